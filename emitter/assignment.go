@@ -8,11 +8,9 @@ import (
 )
 
 func needsCopy(expr parser.Expression) bool {
-	switch expr.Type().(type) {
-	case parser.Nil, parser.Number, parser.Boolean, parser.String, parser.Function:
+	if !isCopiedType(expr.Type()) {
 		return false
 	}
-
 	switch expr := expr.(type) {
 	case *parser.CallExpression,
 		*parser.ComputedAccessExpression,
@@ -22,14 +20,34 @@ func needsCopy(expr parser.Expression) bool {
 		if expr.Operator.Kind() != parser.Mul {
 			return false
 		}
-		_, isSlice := expr.Type().(parser.List)
-		return !isSlice
+		return true
 	}
 	return false
 }
 
+// Check if values of a type are copied when assigned.
+// Options are copied like their payload.
+func isCopiedType(t parser.ExpressionType) bool {
+	switch t := t.(type) {
+	case parser.Nil, parser.Number, parser.Boolean, parser.String, parser.Function, parser.Range:
+		return false
+	case parser.TypeAlias:
+		if t.Name == "?" {
+			return isCopiedType(getOptionPayload(t))
+		}
+		// promises and generators are handles on running computations,
+		// channels and signals are shared between them: they cannot be
+		// copied
+		switch t.Name {
+		case "...", "Generator", "AsyncGenerator", "Chan", "Signal":
+			return false
+		}
+	}
+	return true
+}
+
 func emitAssign(e *Emitter, a *parser.Assignment) {
-	e.emitExpression(a.Pattern)
+	e.emitAssignedPattern(a.Pattern)
 
 	switch a.Operator.Kind() {
 	case parser.Assign, parser.Declare:
@@ -50,28 +68,48 @@ func emitAssign(e *Emitter, a *parser.Assignment) {
 		e.write(" ||= ")
 	}
 
-	if needsCopy(a.Value) {
+	emitAssignedValue(e, a.Value)
+	e.write(";\n")
+}
+
+// Emit an assigned value, copied if it could be shared with another variable
+func emitAssignedValue(e *Emitter, value parser.Expression) {
+	if needsCopy(value) {
 		e.write("structuredClone(")
-		e.emitExpression(a.Value)
+		e.emitExpression(value)
 		e.write(")")
 	} else {
-		e.emitExpression(a.Value)
+		e.emitExpression(value)
+	}
+}
+
+// Emit the variables declared by an assignment, like 'x = value;'.
+// Destructured variables are wrapped in cells after their declaration.
+func emitDeclaration(e *Emitter, a *parser.Assignment) {
+	switch pattern := a.Pattern.(type) {
+	case *parser.Identifier:
+		e.emitBinding(pattern, func() { emitAssignedValue(e, a.Value) })
+		e.write(";\n")
+	case *parser.TupleExpression:
+		e.write("[")
+		for i, element := range pattern.Elements {
+			if i > 0 {
+				e.write(", ")
+			}
+			e.write(e.sanitize(element.(*parser.Identifier).Text()))
+		}
+		e.write("] = ")
+		emitAssignedValue(e, a.Value)
+		e.write(";\n")
+		e.emitCells(pattern.Elements...)
+	default:
+		emitAssign(e, a)
 	}
-	e.write(";\n")
 }
 func (e *Emitter) emitAssignment(a *parser.Assignment) {
 	switch a.Operator.Kind() {
-	case parser.Assign:
-		if u, ok := a.Pattern.(*parser.UnaryExpression); ok {
-			// deref
-			e.emitExpression(u.Operand)
-			e.write("(0, ")
-			e.emitExpression(a.Value)
-			e.write(")")
-		} else {
-			emitAssign(e, a)
-		}
-	case parser.AddAssign,
+	case parser.Assign,
+		parser.AddAssign,
 		parser.ConcatAssign,
 		parser.SubAssign,
 		parser.MulAssign,
@@ -82,7 +120,7 @@ func (e *Emitter) emitAssignment(a *parser.Assignment) {
 		emitAssign(e, a)
 	case parser.Declare:
 		e.write("let ")
-		emitAssign(e, a)
+		emitDeclaration(e, a)
 	case parser.Define:
 		if isTypePattern(a.Pattern) {
 			e.emitTypeDeclaration(a)
@@ -94,9 +132,14 @@ func (e *Emitter) emitAssignment(a *parser.Assignment) {
 		}
 
 		e.write("const ")
-		e.emitExpression(a.Pattern)
-		e.write(" = ")
-		e.emitExpression(a.Value)
+		if identifier, ok := a.Pattern.(*parser.Identifier); ok {
+			e.emitBinding(identifier, func() { e.emitExpression(a.Value) })
+		} else {
+			e.emitExpression(a.Pattern)
+			e.write(" = ")
+			e.emitExpression(a.Value)
+		}
+		e.write("\n")
 	}
 }
 
@@ -115,9 +158,9 @@ func (e *Emitter) emitObjectConstructorStatement(n parser.Node) {
 	var name string
 	switch n := n.(type) {
 	case *parser.Param:
-		name = getSanitizedName(n.Identifier.Text())
+		name = e.sanitize(n.Identifier.Text())
 	case *parser.Entry:
-		name = getSanitizedName(n.Key.(*parser.Identifier).Text())
+		name = e.sanitize(n.Key.(*parser.Identifier).Text())
 	}
 	e.indent()
 	e.write(fmt.Sprintf("this.%v = %v;\n", name, name))
@@ -171,37 +214,137 @@ func (e *Emitter) emitTypeDeclaration(definition *parser.Assignment) {
 		e.addFlag(SumFlag)
 		e.write("class ")
 		e.write(getTypeIdentifier(definition.Pattern))
-		e.write(" extends _Sum {}\n")
+		e.write(" extends ")
+		e.write(e.helper("_Sum"))
+		e.write(" {}\n")
 		return
 	}
 }
 
+// Types implemented by JS classes, like 'Array' for lists.
+// Methods declared on them are emitted as functions taking their receiver as
+// first argument, like '__List_len(l)', so that the JS classes are left
+// untouched.
+var builtinTypes = map[string]bool{
+	"List":   true,
+	"Map":    true,
+	"Set":    true,
+	"String": true,
+}
+
+// Get the name of the function implementing a method declared on a builtin
+// type, like '__List_len' for '(l List).len'
+func (e *Emitter) builtinMethod(typeName string, method string) string {
+	return e.helper(builtinMethodBase(typeName, method))
+}
+
+func builtinMethodBase(typeName string, method string) string {
+	return fmt.Sprintf("__%v_%v", typeName, method)
+}
+
 func (e *Emitter) emitMethodDeclaration(a *parser.Assignment) {
 	pattern := a.Pattern.(*parser.PropertyAccessExpression)
 	receiver := pattern.Expr.(*parser.ParenthesizedExpression).Expr.(*parser.Param)
 
-	e.emitExpression(receiver.Complement)
+	// receivers' mutability is only checked
+	complement, _ := parser.UnwrapMut(receiver.Complement)
+	name := getTypeIdentifier(complement)
+	if builtinTypes[name] {
+		e.emitBuiltinMethodDeclaration(name, a)
+		return
+	}
+	if name == "Range" {
+		e.addFlag(RangeFlag)
+		e.write(e.helper("__Range"))
+	} else if name == "Chan" {
+		e.addFlag(ChanFlag)
+		e.write(e.helper("__Chan"))
+	} else {
+		e.emitExpression(complement)
+	}
 	e.write(".prototype.")
 	e.emitExpression(pattern.Property)
-	e.write(" = function ")
+	init := a.Value.(*parser.FunctionExpression)
+	if init.IsGenerator() {
+		e.write(fmt.Sprintf(" = %v ", getGeneratorKeyword(init)))
+	} else {
+		e.write(" = function ")
+	}
 
 	e.thisName = receiver.Identifier.Text()
 	defer func() { e.thisName = "" }()
 
-	init := a.Value.(*parser.FunctionExpression)
 	e.write("(")
 	params := init.Params.Expr.(*parser.TupleExpression).Elements
-	max := len(params)
-	if max > 0 {
+	max := len(params) - 1
+	if max >= 0 {
 		for i := range params[:max] {
-			param := params[i].(*parser.Param)
-			e.emitIdentifier(param.Identifier)
+			e.emitFunctionParam(params[i])
 			e.write(", ")
 		}
-		e.emitIdentifier(params[max].(*parser.Param).Identifier)
+		e.emitFunctionParam(params[max])
+	}
+	e.write(") ")
+	e.emitFunctionBody(init)
+	e.write("\n")
+}
+
+// Emit a method declared on a builtin type as a function taking its receiver
+// as first param, like 'function __List_len(l) {...}'
+func (e *Emitter) emitBuiltinMethodDeclaration(typeName string, a *parser.Assignment) {
+	pattern := a.Pattern.(*parser.PropertyAccessExpression)
+	receiver := pattern.Expr.(*parser.ParenthesizedExpression).Expr.(*parser.Param)
+	init := a.Value.(*parser.FunctionExpression)
+	switch {
+	case init.IsGenerator():
+		e.write(getGeneratorKeyword(init))
+		e.write(" ")
+	case init.Type().(parser.Function).Async:
+		e.write("async function ")
+	default:
+		e.write("function ")
+	}
+	e.write(e.builtinMethod(typeName, pattern.Property.(*parser.Identifier).Text()))
+	e.write("(")
+	e.emitFunctionParam(receiver)
+	for _, param := range init.Params.Expr.(*parser.TupleExpression).Elements {
+		e.write(", ")
+		e.emitFunctionParam(param)
 	}
 	e.write(") ")
-	e.emitFunctionBody(init.Body, init.Params.Expr.(*parser.TupleExpression))
+	e.emitFunctionBody(init)
+	e.write("\n")
+}
+
+// Get the name of the function implementing the accessed method, if it is
+// declared on a builtin type
+func (e *Emitter) getBuiltinMethod(access *parser.PropertyAccessExpression) (string, bool) {
+	property, ok := access.Property.(*parser.Identifier)
+	if !ok || access.Extern() != nil {
+		return "", false
+	}
+	object := access.Expr.Type()
+	if ref, ok := object.(parser.Ref); ok {
+		object = ref.To
+	}
+	if access.Optional {
+		object = getOptionPayload(object)
+	}
+	var name string
+	switch t := object.(type) {
+	case parser.List:
+		name = "List"
+	case parser.String:
+		name = "String"
+	case parser.TypeAlias:
+		if builtinTypes[t.Name] {
+			name = t.Name
+		}
+	}
+	if name == "" {
+		return "", false
+	}
+	return e.builtinMethod(name, property.Text()), true
 }
 
 func isTypePattern(expr parser.Expression) bool {
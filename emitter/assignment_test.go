@@ -15,10 +15,10 @@ func TestAssignmentShorthand(t *testing.T) {
 
 func TestInderectAssignment(t *testing.T) {
 	source := "i := 0\n"
-	source += "ref := &i\n"
+	source += "ref := &mut i\n"
 	source += "*ref = 42"
 
-	expected := "ref(0, 42)"
+	expected := "ref.v = 42;\n"
 
 	testEmitter(t, source, expected, 2)
 }
@@ -61,3 +61,23 @@ func TestMethodDefinition(t *testing.T) {
 	expected += "}\n"
 	testEmitter(t, source, expected, 1)
 }
+
+func TestUninitializedDeclaration(t *testing.T) {
+	source := "n number\n"
+	source += "n = 42\n"
+	source += "io.log(n)"
+
+	expected := "let n;\n"
+
+	testEmitter(t, source, expected, 0)
+}
+
+func TestReferencedTupleDeclaration(t *testing.T) {
+	source := "a, b := (1, 2)\n"
+	source += "r := &b"
+
+	expected := "let [a, b] = ([1, 2]);\n"
+	expected += "b = { v: b };\n"
+
+	testEmitter(t, source, expected, 0)
+}
@@ -11,14 +11,7 @@ func (e *Emitter) emitBinaryExpression(expr *parser.BinaryExpression) {
 
 	precedence := Precedence(expr)
 	if expr.Left != nil {
-		left := Precedence(expr.Left)
-		if left < precedence {
-			e.write("(")
-		}
-		e.emitExpression(expr.Left)
-		if left < precedence {
-			e.write(")")
-		}
+		e.emitOperand(expr, expr.Left, precedence)
 	}
 
 	e.write(" ")
@@ -26,21 +19,43 @@ func (e *Emitter) emitBinaryExpression(expr *parser.BinaryExpression) {
 	e.write(" ")
 
 	if expr.Right != nil {
-		right := Precedence(expr.Right)
-		if right < precedence {
-			e.write("(")
-		}
-		e.emitExpression(expr.Right)
-		if right < precedence {
-			e.write(")")
-		}
+		e.emitOperand(expr, expr.Right, precedence)
+	}
+}
+
+func (e *Emitter) emitOperand(expr *parser.BinaryExpression, operand parser.Expression, precedence uint8) {
+	parenthesized := Precedence(operand) < precedence
+	// JS forbids mixing '??' with '&&' and '||' without parentheses
+	if expr.Operator.Kind() == parser.Coalesce && isLogical(operand) {
+		parenthesized = true
+	}
+	// JS forbids unary operators on the left of '**', like in '-x ** 2'
+	if _, ok := operand.(*parser.UnaryExpression); ok && expr.Operator.Kind() == parser.Pow && operand == expr.Left {
+		parenthesized = true
+	}
+	if parenthesized {
+		e.write("(")
+	}
+	e.emitExpression(operand)
+	if parenthesized {
+		e.write(")")
+	}
+}
+
+func isLogical(expr parser.Expression) bool {
+	binary, ok := expr.(*parser.BinaryExpression)
+	if !ok {
+		return false
 	}
+	kind := binary.Operator.Kind()
+	return kind == parser.LogicalAnd || kind == parser.LogicalOr
 }
 
 func (e *Emitter) emitComparison(expr *parser.BinaryExpression) bool {
 	if _, ok := expr.Left.Type().(parser.Ref); ok {
 		e.addFlag(RefComparisonFlag)
-		e.write("__refEquals(")
+		e.write(e.helper("__refEquals"))
+		e.write("(")
 		e.emitExpression(expr.Left)
 		e.write(", ")
 		e.emitExpression(expr.Right)
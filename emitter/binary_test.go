@@ -17,3 +17,20 @@ func TestEmitRefComparison(t *testing.T) {
 	expected := "__refEquals(a, b);\n"
 	testEmitter(t, source, expected, 3)
 }
+
+func TestEmitCoalescing(t *testing.T) {
+	source := "f :: (x ?boolean, a boolean, b boolean) => boolean { x ?? a || b }"
+
+	expected := "const f = (x, a, b) => {\n"
+	expected += "    return x ?? (a || b);\n"
+	expected += "}\n"
+
+	testEmitter(t, source, expected, 0)
+}
+
+func TestEmitParenthesizedOperand(t *testing.T) {
+	source := "x := 1\n"
+	source += "(x + 1) * 2"
+	expected := "(x + 1) * 2;\n"
+	testEmitter(t, source, expected, 1)
+}
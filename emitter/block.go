@@ -1,22 +1,25 @@
 package emitter
 
-import (
-	"fmt"
-
-	"github.com/bmelicque/test-parser/parser"
-)
+import "github.com/bmelicque/test-parser/parser"
 
 func (e *Emitter) emitBlockStatement(b *parser.Block) {
+	e.emitBlockStatementWith(b, nil)
+}
+
+// Emit a block statement.
+// The prelude, if any, is emitted at the start of the block.
+func (e *Emitter) emitBlockStatementWith(b *parser.Block, prelude func()) {
 	e.write("{")
-	if len(b.Statements) == 0 {
+	if len(b.Statements) == 0 && prelude == nil {
 		e.write("}")
 		return
 	}
 	e.write("\n")
 	e.depth++
-	if needsSymbol(b) {
-		e.indent()
-		e.write("const __s = Symbol();\n")
+	e.pushNames(b.Scope())
+	defer e.dropNames()
+	if prelude != nil {
+		prelude()
 	}
 	for _, statement := range b.Statements {
 		e.indent()
@@ -27,28 +30,9 @@ func (e *Emitter) emitBlockStatement(b *parser.Block) {
 	e.write("}\n")
 }
 
-func needsSymbol(b *parser.Block) bool {
-	var found bool
-	parser.Walk(b, func(n parser.Node, skip func()) {
-		if found {
-			skip()
-			return
-		}
-		unary, ok := n.(*parser.UnaryExpression)
-		if !ok || unary.Operator.Kind() != parser.BinaryAnd {
-			return
-		}
-		if _, ok = unary.Operand.(*parser.Identifier); ok {
-			found = true
-			skip()
-		}
-	})
-	return found
-}
-
 func (e *Emitter) emitBlockExpression(b *parser.Block) {
-	if id, ok := e.uninlinables[b]; ok {
-		e.write(fmt.Sprintf("_tmp%v", id))
+	if name, ok := e.uninlinables[b]; ok {
+		e.write(name)
 		delete(e.uninlinables, b)
 		return
 	}
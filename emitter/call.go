@@ -1,23 +1,117 @@
 package emitter
 
-import "github.com/bmelicque/test-parser/parser"
+import (
+	"fmt"
+
+	"github.com/bmelicque/test-parser/parser"
+)
 
 func (e *Emitter) emitCallExpression(expr *parser.CallExpression, await bool) {
-	if expr.Callee.Type().(parser.Function).Async && await {
+	// conversions like 'string(value)'
+	if _, ok := expr.CalledType().(parser.Type); ok {
+		e.emitDisplayed(expr.Args.Expr.(*parser.TupleExpression).Elements[0])
+		return
+	}
+	function := expr.CalledType().(parser.Function)
+	switch function.Intrinsic {
+	case parser.FormatIntrinsic:
+		e.emitFormatCall(expr)
+		return
+	case parser.EncodeIntrinsic:
+		e.emitJsonEncoding(expr)
+		return
+	case parser.DecodeIntrinsic:
+		e.emitJsonDecoding(expr)
+		return
+	case parser.HelperIntrinsic:
+		e.emitHelperCall(expr)
+		return
+	}
+	if function.Host {
+		e.emitHostCall(expr, function, await)
+		return
+	}
+	e.emitCall(expr, function.Async && await)
+}
+
+func (e *Emitter) emitCall(expr *parser.CallExpression, await bool) {
+	if await {
 		e.write("await ")
 	}
+	if access, ok := expr.Callee.(*parser.PropertyAccessExpression); ok {
+		if method, ok := e.getBuiltinMethod(access); ok {
+			e.emitBuiltinMethodCall(expr, access, method)
+			return
+		}
+	}
 	e.emitExpression(expr.Callee)
 	e.write("(")
-	defer e.write(")")
+	e.emitArgs(expr, false)
+	e.write(")")
+}
 
+// Emit the arguments of a call, after the given ones if any
+func (e *Emitter) emitArgs(expr *parser.CallExpression, preceded bool) {
 	args := expr.Args.Expr.(*parser.TupleExpression).Elements
-	max := len(args) - 1
-	if max == -1 {
+	for i, arg := range args {
+		if i > 0 || preceded {
+			e.write(", ")
+		}
+		e.emitExpression(arg)
+	}
+	if expr.PassesSignal() {
+		if len(args) > 0 || preceded {
+			e.write(", ")
+		}
+		e.write(e.group + ".signal")
+	}
+}
+
+// Emit a call to a method declared on a builtin type, like '__List_len(l)'.
+// Like JS's '?.', optional chains skip the call and its arguments for None.
+func (e *Emitter) emitBuiltinMethodCall(expr *parser.CallExpression, access *parser.PropertyAccessExpression, method string) {
+	if !access.Optional {
+		e.write(method + "(")
+		e.emitDereferenced(access.Expr)
+		e.emitArgs(expr, true)
+		e.write(")")
 		return
 	}
-	for i := range args[:max] {
-		e.emitExpression(args[i])
-		e.write(", ")
+	o := e.fresh("o")
+	e.write(fmt.Sprintf("((%[1]v) => %[1]v === undefined ? undefined : %[2]v(%[1]v", o, method))
+	e.emitArgs(expr, true)
+	e.write("))(")
+	e.emitDereferenced(access.Expr)
+	e.write(")")
+}
+
+// Host functions fail by throwing exceptions, which are turned into errors
+// holding their message.
+// Their absent values may be null, which is turned into undefined.
+func (e *Emitter) emitHostCall(expr *parser.CallExpression, function parser.Function, await bool) {
+	returned := function.Returned
+	switch {
+	case isResultType(returned) && function.Async:
+		e.addFlag(SumFlag | AsyncAttemptFlag)
+		if await {
+			e.write("await ")
+		}
+		e.write(fmt.Sprintf("%v(() => ", e.helper("__attemptAsync")))
+		e.emitCall(expr, false)
+		e.write(")")
+	case isResultType(returned):
+		e.addFlag(SumFlag | AttemptFlag)
+		e.write(fmt.Sprintf("%v(() => ", e.helper("__attempt")))
+		e.emitCall(expr, false)
+		e.write(")")
+	case isOptionType(returned) && function.Async && !await:
+		e.emitCall(expr, false)
+		e.write(".then((v) => v ?? undefined)")
+	case isOptionType(returned):
+		e.write("(")
+		e.emitCall(expr, function.Async)
+		e.write(" ?? undefined)")
+	default:
+		e.emitCall(expr, function.Async && await)
 	}
-	e.emitExpression(args[max])
 }
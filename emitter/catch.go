@@ -1,15 +1,27 @@
 package emitter
 
-import "github.com/bmelicque/test-parser/parser"
+import (
+	"fmt"
 
+	"github.com/bmelicque/test-parser/parser"
+)
+
+// Results are tested on their tag, the catch block being run on 'Err'
 func (e *Emitter) emitCatchStatement(c *parser.CatchExpression) {
-	e.write("try {\n")
-	e.depth++
+	value := e.emitTestedValue(c.Left)
+	e.write(fmt.Sprintf("if (%v._tag === \"Err\") ", value))
+	e.emitBlockStatementWith(c.Body, func() {
+		e.emitCatchBinding(c, value)
+	})
+}
+
+// Bind the error held by a result to the catch identifier, if any
+func (e *Emitter) emitCatchBinding(c *parser.CatchExpression, value string) {
+	if c.Identifier == nil || c.Identifier.Text() == "_" {
+		return
+	}
 	e.indent()
-	e.emit(c.Left)
-	e.depth--
-	e.write("} catch (")
-	e.emitExpression(c.Identifier)
-	e.write(") ")
-	e.emitBlockStatement(c.Body)
+	e.write("let ")
+	e.emitBinding(c.Identifier, func() { e.write(value + "._value") })
+	e.write(";\n")
 }
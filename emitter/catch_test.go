@@ -18,12 +18,34 @@ func TestCatchStatement(t *testing.T) {
 	})
 
 	text := emitter.string()
-	expected := "try {\n"
-	expected += "    result;\n"
-	expected += "} catch (_) {\n"
+	expected := "if (result._tag === \"Err\") {\n"
 	expected += "    0;\n"
 	expected += "}\n"
 	if text != expected {
 		t.Fatalf("Expected string:\n%v\ngot:\n%v", expected, text)
 	}
 }
+
+func TestEmitCatchBinding(t *testing.T) {
+	source := "f :: (x string!number) => number {\n"
+	source += "    y := x catch err {\n"
+	source += "        io.log(err)\n"
+	source += "        0\n"
+	source += "    }\n"
+	source += "    y + 1\n"
+	source += "}"
+
+	expected := "const f = (x) => {\n"
+	expected += "    let _tmp0;\n"
+	expected += "    if ((_tmp0 = x)._tag === \"Ok\") _tmp0 = _tmp0._value;\n"
+	expected += "    else {\n"
+	expected += "        let err = _tmp0._value;\n"
+	expected += "        console.log(err);\n"
+	expected += "        _tmp0 = 0;\n"
+	expected += "    }\n"
+	expected += "    let y = _tmp0;\n"
+	expected += "    return y + 1;\n"
+	expected += "}\n"
+
+	testEmitter(t, source, expected, 0)
+}
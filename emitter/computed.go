@@ -1,6 +1,33 @@
 package emitter
 
-import "github.com/bmelicque/test-parser/parser"
+import (
+	"fmt"
+
+	"github.com/bmelicque/test-parser/parser"
+)
+
+// Lists and strings are indexed at checked indexes: out-of-range indexes
+// throw instead of reading undefined. Unlike 'get', indexing does not count
+// negative indexes back from the end.
+const indexHelper = `function %v(list, i) {
+    if (Number.isInteger(i) && i >= 0 && i < list.length) return i;
+    throw new RangeError("Index " + i + " out of range for length " + list.length);
+}
+`
+
+// Read an element at a checked index
+const elementHelper = `function %v(list, i) { return list[%v(list, i)] }
+`
+
+// Slices are checked like indexes: out-of-range bounds throw instead of being
+// clamped, and negative bounds do not count back from the end.
+const sliceHelper = `function %v(list, start, end = list.length) {
+    if (Number.isInteger(start) && Number.isInteger(end) && 0 <= start && start <= end && end <= list.length) {
+        return list.slice(start, end);
+    }
+    throw new RangeError("Slice " + start + ".." + end + " out of range for length " + list.length);
+}
+`
 
 func (e *Emitter) emitComputedAccessExpression(expr *parser.ComputedAccessExpression) {
 	switch left := expr.Expr.Type().(type) {
@@ -10,6 +37,15 @@ func (e *Emitter) emitComputedAccessExpression(expr *parser.ComputedAccessExpres
 		} else {
 			e.emitExpression(expr.Expr)
 		}
+	case parser.List, parser.String:
+		emitListAccess(e, expr)
+	case parser.Ref:
+		switch left.To.(type) {
+		case parser.List, parser.String:
+			emitListAccess(e, expr)
+		default:
+			e.emitExpression(expr.Expr)
+		}
 	default:
 		e.emitExpression(expr.Expr)
 	}
@@ -20,3 +56,67 @@ func emitGetElement(e *Emitter, c *parser.ComputedAccessExpression) {
 	e.emitExpression(c.Property.Expr)
 	e.write(")")
 }
+
+// Emit 'list[i]' as '__at(list, i)', and slices like 'list[1..3]' as
+// '__slice(list, 1, 3)'.
+// Strings are indexed and sliced the same way.
+func emitListAccess(e *Emitter, c *parser.ComputedAccessExpression) {
+	r, ok := c.Property.Expr.(*parser.RangeExpression)
+	if !ok {
+		e.addFlag(ElementFlag)
+		e.write(fmt.Sprintf("%v(", e.helper("__at")))
+		e.emitDereferenced(c.Expr)
+		e.write(", ")
+		e.emitExpression(c.Property.Expr)
+		e.write(")")
+		return
+	}
+	e.addFlag(SliceFlag)
+	e.write(fmt.Sprintf("%v(", e.helper("__slice")))
+	e.emitDereferenced(c.Expr)
+	e.write(", ")
+	if r.Left != nil {
+		e.emitExpression(r.Left)
+	} else {
+		e.write("0")
+	}
+	if r.Right != nil {
+		e.write(", ")
+		e.emitExpression(r.Right)
+		if r.Operator.Kind() == parser.InclusiveRange {
+			e.write(" + 1")
+		}
+	}
+	e.write(")")
+}
+
+// Emit the target of an assignment.
+// Elements are written at a checked index, like 'list[__index(list, i)]':
+// the list is emitted twice, which is fine since assigned lists are places.
+func (e *Emitter) emitAssignedPattern(pattern parser.Expression) {
+	c, ok := pattern.(*parser.ComputedAccessExpression)
+	if !ok || !isIndexed(c) {
+		e.emitExpression(pattern)
+		return
+	}
+	e.addFlag(IndexFlag)
+	e.emitDereferenced(c.Expr)
+	e.write(fmt.Sprintf("[%v(", e.helper("__index")))
+	e.emitDereferenced(c.Expr)
+	e.write(", ")
+	e.emitExpression(c.Property.Expr)
+	e.write(")]")
+}
+
+// Check if an expression indexes a list, like 'list[i]'
+func isIndexed(c *parser.ComputedAccessExpression) bool {
+	if _, ok := c.Property.Expr.(*parser.RangeExpression); ok {
+		return false
+	}
+	t := c.Expr.Type()
+	if ref, ok := t.(parser.Ref); ok {
+		t = ref.To
+	}
+	_, ok := t.(parser.List)
+	return ok
+}
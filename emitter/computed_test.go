@@ -1,6 +1,7 @@
 package emitter
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/bmelicque/test-parser/parser"
@@ -21,3 +22,63 @@ func TestMapElementAccess(t *testing.T) {
 		t.Fatalf("Expected string:\n%v\ngot:\n%v", expected, text)
 	}
 }
+
+func TestEmitListIndex(t *testing.T) {
+	source := "list := []number{1, 2}\n"
+	source += "list[0] = list[1]"
+	testEmitter(t, source, "list[__index(list, 0)] = __at(list, 1);\n", 1)
+}
+
+func TestEmitIndexHelpers(t *testing.T) {
+	ast, errors := parser.Parse(strings.NewReader("list := []number{1}\nio.log(list[0])"))
+	if len(errors) > 0 {
+		t.Fatalf("Expected no errors, got %#v", errors)
+	}
+	text := EmitProgram(ast)
+	if !strings.Contains(text, "function __index(list, i) {") {
+		t.Fatalf("Expected index helper, got:\n%v", text)
+	}
+	if !strings.Contains(text, "function __at(list, i) { return list[__index(list, i)] }") {
+		t.Fatalf("Expected element helper, got:\n%v", text)
+	}
+
+	ast, _ = parser.Parse(strings.NewReader("list := []number{1}\nio.log(list[0..1])"))
+	text = EmitProgram(ast)
+	if strings.Contains(text, "__index") {
+		t.Fatalf("Expected no index helper for slices, got:\n%v", text)
+	}
+	// slices throw on out-of-range bounds, like indexes
+	if !strings.Contains(text, "function __slice(list, start, end = list.length) {") {
+		t.Fatalf("Expected slice helper, got:\n%v", text)
+	}
+	if !strings.Contains(text, "throw new RangeError(\"Slice \"") {
+		t.Fatalf("Expected checked slices, got:\n%v", text)
+	}
+}
+
+func TestEmitListSlice(t *testing.T) {
+	source := "list := []number{1, 2, 3}\n"
+	source += "list[1..=2]"
+	testEmitter(t, source, "__slice(list, 1, 2 + 1);\n", 1)
+}
+
+func TestEmitListHigherOrderMethod(t *testing.T) {
+	source := "list := []number{1, 2, 3}\n"
+	source += "list.map((x) => { x * 2 })"
+	expected := "list.map((x) => {\n"
+	expected += "    return x * 2;\n"
+	expected += "});\n"
+	testEmitter(t, source, expected, 1)
+}
+
+func TestEmitStringSlice(t *testing.T) {
+	source := "s := \"abc\"\n"
+	source += "s[..2]"
+	testEmitter(t, source, "__slice(s, 0, 2);\n", 1)
+}
+
+func TestEmitStringMethod(t *testing.T) {
+	source := "s := \"abc\"\n"
+	source += "s.contains(\"b\")"
+	testEmitter(t, source, "s.includes(\"b\");\n", 1)
+}
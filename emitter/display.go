@@ -0,0 +1,239 @@
+package emitter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bmelicque/test-parser/parser"
+)
+
+// Values are rendered at runtime by the __show helper.
+// Options and tuples cannot be told apart from other values once emitted,
+// so values are passed along with a description of their static type,
+// whenever the dynamic rendering is not enough:
+//
+//	["show", Point.prototype.show] // type implementing Display
+//	["option", payload]
+//	["tuple", [elements...]]
+//	["list", element], ["set", element], ["map", key, value]
+//	["sum", {Constructor: payload}] // tuple payload for several params
+//	["object", "Name", {field: type}]
+const showHelper = `function %[1]v(v, t, nested) {
+    const show = (x, i) => %[1]v(x, t[1]?.[i], true);
+    switch (t?.[0]) {
+    case "show":
+        return t[1].call(v);
+    case "option":
+        return v === undefined ? "None" : "Some(" + %[1]v(v, t[1], true) + ")";
+    case "tuple":
+        return "(" + v.map(show).join(", ") + ")";
+    case "list":
+        return "[" + v.map((x) => %[1]v(x, t[1], true)).join(", ") + "]";
+    case "set":
+        return "Set{" + [...v].map((x) => %[1]v(x, t[1], true)).join(", ") + "}";
+    case "map":
+        return "Map{" + [...v].map(([k, x]) => %[1]v(k, t[1], true) + ": " + %[1]v(x, t[2], true)).join(", ") + "}";
+    case "sum": {
+        if (!("_value" in v)) return v._tag;
+        const payload = t[1][v._tag];
+        const shown = %[1]v(v._value, payload, true);
+        return payload?.[0] === "tuple" ? v._tag + shown : v._tag + "(" + shown + ")";
+    }
+    case "object":
+        return t[1] + "{" + Object.entries(v).map(([k, x]) => k + ": " + %[1]v(x, t[2]?.[k], true)).join(", ") + "}";
+    }
+    if (v === undefined) return "None";
+    if (typeof v === "string") return nested ? JSON.stringify(v) : v;
+    if (typeof v === "function") return "<function>";
+    if (typeof v !== "object" || v === null) return String(v);
+    if (typeof v.show === "function") return v.show();
+    if (Array.isArray(v)) return %[1]v(v, ["list"]);
+    if (v instanceof Set) return %[1]v(v, ["set"]);
+    if (v instanceof Map) return %[1]v(v, ["map"]);
+    if ("_tag" in v) return %[1]v(v, ["sum", {}]);
+    const name = v.constructor === Object ? "" : v.constructor.name;
+    return %[1]v(v, ["object", name]);
+}
+`
+
+// Emit a value rendered as a string, like 'string(value)' does
+func (e *Emitter) emitDisplayed(value parser.Expression) {
+	e.addFlag(DisplayFlag)
+	e.write(e.helper("__show"))
+	e.write("(")
+	e.emitExpression(value)
+	if description := e.describe(value.Type(), map[string]bool{}); description != "" {
+		e.write(", ")
+		e.write(description)
+	}
+	e.write(")")
+}
+
+// Get the description of a type needed to render its values, or "" if the
+// dynamic rendering is enough.
+// Aliases already being described are rendered dynamically, so that
+// recursive types have finite descriptions.
+func (e *Emitter) describe(t parser.ExpressionType, seen map[string]bool) string {
+	switch t := t.(type) {
+	case parser.TypeAlias:
+		return e.describeAlias(t, seen)
+	case parser.Generic:
+		if t.Value == nil {
+			return ""
+		}
+		return e.describe(t.Value, seen)
+	case parser.Tuple:
+		elements := make([]string, len(t.Elements))
+		for i, element := range t.Elements {
+			elements[i] = orNull(e.describe(element, seen))
+		}
+		return fmt.Sprintf("[\"tuple\", [%v]]", strings.Join(elements, ", "))
+	case parser.List:
+		return describeWith("list", e.describe(t.Element, seen))
+	case parser.Set:
+		return describeWith("set", e.describe(t.Element, seen))
+	case parser.Map:
+		key := e.describe(t.Key, seen)
+		value := e.describe(t.Value, seen)
+		if key == "" && value == "" {
+			return ""
+		}
+		return fmt.Sprintf("[\"map\", %v, %v]", orNull(key), orNull(value))
+	default:
+		return ""
+	}
+}
+
+func (e *Emitter) describeAlias(t parser.TypeAlias, seen map[string]bool) string {
+	if seen[t.Name] {
+		return ""
+	}
+	if isOptionType(t) {
+		return fmt.Sprintf("[\"option\", %v]", orNull(e.describe(getOptionPayload(t), seen)))
+	}
+	if implementsDisplay(t) && builtinTypes[t.Name] {
+		method := e.builtinMethod(t.Name, "show")
+		return fmt.Sprintf("[\"show\", function () { return %v(this) }]", method)
+	}
+	if implementsDisplay(t) {
+		return fmt.Sprintf("[\"show\", %v.prototype.show]", e.sanitize(t.Name))
+	}
+	seen[t.Name] = true
+	defer delete(seen, t.Name)
+	switch ref := t.Ref.(type) {
+	case parser.Sum:
+		return e.describeSum(ref, seen)
+	case parser.Object:
+		fields := []string{}
+		for _, member := range ref.Members {
+			if d := e.describe(member.Type, seen); d != "" {
+				fields = append(fields, fmt.Sprintf("%v: %v", member.Name, d))
+			}
+		}
+		if len(fields) == 0 {
+			return fmt.Sprintf("[\"object\", \"%v\"]", t.Name)
+		}
+		return fmt.Sprintf("[\"object\", \"%v\", {%v}]", t.Name, strings.Join(fields, ", "))
+	default:
+		return e.describe(t.Ref, seen)
+	}
+}
+
+// Constructors with several params hold a tuple, which always needs a
+// description
+func (e *Emitter) describeSum(sum parser.Sum, seen map[string]bool) string {
+	names := make([]string, 0, len(sum.Members))
+	for name := range sum.Members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	payloads := []string{}
+	for _, name := range names {
+		params := sum.Members[name].Params
+		var d string
+		switch {
+		case params == nil || len(params.Elements) == 0:
+		case len(params.Elements) == 1:
+			d = e.describe(params.Elements[0], seen)
+		default:
+			d = e.describe(*params, seen)
+		}
+		if d != "" {
+			payloads = append(payloads, fmt.Sprintf("%v: %v", name, d))
+		}
+	}
+	if len(payloads) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("[\"sum\", {%v}]", strings.Join(payloads, ", "))
+}
+
+// Check if a type has a 'show' method, as declared by the Display trait
+func implementsDisplay(t parser.TypeAlias) bool {
+	method, ok := t.Methods["show"].(parser.Function)
+	if !ok || method.Params != nil && len(method.Params.Elements) > 0 {
+		return false
+	}
+	_, ok = method.Returned.(parser.String)
+	return ok
+}
+
+func describeWith(kind string, element string) string {
+	if element == "" {
+		return ""
+	}
+	return fmt.Sprintf("[\"%v\", %v]", kind, element)
+}
+
+func orNull(description string) string {
+	if description == "" {
+		return "null"
+	}
+	return description
+}
+
+// Printing without a trailing newline writes to the standard output in
+// runtimes providing one, like Node. Consoles cannot write partial lines:
+// elsewhere, the text is logged as a line.
+const printHelper = `function %v(text) {
+    if (typeof process !== "undefined" && process.stdout) process.stdout.write(text);
+    else console.log(text);
+}
+`
+
+// Emit a formatted printing call, like 'io.println("x = {}", x)', as a
+// concatenation of the format string's pieces and the rendered values
+func (e *Emitter) emitFormatCall(expr *parser.CallExpression) {
+	name := expr.Callee.(*parser.PropertyAccessExpression).Property.(*parser.Identifier).Text()
+	if name == "print" {
+		e.addFlag(PrintFlag)
+		e.write(e.helper("__print"))
+	} else {
+		e.write("console.log")
+	}
+	e.write("(")
+	args := expr.Args.Expr.(*parser.TupleExpression).Elements
+	pieces := parser.SplitFormat(args[0].(*parser.Literal).Token.Text())
+	parts := []func(){}
+	for i, piece := range pieces {
+		if piece != "" {
+			quoted := fmt.Sprintf("\"%v\"", piece)
+			parts = append(parts, func() { e.write(quoted) })
+		}
+		if i+1 < len(args) {
+			value := args[i+1]
+			parts = append(parts, func() { e.emitDisplayed(value) })
+		}
+	}
+	if len(parts) == 0 {
+		e.write("\"\"")
+	}
+	for i, part := range parts {
+		if i > 0 {
+			e.write(" + ")
+		}
+		part()
+	}
+	e.write(")")
+}
@@ -0,0 +1,90 @@
+package emitter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bmelicque/test-parser/parser"
+)
+
+func TestEmitStringConversion(t *testing.T) {
+	testEmitter(t, "string(42)", "__show(42);\n", 0)
+}
+
+func TestEmitOptionDescription(t *testing.T) {
+	source := "l := []string{\"a\"}\n"
+	source += "string(l.get(0))"
+	expected := "__show((l.at(0) ?? undefined), [\"option\", null]);\n"
+	testEmitter(t, source, expected, 1)
+}
+
+func TestEmitTupleDescription(t *testing.T) {
+	source := "l := [](number, ?string){}\n"
+	source += "string(l)"
+	expected := "__show(l, [\"list\", [\"tuple\", [null, [\"option\", null]]]]);\n"
+	testEmitter(t, source, expected, 1)
+}
+
+func TestEmitDisplayImplementation(t *testing.T) {
+	source := "Point :: { x number }\n"
+	source += "(p Point).show :: () => string { string(p.x) }\n"
+	source += "string(Point{x: 1})"
+	expected := "__show(new Point(1), [\"show\", Point.prototype.show]);\n"
+	testEmitter(t, source, expected, 2)
+}
+
+func TestEmitSumDescription(t *testing.T) {
+	source := "Shape :: | Circle{number} | Rect{number, number} | Empty\n"
+	source += "f :: (s Shape) => string { string(s) }"
+	expected := "const f = (s) => {\n"
+	expected += "    return __show(s, [\"sum\", {Rect: [\"tuple\", [null, null]]}]);\n"
+	expected += "}\n"
+	testEmitter(t, source, expected, 1)
+}
+
+func TestEmitFormat(t *testing.T) {
+	source := "x := 1\n"
+	source += "io.println(\"x = {}, {{}}\", x)"
+	expected := "console.log(\"x = \" + __show(x) + \", {}\");\n"
+	testEmitter(t, source, expected, 1)
+}
+
+func TestEmitPrint(t *testing.T) {
+	source := "io.print(\"{}{}\", 1, \"a\")"
+	expected := "__print(__show(1) + __show(\"a\"));\n"
+	testEmitter(t, source, expected, 0)
+}
+
+func TestEmitPrintHelper(t *testing.T) {
+	ast, errors := parser.Parse(strings.NewReader("io.println(\"done\")"))
+	if len(errors) > 0 {
+		t.Fatalf("Expected no errors, got %#v", errors)
+	}
+	if text := EmitProgram(ast); strings.Contains(text, "__print") {
+		t.Fatalf("Expected no helper, got:\n%v", text)
+	}
+
+	ast, _ = parser.Parse(strings.NewReader("io.print(\"done\")"))
+	text := EmitProgram(ast)
+	if !strings.Contains(text, "function __print(text) {") {
+		t.Fatalf("Expected helper, got:\n%v", text)
+	}
+	if strings.Contains(text, "process.stdout.write(\"done\")") {
+		t.Fatalf("Expected printing through the helper, got:\n%v", text)
+	}
+}
+
+func TestEmitShowHelper(t *testing.T) {
+	ast, errors := parser.Parse(strings.NewReader("io.println(\"done\")"))
+	if len(errors) > 0 {
+		t.Fatalf("Expected no errors, got %#v", errors)
+	}
+	if text := EmitProgram(ast); strings.Contains(text, "function __show(") {
+		t.Fatalf("Expected no helper, got:\n%v", text)
+	}
+
+	ast, _ = parser.Parse(strings.NewReader("io.println(\"{}\", 1)"))
+	if text := EmitProgram(ast); !strings.Contains(text, "function __show(v, t, nested) {") {
+		t.Fatalf("Expected helper, got:\n%v", text)
+	}
+}
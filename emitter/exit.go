@@ -1,16 +1,41 @@
 package emitter
 
-import "github.com/bmelicque/test-parser/parser"
+import (
+	"fmt"
 
+	"github.com/bmelicque/test-parser/parser"
+)
+
+// In functions returning results, 'return' wraps happy values as 'Ok'
+// and 'throw' returns an 'Err'.
 func (e *Emitter) emitExit(r *parser.Exit) {
 	switch r.Operator.Kind() {
 	case parser.BreakKeyword:
-		e.write("break")
+		e.emitBreak(r)
+		return
 	case parser.ContinueKeyword:
 		e.write("continue")
+		e.emitLabel(r.Label)
+		e.write(";\n")
+		return
 	case parser.ReturnKeyword:
 		e.write("return")
+		if r.Value != nil {
+			e.write(" ")
+			e.emitReturnedValue(r.Value)
+		} else if isResultType(e.returned) {
+			e.write(" ")
+			e.emitResult("Ok", nil)
+		}
+		e.write(";\n")
+		return
 	case parser.ThrowKeyword:
+		if isResultType(e.returned) {
+			e.write("return ")
+			e.emitResult("Err", r.Value)
+			e.write(";\n")
+			return
+		}
 		e.write("throw")
 	}
 	if r.Value != nil {
@@ -19,3 +44,24 @@ func (e *Emitter) emitExit(r *parser.Exit) {
 	}
 	e.write(";\n")
 }
+
+// Values of loops used as expressions are assigned to the variable holding
+// the loop's result before exiting it
+func (e *Emitter) emitBreak(r *parser.Exit) {
+	if name, ok := e.loopResults[r.Loop()]; ok && r.Value != nil {
+		e.write(fmt.Sprintf("%v = ", name))
+		e.emitExpression(r.Value)
+		e.write(";\n")
+		e.indent()
+	}
+	e.write("break")
+	e.emitLabel(r.Label)
+	e.write(";\n")
+}
+
+func (e *Emitter) emitLabel(label *parser.Identifier) {
+	if label != nil {
+		e.write(" ")
+		e.write(e.sanitize(label.Text()))
+	}
+}
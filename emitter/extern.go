@@ -0,0 +1,22 @@
+package emitter
+
+import (
+	"fmt"
+
+	"github.com/bmelicque/test-parser/parser"
+)
+
+// Globals are used directly, module exports are imported at the top of the
+// program.
+func (e *Emitter) emitExternDeclaration(d *parser.ExternDeclaration) {
+	module := d.ModuleName()
+	if module == "" {
+		return
+	}
+	name := e.sanitize(d.Identifier.Text())
+	specifier := name
+	if exported := d.Path(); exported != name {
+		specifier = fmt.Sprintf("%v as %v", exported, name)
+	}
+	e.imports = append(e.imports, fmt.Sprintf("import { %v } from \"%v\";\n", specifier, module))
+}
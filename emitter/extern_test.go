@@ -0,0 +1,65 @@
+package emitter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bmelicque/test-parser/parser"
+)
+
+func TestEmitExternGlobal(t *testing.T) {
+	source := "extern sqrt :: (number) -> number = \"Math.sqrt\"\n"
+	source += "sqrt(4)"
+	testEmitter(t, source, "Math.sqrt(4);\n", 1)
+}
+
+func TestEmitExternImport(t *testing.T) {
+	source := "extern read :: async (string) -> string!string = \"readFile\" from \"node:fs/promises\"\n"
+	source += "f :: (path string) => string!string {\n"
+	source += "    read(path)\n"
+	source += "}\n"
+	source += "io.log(f)"
+	ast, errors := parser.Parse(strings.NewReader(source))
+	if len(errors) > 0 {
+		t.Fatalf("Expected no errors, got %#v", errors)
+	}
+	text := EmitProgram(ast)
+	if !strings.HasPrefix(text, "import { readFile as read } from \"node:fs/promises\";\n") {
+		t.Fatalf("Expected import, got:\n%v", text)
+	}
+	if !strings.Contains(text, "async function __attemptAsync(f) {") {
+		t.Fatalf("Expected async attempt helper, got:\n%v", text)
+	}
+	// the error type is 'string', not JS's Error
+	if !strings.Contains(text, "\"Err\", e instanceof Error ? e.message : String(e))") {
+		t.Fatalf("Expected exceptions to be turned into messages, got:\n%v", text)
+	}
+	if !strings.Contains(text, "return await __attemptAsync(() => read(path));") {
+		t.Fatalf("Expected wrapped call, got:\n%v", text)
+	}
+}
+
+func TestEmitExternResult(t *testing.T) {
+	source := "extern parse :: (string) -> string!number = \"JSON.parse\"\n"
+	source += "x := parse(\"42\") catch _ { 0 }"
+
+	expected := "let _tmp0;\n"
+	expected += "if ((_tmp0 = __attempt(() => JSON.parse(\"42\")))._tag === \"Ok\") _tmp0 = _tmp0._value;\n"
+	expected += "else {\n"
+	expected += "    _tmp0 = 0;\n"
+	expected += "}\n"
+	expected += "let x = _tmp0;\n"
+
+	testEmitter(t, source, expected, 1)
+}
+
+func TestEmitExternOption(t *testing.T) {
+	source := "extern find :: (string) -> ?number = \"lookup\"\n"
+	source += "find(\"key\") ?? 0"
+	testEmitter(t, source, "(lookup(\"key\") ?? undefined) ?? 0;\n", 1)
+}
+
+func TestEmitMath(t *testing.T) {
+	source := "math.max(math.pi, math.inf)"
+	testEmitter(t, source, "Math.max(Math.PI, Number.POSITIVE_INFINITY);\n", 0)
+}
@@ -6,7 +6,9 @@ import (
 	"github.com/bmelicque/test-parser/parser"
 )
 
-func (e *Emitter) findUninlinables(node parser.Node) {
+// Find the nodes that need to be extracted, in emission order
+func findUninlinables(node parser.Node) []parser.Node {
+	found := []parser.Node{}
 	parser.Walk(node, func(node parser.Node, skip func()) {
 		if _, ok := node.(*parser.FunctionExpression); ok {
 			skip()
@@ -20,11 +22,36 @@ func (e *Emitter) findUninlinables(node parser.Node) {
 			skip()
 			return
 		}
+		if i, ok := node.(*parser.IfExpression); ok && !hasPattern(i) {
+			// emitted as a ternary
+			skip()
+			return
+		}
+		if isPropagation(node) {
+			// the operand is evaluated before the test
+			operand := node.(*parser.UnaryExpression).Operand
+			found = append(found, findUninlinables(operand)...)
+			found = append(found, node)
+			skip()
+			return
+		}
 		if isUninlinable(node) {
-			e.uninlinables[node] = len(e.uninlinables)
+			found = append(found, node)
 			skip()
 		}
 	})
+	return found
+}
+
+// Check if a node is a 'try' on an option or a result, which returns early
+// on 'None' or 'Err'
+func isPropagation(node parser.Node) bool {
+	u, ok := node.(*parser.UnaryExpression)
+	if !ok || u.Operator.Kind() != parser.TryKeyword || u.Operand == nil {
+		return false
+	}
+	t := u.Operand.Type()
+	return isOptionType(t) || isResultType(t)
 }
 
 func isTypeDef(node parser.Node) bool {
@@ -32,12 +59,20 @@ func isTypeDef(node parser.Node) bool {
 	return ok && a.Operator.Kind() == parser.Define && isTypePattern(a.Pattern)
 }
 
+// Check if an if expression's condition is a pattern, like 'Some(s) := option'
+func hasPattern(i *parser.IfExpression) bool {
+	_, ok := i.Condition.(*parser.Assignment)
+	return ok
+}
+
 func isUninlinable(node parser.Node) bool {
 	switch n := node.(type) {
 	case *parser.CatchExpression,
 		*parser.ForExpression,
 		*parser.IfExpression,
-		*parser.MatchExpression:
+		*parser.MatchExpression,
+		*parser.SelectExpression,
+		*parser.TaskGroup:
 		return true
 	case *parser.Block:
 		return len(n.Statements) >= 2
@@ -47,52 +82,83 @@ func isUninlinable(node parser.Node) bool {
 }
 
 func (e *Emitter) extractUninlinables(node parser.Node) {
-	startAt := len(e.uninlinables)
-	e.findUninlinables(node)
-	for n, id := range e.uninlinables {
-		if id < startAt {
-			continue
-		}
+	for _, n := range findUninlinables(node) {
 		// outline block
-		e.write(fmt.Sprintf("let _tmp%v;\n", id))
+		name := e.freshIndexed("_tmp", 0)
+		e.uninlinables[n] = name
+		e.write(fmt.Sprintf("let %v;\n", name))
 		e.indent()
 		switch n := n.(type) {
 		case *parser.Block:
-			emitExtractedBlock(e, n, id)
+			emitExtractedBlock(e, n, name, nil)
 		case *parser.CatchExpression:
 			emitExtractedCatch(e, n)
+		case *parser.ForExpression:
+			// breaks with a value assign the loop's result
+			e.loopResults[n] = name
+			e.emitFor(n)
+		case *parser.SelectExpression:
+			e.emitSelect(n, name)
+		case *parser.TaskGroup:
+			e.emitTaskGroup(n, name)
+		case *parser.IfExpression:
+			e.emitIf(n, func(b *parser.Block, prelude func()) {
+				emitExtractedBlock(e, b, name, prelude)
+			})
+		case *parser.UnaryExpression:
+			e.write(fmt.Sprintf("if ((%v = ", name))
+			e.emitExpression(n.Operand)
+			if isResultType(n.Operand.Type()) {
+				e.write(fmt.Sprintf(")._tag === \"Err\") return %v;\n", name))
+			} else {
+				e.write(") === undefined) return;\n")
+			}
 		}
 		e.indent()
 	}
 }
 
-func emitExtractedBlock(e *Emitter, b *parser.Block, id int) {
+// Emit a block assigning its last expression to the given name.
+// The prelude, if any, is emitted at the start of the block.
+func emitExtractedBlock(e *Emitter, b *parser.Block, name string, prelude func()) {
 	e.write("{\n")
 	e.depth++
+	e.pushNames(b.Scope())
+	defer e.dropNames()
+	if prelude != nil {
+		prelude()
+	}
+	if len(b.Statements) == 0 {
+		e.depth--
+		e.indent()
+		e.write("}\n")
+		return
+	}
 	max := len(b.Statements) - 1
 	for _, statement := range b.Statements[:max] {
 		e.indent()
 		e.emit(statement)
 	}
 	e.indent()
-	e.write(fmt.Sprintf("_tmp%v = ", id))
+	if !parser.Exits(b.Statements[max]) {
+		e.write(fmt.Sprintf("%v = ", name))
+	}
 	e.emit(b.Statements[max])
 	e.depth--
 	e.indent()
 	e.write("}\n")
 }
 
+// Emit a catch expression, assigning either the happy value or the value of
+// its body to its temporary name
 func emitExtractedCatch(e *Emitter, c *parser.CatchExpression) {
-	e.write("try {\n")
-	e.depth++
-	e.indent()
-	id := e.uninlinables[c]
-	e.write(fmt.Sprintf("_tmp%v = ", id))
+	name := e.uninlinables[c]
+	e.write(fmt.Sprintf("if ((%v = ", name))
 	e.emitExpression(c.Left)
-	e.write(";\n")
-	e.depth--
-	e.write("} catch (")
-	e.emitIdentifier(c.Identifier)
-	e.write(") ")
-	emitExtractedBlock(e, c.Body, id)
+	e.write(fmt.Sprintf(")._tag === \"Ok\") %v = %v._value;\n", name, name))
+	e.indent()
+	e.write("else ")
+	emitExtractedBlock(e, c.Body, name, func() {
+		e.emitCatchBinding(c, name)
+	})
 }
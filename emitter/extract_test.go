@@ -39,9 +39,8 @@ func TestExtractCatch(t *testing.T) {
 
 	text := emitter.string()
 	expected := "let _tmp0;\n"
-	expected += "try {\n"
-	expected += "    _tmp0 = result;\n"
-	expected += "} catch (_) {\n"
+	expected += "if ((_tmp0 = result)._tag === \"Ok\") _tmp0 = _tmp0._value;\n"
+	expected += "else {\n"
 	expected += "    _tmp0 = 0;\n"
 	expected += "}\n"
 	if text != expected {
@@ -1,8 +1,16 @@
 package emitter
 
-import "github.com/bmelicque/test-parser/parser"
+import (
+	"fmt"
+
+	"github.com/bmelicque/test-parser/parser"
+)
 
 func (e *Emitter) emitFor(f *parser.ForExpression) {
+	if f.Label != nil {
+		e.write(e.sanitize(f.Label.Text()))
+		e.write(": ")
+	}
 	if f.Expr == nil {
 		e.write("while (true) ")
 		e.emitBlockStatement(f.Body)
@@ -19,7 +27,7 @@ func (e *Emitter) emitFor(f *parser.ForExpression) {
 	}
 	_, isTuple := binary.Left.(*parser.TupleExpression)
 	// TODO: switch over type
-	switch binary.Right.Type().(type) {
+	switch t := binary.Right.Type().(type) {
 	case parser.Range:
 		if isTuple {
 			emitForRangeTuple(e, f)
@@ -32,59 +40,94 @@ func (e *Emitter) emitFor(f *parser.ForExpression) {
 		} else {
 			emitForList(e, f)
 		}
+	case parser.String:
+		// 'for ... of' iterates over code points
+		emitForList(e, f)
+	case parser.TypeAlias:
+		// maps, sets and generators are iterable in JS, only maps' entries
+		// are tuples
+		switch {
+		case isTuple:
+			emitForMapTuple(e, f)
+		case isJsIterable(t):
+			emitForList(e, f)
+		default:
+			emitForIterator(e, f)
+		}
 	default:
 		panic("unexpected type in for loop!")
 	}
 
 }
 
+// Loops over range literals are emitted as counting loops.
+// Other ranges are iterable objects.
 func emitForRange(e *Emitter, f *parser.ForExpression) {
 	binary := f.Expr.(*parser.BinaryExpression)
-	r := binary.Right.(*parser.RangeExpression)
+	r, ok := parser.Unwrap(binary.Right).(*parser.RangeExpression)
+	if !ok {
+		emitForList(e, f)
+		return
+	}
 	identifier := binary.Left.(*parser.Identifier)
 
 	e.write("for (let ")
-	e.emitIdentifier(identifier)
-	e.write(" = ")
-	e.emitExpression(r.Left)
+	e.emitBinding(identifier, func() { emitRangeStart(e, r) })
+	step := declareRangeStep(e, r)
 	e.write("; ")
-	e.emitExpression(identifier)
-	if r.Operator.Kind() == parser.InclusiveRange {
-		e.write(" <= ")
-	} else {
-		e.write(" < ")
-	}
-	e.emitExpression(r.Right)
+	emitRangeCondition(e, r, identifier, step)
 	e.write("; ")
-	e.emitExpression(identifier)
-	e.write("++) ")
+	emitRangeIncrement(e, r, identifier, step)
+	e.write(") ")
 	e.emitBlockStatement(f.Body)
 }
 
 func emitForRangeTuple(e *Emitter, f *parser.ForExpression) {
 	binary := f.Expr.(*parser.BinaryExpression)
-	r := binary.Right.(*parser.RangeExpression)
+	r, ok := parser.Unwrap(binary.Right).(*parser.RangeExpression)
+	if !ok {
+		emitForRangeValueTuple(e, f)
+		return
+	}
 	tuple := binary.Left.(*parser.TupleExpression)
 
 	e.write("for (let ")
-	e.emitExpression(tuple.Elements[0])
-	e.write(" = ")
-	e.emitExpression(r.Left)
+	e.emitBinding(tuple.Elements[0].(*parser.Identifier), func() { emitRangeStart(e, r) })
+	step := declareRangeStep(e, r)
+	e.write(", ")
+	e.emitBinding(tuple.Elements[1].(*parser.Identifier), func() { e.write("0") })
+	e.write("; ")
+	emitRangeCondition(e, r, tuple.Elements[0], step)
+	e.write("; ")
+	emitRangeIncrement(e, r, tuple.Elements[0], step)
 	e.write(", ")
 	e.emitExpression(tuple.Elements[1])
-	e.write(" = 0; ")
-	e.emitExpression(tuple.Elements[0])
-	if r.Operator.Kind() == parser.InclusiveRange {
-		e.write(" <= ")
-	} else {
-		e.write(" < ")
-	}
-	e.emitExpression(r.Right)
+	e.write("++) ")
+	e.emitBlockStatement(f.Body)
+}
+
+// Like lists, range values are iterated on by index
+func emitForRangeValueTuple(e *Emitter, f *parser.ForExpression) {
+	binary := f.Expr.(*parser.BinaryExpression)
+	tuple := binary.Left.(*parser.TupleExpression)
+
+	r := e.fresh("__range")
+	e.write(fmt.Sprintf("const %v = ", r))
+	e.emitExpression(binary.Right)
+	e.write(";\n")
+
+	e.indent()
+	e.write("for (let ")
+	e.emitBinding(tuple.Elements[0].(*parser.Identifier), func() { e.write(r + ".at(0)") })
+	e.write(", ")
+	e.emitBinding(tuple.Elements[1].(*parser.Identifier), func() { e.write("0") })
 	e.write("; ")
+	e.emitExpression(tuple.Elements[1])
+	e.write(fmt.Sprintf(" < %v.len(); ", r))
 	e.emitExpression(tuple.Elements[0])
-	e.write("++, ")
+	e.write(fmt.Sprintf(" = %v.at(++", r))
 	e.emitExpression(tuple.Elements[1])
-	e.write("++) ")
+	e.write(")) ")
 	e.emitBlockStatement(f.Body)
 }
 
@@ -92,33 +135,98 @@ func emitForList(e *Emitter, f *parser.ForExpression) {
 	binary := f.Expr.(*parser.BinaryExpression)
 	identifier := binary.Left.(*parser.Identifier)
 
-	e.write("for (let ")
-	e.emitIdentifier(identifier)
+	if parser.IsAsyncIterable(binary.Right.Type()) {
+		e.write("for await (let ")
+	} else {
+		e.write("for (let ")
+	}
+	e.write(e.sanitize(identifier.Text()))
 	e.write(" of ")
 	e.emitExpression(binary.Right)
 	e.write(") ")
-	e.emitBlockStatement(f.Body)
+	emitLoopBody(e, f.Body, identifier)
 }
 
 func emitForListTuple(e *Emitter, f *parser.ForExpression) {
 	binary := f.Expr.(*parser.BinaryExpression)
 	tuple := binary.Left.(*parser.TupleExpression)
 
-	e.write("const __list = ")
+	list := e.fresh("__list")
+	e.write(fmt.Sprintf("const %v = ", list))
 	e.emitExpression(binary.Right)
 	e.write(";\n")
 
 	e.indent()
 	e.write("for (let ")
-	e.emitExpression(tuple.Elements[0])
-	e.write(" = __list[0], ")
-	e.emitExpression(tuple.Elements[1])
-	e.write(" = 0; ")
+	e.emitBinding(tuple.Elements[0].(*parser.Identifier), func() { e.write(list + "[0]") })
+	e.write(", ")
+	e.emitBinding(tuple.Elements[1].(*parser.Identifier), func() { e.write("0") })
+	e.write("; ")
 	e.emitExpression(tuple.Elements[1])
-	e.write(" < __list.length; ")
+	e.write(fmt.Sprintf(" < %v.length; ", list))
 	e.emitExpression(tuple.Elements[0])
-	e.write(" = __list[++")
+	e.write(fmt.Sprintf(" = %v[++", list))
 	e.emitExpression(tuple.Elements[1])
 	e.write("]) ")
 	e.emitBlockStatement(f.Body)
 }
+
+// Map entries are destructured, skipping '_' elements
+func emitForMapTuple(e *Emitter, f *parser.ForExpression) {
+	binary := f.Expr.(*parser.BinaryExpression)
+	tuple := binary.Left.(*parser.TupleExpression)
+
+	e.write("for (let [")
+	for i, element := range tuple.Elements {
+		if i > 0 {
+			e.write(", ")
+		}
+		if identifier, ok := element.(*parser.Identifier); ok && identifier.Text() != "_" {
+			e.write(e.sanitize(identifier.Text()))
+		}
+	}
+	e.write("] of ")
+	e.emitExpression(binary.Right)
+	e.write(") ")
+	emitLoopBody(e, f.Body, tuple.Elements...)
+}
+
+// Values implementing the Iterator trait are iterated on by calling 'next'
+// until it returns None
+func emitForIterator(e *Emitter, f *parser.ForExpression) {
+	binary := f.Expr.(*parser.BinaryExpression)
+	identifier := binary.Left.(*parser.Identifier)
+
+	iterator := e.fresh("__iterator")
+	e.write(fmt.Sprintf("for (let %v = ", iterator))
+	e.emitExpression(binary.Right)
+	e.write(", ")
+	e.emitBinding(identifier, func() { e.write(iterator + ".next()") })
+	e.write("; ")
+	e.emitIdentifier(identifier)
+	e.write(" !== undefined; ")
+	e.emitIdentifier(identifier)
+	e.write(fmt.Sprintf(" = %v.next()) ", iterator))
+	e.emitBlockStatement(f.Body)
+}
+
+// Emit the body of a 'for ... of' loop, whose variables cannot be declared as
+// cells in its header: they are wrapped at the start of the body if needed
+func emitLoopBody(e *Emitter, body *parser.Block, identifiers ...parser.Expression) {
+	for _, identifier := range identifiers {
+		if i, ok := identifier.(*parser.Identifier); ok && isCell(i) {
+			e.emitBlockStatementWith(body, func() { e.emitCells(identifiers...) })
+			return
+		}
+	}
+	e.emitBlockStatement(body)
+}
+
+func isJsIterable(t parser.TypeAlias) bool {
+	switch t.Name {
+	case "Map", "Set", "Generator", "AsyncGenerator":
+		return true
+	default:
+		return false
+	}
+}
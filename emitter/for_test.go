@@ -40,6 +40,21 @@ func TestEmitForInList(t *testing.T) {
 	testEmitter(t, source, expected, 1)
 }
 
+func TestEmitForReferencedElement(t *testing.T) {
+	source := "list := []number{1, 2, 3}\n"
+	source += "for x in list {\n"
+	source += "    r := &mut x\n"
+	source += "    *r = 0\n"
+	source += "}"
+
+	expected := "for (let x of list) {\n"
+	expected += "    x = { v: x };\n"
+	expected += "    let r = x;\n"
+	expected += "    r.v = 0;\n"
+	expected += "}\n"
+	testEmitter(t, source, expected, 1)
+}
+
 func TestEmitForInListTuple(t *testing.T) {
 	source := "list := []number{1, 2, 3}\n"
 	source += "for x, i in list { x + i }"
@@ -50,3 +65,142 @@ func TestEmitForInListTuple(t *testing.T) {
 	expected += "}\n"
 	testEmitter(t, source, expected, 1)
 }
+
+func TestEmitForInMap(t *testing.T) {
+	source := "m := Map{\"a\": 1}\n"
+	source += "for key, value in m { key, value }"
+
+	expected := "for (let [key, value] of m) {\n"
+	expected += "    [key, value];\n"
+	expected += "}\n"
+	testEmitter(t, source, expected, 1)
+}
+
+func TestEmitForInMapValues(t *testing.T) {
+	source := "m := Map{\"a\": 1}\n"
+	source += "for _, value in m { value }"
+
+	expected := "for (let [, value] of m) {\n"
+	expected += "    value;\n"
+	expected += "}\n"
+	testEmitter(t, source, expected, 1)
+}
+
+func TestEmitForInString(t *testing.T) {
+	source := "for c in \"abc\" { c }"
+	expected := "for (let c of \"abc\") {\n"
+	expected += "    c;\n"
+	expected += "}\n"
+	testEmitter(t, source, expected, 0)
+}
+
+func TestEmitForInSet(t *testing.T) {
+	source := "set := Set{1, 2}\n"
+	source += "for x in set { x }"
+	expected := "for (let x of set) {\n"
+	expected += "    x;\n"
+	expected += "}\n"
+	testEmitter(t, source, expected, 1)
+}
+
+func TestEmitForInIterator(t *testing.T) {
+	source := "Counter :: {\n"
+	source += "    list []number\n"
+	source += "}\n"
+	source += "(c mut Counter).next :: () => ?number { c.list.pop() }\n"
+	source += "counter := Counter{list: []number{1, 2}}\n"
+	source += "for x in counter { x }"
+
+	expected := "for (let __iterator = counter, x = __iterator.next(); x !== undefined; x = __iterator.next()) {\n"
+	expected += "    x;\n"
+	expected += "}\n"
+	testEmitter(t, source, expected, 3)
+}
+
+func TestEmitForInAsyncGenerator(t *testing.T) {
+	source := "extern fetch :: async (number) -> string\n"
+	source += "pages :: () => {\n"
+	source += "    yield fetch(1)\n"
+	source += "}\n"
+	source += "for page in pages() { page }"
+
+	expected := "for await (let page of pages()) {\n"
+	expected += "    page;\n"
+	expected += "}\n"
+	testEmitter(t, source, expected, 2)
+}
+
+func TestEmitLabeledLoop(t *testing.T) {
+	source := "outer: for i in 0..3 {\n"
+	source += "    for j in 0..3 {\n"
+	source += "        if j > i {\n"
+	source += "            continue outer\n"
+	source += "        }\n"
+	source += "        break outer\n"
+	source += "    }\n"
+	source += "}"
+
+	expected := "outer: for (let i = 0; i < 3; i++) {\n"
+	expected += "    for (let j = 0; j < 3; j++) {\n"
+	expected += "        if (j > i) {\n"
+	expected += "            continue outer;\n"
+	expected += "        }\n"
+	expected += "        break outer;\n"
+	expected += "    }\n"
+	expected += "}\n"
+	testEmitter(t, source, expected, 0)
+}
+
+func TestEmitLoopValue(t *testing.T) {
+	source := "found := outer: for i in 0..3 {\n"
+	source += "    for j in 0..3 {\n"
+	source += "        break outer i + j\n"
+	source += "    }\n"
+	source += "}"
+
+	expected := "let _tmp0;\n"
+	expected += "outer: for (let i = 0; i < 3; i++) {\n"
+	expected += "    for (let j = 0; j < 3; j++) {\n"
+	expected += "        _tmp0 = i + j;\n"
+	expected += "        break outer;\n"
+	expected += "    }\n"
+	expected += "}\n"
+	expected += "let found = _tmp0;\n"
+	testEmitter(t, source, expected, 0)
+}
+
+func TestEmitForInSteppedRange(t *testing.T) {
+	source := "for x in 0..10 by 3 { x }"
+	expected := "for (let x = 0; x < 10; x += 3) {\n    x;\n}\n"
+	testEmitter(t, source, expected, 0)
+}
+
+func TestEmitForInDescendingRange(t *testing.T) {
+	source := "for x, i in 10..=0 by -2 { x + i }"
+	expected := "for (let x = 10, i = 0; x >= 0; x -= 2, i++) {\n    x + i;\n}\n"
+	testEmitter(t, source, expected, 0)
+}
+
+func TestEmitForInDynamicStep(t *testing.T) {
+	source := "step := 2\n"
+	source += "for x in 0..10 by step { x }"
+	expected := "for (let x = 0, __step = step; __step > 0 ? x < 10 : __step < 0 && x > 10; x += __step) {\n    x;\n}\n"
+	testEmitter(t, source, expected, 1)
+}
+
+func TestEmitForInRangeValue(t *testing.T) {
+	source := "r := 0..10\n"
+	source += "for x in r { x }"
+	expected := "for (let x of r) {\n    x;\n}\n"
+	testEmitter(t, source, expected, 1)
+}
+
+func TestEmitForInRangeValueTuple(t *testing.T) {
+	source := "r := 0..10\n"
+	source += "for x, i in r { x + i }"
+	expected := "const __range = r;\n"
+	expected += "for (let x = __range.at(0), i = 0; i < __range.len(); x = __range.at(++i)) {\n"
+	expected += "    x + i;\n"
+	expected += "}\n"
+	testEmitter(t, source, expected, 1)
+}
@@ -6,7 +6,18 @@ import (
 	"github.com/bmelicque/test-parser/parser"
 )
 
-func (e *Emitter) emitFunctionBody(b *parser.Block, params *parser.TupleExpression) {
+func (e *Emitter) emitFunctionBody(f *parser.FunctionExpression) {
+	returned, group := e.returned, e.group
+	e.returned = f.Type().(parser.Function).Returned
+	e.group = ""
+	defer func() { e.returned, e.group = returned, group }()
+	if f.IsGenerator() {
+		// generators don't return their last value
+		e.returned = parser.Nil{}
+	}
+
+	b := f.Body
+	params := f.Params.Expr.(*parser.TupleExpression)
 	e.write("{")
 	if len(b.Statements) == 0 {
 		e.write("}")
@@ -14,19 +25,25 @@ func (e *Emitter) emitFunctionBody(b *parser.Block, params *parser.TupleExpressi
 	}
 	e.write("\n")
 	e.depth++
+	e.pushNames(b.Scope())
+	defer e.dropNames()
 
 	for _, param := range params.Elements {
-		if _, ok := param.Type().(parser.Ref); ok {
-			continue
-		}
-		name := param.(*parser.Param).Identifier.Text()
+		name := getParamName(param)
 		v, ok := b.Scope().Find(name)
 		if !ok {
 			panic("variable should be found in current scope...")
 		}
-		if isMutated(v) {
+		value := name
+		if _, ok := param.Type().(parser.Ref); !ok && isMutated(v) {
+			value = fmt.Sprintf("structuredClone(%v)", name)
+		}
+		if v.IsReferenced() {
+			value = fmt.Sprintf("{ v: %v }", value)
+		}
+		if value != name {
 			e.indent()
-			e.write(fmt.Sprintf("%v = structuredClone(%v);\n", name, name))
+			e.write(fmt.Sprintf("%v = %v;\n", name, value))
 		}
 	}
 	max := len(b.Statements) - 1
@@ -35,37 +52,95 @@ func (e *Emitter) emitFunctionBody(b *parser.Block, params *parser.TupleExpressi
 		e.emit(statement)
 	}
 	e.indent()
-	e.write("return ")
-	e.emit(b.Statements[max])
+	e.emitImplicitReturn(b.Statements[max])
 	e.depth--
 	e.indent()
-	e.write("}\n")
+	e.write("}")
 }
 
+// Arrow functions cannot be generators, which are emitted as 'function*'
 func (e *Emitter) emitFunctionExpression(f *parser.FunctionExpression) {
-	if f.Type().(parser.Function).Async {
+	if f.IsGenerator() {
+		e.write(getGeneratorKeyword(f))
+		e.write(" ")
+	} else if f.Type().(parser.Function).Async {
 		e.write("async ")
 	}
 	e.write("(")
 	args := f.Params.Expr.(*parser.TupleExpression).Elements
-	max := len(args) - 1
-	for i := range args[:max] {
-		e.emitFunctionParam(args[i])
+	for i, arg := range args {
+		if i > 0 {
+			e.write(", ")
+		}
+		e.emitFunctionParam(arg)
+	}
+	if f.IsGenerator() {
+		e.write(") ")
+	} else {
+		e.write(") => ")
+	}
+	e.emitFunctionBody(f)
+}
+
+func getGeneratorKeyword(f *parser.FunctionExpression) string {
+	if parser.IsAsyncIterable(f.Type().(parser.Function).Returned) {
+		return "async function*"
+	}
+	return "function*"
+}
+
+// Emit the last statement of a function body, returning its value if any
+func (e *Emitter) emitImplicitReturn(node parser.Node) {
+	switch node.(type) {
+	case *parser.Assignment, *parser.ForExpression, *parser.MatchExpression, *parser.Param:
+		e.emit(node)
+		return
+	}
+	if _, ok := e.returned.(parser.Nil); ok || parser.Exits(node) {
+		e.emit(node)
+		return
+	}
+	e.extractUninlinables(node)
+	e.write("return ")
+	e.emitReturnedValue(node.(parser.Expression))
+	e.write(";\n")
+}
+
+// Emit a returned value.
+// Functions returning results wrap their happy values as 'Ok'.
+func (e *Emitter) emitReturnedValue(value parser.Expression) {
+	if !isResultType(e.returned) || isResultType(value.Type()) {
+		e.emitExpression(value)
+		return
+	}
+	e.emitResult("Ok", value)
+}
+
+// Emit a result with the given tag and value
+func (e *Emitter) emitResult(tag string, value parser.Expression) {
+	e.addFlag(SumFlag)
+	e.write(fmt.Sprintf("new %v(\"%v\"", e.helper("_Sum"), tag))
+	if value != nil {
 		e.write(", ")
+		e.emitExpression(value)
 	}
-	e.emitFunctionParam(args[max])
-	e.write(") => ")
+	e.write(")")
+}
 
-	params := f.Params.Expr.(*parser.TupleExpression)
-	e.emitFunctionBody(f.Body, params)
+// Get the name of a param, which may be untyped in function arguments
+func getParamName(param parser.Expression) string {
+	if p, ok := param.(*parser.Param); ok {
+		return p.Identifier.Text()
+	}
+	return param.(*parser.Identifier).Text()
 }
 
 func (e *Emitter) emitFunctionParam(arg parser.Expression) {
 	switch arg := arg.(type) {
 	case *parser.Param:
-		e.emitIdentifier(arg.Identifier)
+		e.write(e.sanitize(arg.Identifier.Text()))
 	case *parser.Identifier:
-		e.emitIdentifier(arg)
+		e.write(e.sanitize(arg.Text()))
 	default:
 		panic("expected param or identifier")
 	}
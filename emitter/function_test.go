@@ -11,3 +11,76 @@ func TestEmitFunctionExpression(t *testing.T) {
 
 	testEmitter(t, source, expected, 0)
 }
+
+func TestEmitNilReturn(t *testing.T) {
+	source := "f :: (list []number) => { list.push(1) }"
+
+	expected := "const f = (list) => {\n"
+	expected += "    list.push(1);\n"
+	expected += "}\n"
+
+	testEmitter(t, source, expected, 0)
+}
+
+func TestEmitResultReturns(t *testing.T) {
+	source := "f :: (x number) => string!number {\n"
+	source += "    if x < 0 {\n"
+	source += "        throw \"negative\"\n"
+	source += "    }\n"
+	source += "    x\n"
+	source += "}"
+
+	expected := "const f = (x) => {\n"
+	expected += "    if (x < 0) {\n"
+	expected += "        return new _Sum(\"Err\", \"negative\");\n"
+	expected += "    }\n"
+	expected += "    return new _Sum(\"Ok\", x);\n"
+	expected += "}\n"
+
+	testEmitter(t, source, expected, 0)
+}
+
+func TestEmitGenerator(t *testing.T) {
+	source := "f :: (n number) => {\n"
+	source += "    yield n\n"
+	source += "    n + 1\n"
+	source += "}"
+
+	expected := "const f = function* (n) {\n"
+	expected += "    yield n;\n"
+	expected += "    n + 1;\n"
+	expected += "}\n"
+
+	testEmitter(t, source, expected, 0)
+}
+
+func TestEmitAsyncGenerator(t *testing.T) {
+	source := "extern fetch :: async (number) -> string\n"
+	source += "f :: () => {\n"
+	source += "    yield fetch(1)\n"
+	source += "}"
+
+	expected := "const f = async function* () {\n"
+	expected += "    yield await fetch(1);\n"
+	expected += "}\n"
+
+	testEmitter(t, source, expected, 1)
+}
+
+func TestEmitGeneratorMethod(t *testing.T) {
+	source := "Pair :: {\n"
+	source += "    a number\n"
+	source += "    b number\n"
+	source += "}\n"
+	source += "(p Pair).values :: () => {\n"
+	source += "    yield p.a\n"
+	source += "    yield p.b\n"
+	source += "}"
+
+	expected := "Pair.prototype.values = function* () {\n"
+	expected += "    yield this.a;\n"
+	expected += "    yield this.b;\n"
+	expected += "}\n"
+
+	testEmitter(t, source, expected, 1)
+}
@@ -0,0 +1,91 @@
+package emitter
+
+import (
+	"fmt"
+
+	"github.com/bmelicque/test-parser/parser"
+)
+
+// Task groups are instances of the __TaskGroup helper.
+// Calls started with 'async' are spawned in the group, which waits for them
+// at the end of its block. The first task to fail aborts the group's signal,
+// and its error is thrown when the group is waited for.
+// Leaving the block in any other way aborts the group's signal too, then
+// waits for the tasks to settle, ignoring their errors: tasks never outlive
+// their group.
+const taskGroupHelper = `class %v {
+    constructor() {
+        this.controller = new AbortController();
+        this.tasks = [];
+        this.failed = false;
+        this.error = undefined;
+    }
+    get signal() { return this.controller.signal }
+    spawn(task) {
+        this.tasks.push(task.catch((error) => {
+            if (this.failed) return;
+            this.failed = true;
+            this.error = error;
+            this.controller.abort();
+        }));
+        return task;
+    }
+    async wait() {
+        await Promise.all(this.tasks);
+        if (this.failed) throw this.error;
+    }
+    cancel() {
+        this.controller.abort();
+        return Promise.allSettled(this.tasks);
+    }
+}
+`
+
+// Emit a task group, like:
+//
+//	const __group = new __TaskGroup();
+//	try {
+//	    ...
+//	    await __group.wait();
+//	} finally {
+//	    await __group.cancel();
+//	}
+//
+// Groups used as values assign their last expression to the given name.
+// Statements are emitted inline, so that 'return' and 'break' keep working.
+func (e *Emitter) emitTaskGroup(g *parser.TaskGroup, name string) {
+	e.addFlag(TaskGroupFlag)
+	group := e.fresh("__group")
+	e.write(fmt.Sprintf("const %v = new %v();\n", group, e.helper("__TaskGroup")))
+	e.indent()
+	e.write("try {\n")
+	e.depth++
+	e.pushNames(g.Body.Scope())
+
+	outer := e.group
+	e.group = group
+	exits := false
+	for i, statement := range g.Body.Statements {
+		e.indent()
+		exits = parser.Exits(statement)
+		isLast := i == len(g.Body.Statements)-1
+		if name != "" && isLast && !exits && !g.EndsWithSpawn() {
+			e.write(fmt.Sprintf("%v = ", name))
+		}
+		e.emit(statement)
+	}
+	e.group = outer
+	if !exits {
+		e.indent()
+		e.write(fmt.Sprintf("await %v.wait();\n", group))
+	}
+
+	e.dropNames()
+	e.depth--
+	e.indent()
+	e.write("} finally {\n")
+	e.indent()
+	e.write(fmt.Sprintf("    await %v.cancel();\n", group))
+	e.indent()
+	e.write("}\n")
+}
@@ -0,0 +1,108 @@
+package emitter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bmelicque/test-parser/parser"
+)
+
+func TestEmitTaskGroup(t *testing.T) {
+	source := "extern fetch :: async (number) -> string\n"
+	source += "async {\n"
+	source += "    async fetch(1)\n"
+	source += "    fetch(2)\n"
+	source += "}"
+
+	expected := "const __group = new __TaskGroup();\n"
+	expected += "try {\n"
+	expected += "    __group.spawn(fetch(1));\n"
+	expected += "    await fetch(2);\n"
+	expected += "    await __group.wait();\n"
+	expected += "} finally {\n"
+	expected += "    await __group.cancel();\n"
+	expected += "}\n"
+
+	testEmitter(t, source, expected, 1)
+}
+
+func TestEmitTaskGroupValue(t *testing.T) {
+	source := "extern fetch :: async (number, Signal) -> string\n"
+	source += "s := async {\n"
+	source += "    p := async fetch(1)\n"
+	source += "    await p\n"
+	source += "}"
+
+	expected := "let _tmp0;\n"
+	expected += "const __group = new __TaskGroup();\n"
+	expected += "try {\n"
+	expected += "    let p = __group.spawn(fetch(1, __group.signal));\n"
+	expected += "    _tmp0 = await p;\n"
+	expected += "    await __group.wait();\n"
+	expected += "} finally {\n"
+	expected += "    await __group.cancel();\n"
+	expected += "}\n"
+	expected += "let s = _tmp0;\n"
+
+	testEmitter(t, source, expected, 1)
+}
+
+func TestEmitTaskGroupReturn(t *testing.T) {
+	source := "extern fetch :: async (number) -> string\n"
+	source += "f :: () => string {\n"
+	source += "    async {\n"
+	source += "        async fetch(1)\n"
+	source += "        return fetch(2)\n"
+	source += "    }\n"
+	source += "}"
+
+	expected := "const f = async () => {\n"
+	expected += "    const __group = new __TaskGroup();\n"
+	expected += "    try {\n"
+	expected += "        __group.spawn(fetch(1));\n"
+	expected += "        return await fetch(2);\n"
+	expected += "    } finally {\n"
+	expected += "        await __group.cancel();\n"
+	expected += "    }\n"
+	expected += "}\n"
+
+	testEmitter(t, source, expected, 1)
+}
+
+func TestEmitTaskGroupTrailingSpawn(t *testing.T) {
+	source := "extern consume :: async (number) -> ()\n"
+	source += "f :: () => {\n"
+	source += "    async {\n"
+	source += "        async consume(1)\n"
+	source += "    }\n"
+	source += "}"
+
+	expected := "const f = async () => {\n"
+	expected += "    const __group = new __TaskGroup();\n"
+	expected += "    try {\n"
+	expected += "        __group.spawn(consume(1));\n"
+	expected += "        await __group.wait();\n"
+	expected += "    } finally {\n"
+	expected += "        await __group.cancel();\n"
+	expected += "    }\n"
+	expected += "}\n"
+
+	testEmitter(t, source, expected, 1)
+}
+
+func TestEmitTaskGroupHelper(t *testing.T) {
+	source := "async {\n"
+	source += "    await promise.sleep(10)\n"
+	source += "}"
+	ast, errors := parser.Parse(strings.NewReader(source))
+	if len(errors) > 0 {
+		t.Fatalf("Expected no errors, got %#v", errors)
+	}
+	text := EmitProgram(ast)
+	if !strings.Contains(text, "class __TaskGroup {") {
+		t.Fatalf("Expected task group helper, got:\n%v", text)
+	}
+	if !strings.Contains(text, "this.controller = new AbortController();") {
+		t.Fatalf("Expected groups to be cancelled with an AbortController, got:\n%v", text)
+	}
+}
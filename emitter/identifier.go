@@ -1,18 +1,30 @@
 package emitter
 
 import (
-	"slices"
-
 	"github.com/bmelicque/test-parser/parser"
 )
 
 func (e *Emitter) emitIdentifier(i *parser.Identifier) {
 	text := i.Token.Text()
+	if v := i.Variable(); v != nil && v.IsPrelude() {
+		e.prelude[text] = true
+	}
 	if text == e.thisName {
 		e.write("this")
 		return
 	}
-	e.write(getSanitizedName(text))
+	if extern := i.Extern(); extern != nil && extern.ModuleName() == "" {
+		e.write(extern.Path())
+		return
+	}
+	e.write(e.sanitize(text))
+	if isCell(i) {
+		e.write(".v")
+	}
+	// narrowed results hold their payload in their value
+	if from := i.UnwrappedFrom(); from != nil && !isOptionType(from) {
+		e.write("._value")
+	}
 }
 
 var reservedWords = []string{
@@ -25,10 +37,3 @@ var reservedWords = []string{
 	"throw", "throws", "transient", "typeof", "var", "void", "volatile",
 	"while", "with", "yield",
 }
-
-func getSanitizedName(name string) string {
-	if slices.Contains(reservedWords, name) {
-		return name + "_"
-	}
-	return name
-}
@@ -3,25 +3,66 @@ package emitter
 import "github.com/bmelicque/test-parser/parser"
 
 func (e *Emitter) emitIfStatement(i *parser.IfExpression) {
-	e.write("if (")
-	// FIXME:
-	e.emitExpression(i.Condition.(parser.Expression))
+	e.emitIf(i, e.emitBlockStatementWith)
+}
+
+// Emit an if statement, using given function to emit its blocks.
+// Blocks are given a prelude declaring the identifiers bound by patterns.
+func (e *Emitter) emitIf(i *parser.IfExpression, emitBlock func(*parser.Block, func())) {
+	var prelude func()
+	if a, ok := i.Condition.(*parser.Assignment); ok {
+		value := e.emitTestedValue(a.Value)
+		matched := a.Value.Type()
+		e.write("if (")
+		e.emitPatternTest(a.Pattern, value, matched)
+		if len(getPatternElements(a.Pattern)) > 0 {
+			prelude = func() { e.emitPatternBindings(a.Pattern, value, matched) }
+		}
+	} else {
+		e.write("if (")
+		e.emitExpression(i.Condition.(parser.Expression))
+	}
 	e.write(") ")
-	e.emitBlockStatement(i.Body)
+	emitBlock(i.Body, prelude)
 	if i.Alternate == nil {
 		return
 	}
 	e.write(" else ")
 	switch alternate := i.Alternate.(type) {
 	case *parser.Block:
-		e.emitBlockStatement(alternate)
+		emitBlock(alternate, nil)
 	case *parser.IfExpression:
-		e.emitIfStatement(alternate)
+		if !needsTestedValue(alternate) {
+			e.emitIf(alternate, emitBlock)
+			return
+		}
+		// the tested value has to be declared before the test
+		e.write("{\n")
+		e.depth++
+		e.indent()
+		e.emitIf(alternate, emitBlock)
+		e.depth--
+		e.indent()
+		e.write("}\n")
 	}
 }
 
+// Check if an if expression's tested value should be stored before the test
+func needsTestedValue(i *parser.IfExpression) bool {
+	a, ok := i.Condition.(*parser.Assignment)
+	if !ok {
+		return false
+	}
+	_, ok = parser.Unwrap(a.Value).(*parser.Identifier)
+	return !ok
+}
+
 func (e *Emitter) emitIfExpression(i *parser.IfExpression) {
-	// FIXME:
+	if name, ok := e.uninlinables[i]; ok {
+		e.write(name)
+		delete(e.uninlinables, i)
+		return
+	}
 	e.emitExpression(i.Condition.(parser.Expression))
 	e.write(" ? ")
 	e.emitBlockExpression(i.Body)
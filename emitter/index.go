@@ -14,6 +14,23 @@ const (
 	NoFlags EmitterFlag = 0
 	SumFlag EmitterFlag = 1 << iota
 	RefComparisonFlag
+	FieldRefFlag
+	AttemptFlag
+	AsyncAttemptFlag
+	DisplayFlag
+	JsonEncodingFlag
+	JsonDecodingFlag
+	RangeFlag
+	AnyFlag
+	SleepFlag
+	TimeoutFlag
+	TaskGroupFlag
+	ChanFlag
+	SelectFlag
+	IndexFlag
+	ElementFlag
+	SliceFlag
+	PrintFlag
 )
 
 type Emitter struct {
@@ -21,17 +38,36 @@ type Emitter struct {
 	flags        EmitterFlag
 	builder      strings.Builder
 	thisName     string
+	returned     parser.ExpressionType // return type of the emitted function
 	constructors map[string]map[string]parser.Expression
-	uninlinables map[parser.Node]int
+	uninlinables map[parser.Node]string           // extracted node -> temporary name
+	loopResults  map[*parser.ForExpression]string // loop used as an expression -> temporary name
+	imports      []string                         // written at the top of the program
+	group        string                           // name of the enclosing task group, if any
+
+	names    *nameScope
+	root     *nameScope
+	reserved map[string]bool   // names used in user code
+	helpers  map[string]string // runtime helper -> emitted name
+	prelude  map[string]bool   // prelude definitions used by the program
+	renamed  map[string]string // reserved JS word -> emitted name
 }
 
 func makeEmitter() *Emitter {
+	root := newNameScope(nil)
 	return &Emitter{
 		depth:        0,
 		flags:        NoFlags,
 		builder:      strings.Builder{},
 		constructors: map[string]map[string]parser.Expression{},
-		uninlinables: map[parser.Node]int{},
+		uninlinables: map[parser.Node]string{},
+		loopResults:  map[*parser.ForExpression]string{},
+		names:        root,
+		root:         root,
+		reserved:     map[string]bool{},
+		helpers:      map[string]string{},
+		prelude:      map[string]bool{},
+		renamed:      map[string]string{},
 	}
 }
 
@@ -74,10 +110,25 @@ func (e *Emitter) emit(node parser.Node) {
 		e.emitFor(node)
 	case *parser.IfExpression:
 		e.emitIfStatement(node)
+	case *parser.SelectExpression:
+		e.emitSelect(node, "")
+	case *parser.TaskGroup:
+		e.emitTaskGroup(node, "")
 	case *parser.MatchExpression:
 		e.emitMatchStatement(*node)
 	case *parser.Exit:
 		e.emitExit(node)
+	case *parser.ExternDeclaration:
+		e.emitExternDeclaration(node)
+	case *parser.Param:
+		// declaration without initial value
+		e.write("let ")
+		if isCell(node.Identifier) {
+			e.emitBinding(node.Identifier, func() { e.write("undefined") })
+		} else {
+			e.write(e.sanitize(node.Identifier.Text()))
+		}
+		e.write(";\n")
 	case parser.Expression:
 		e.emitExpression(node)
 		e.write(";\n")
@@ -95,11 +146,32 @@ func (e *Emitter) emitExpression(expr parser.Expression) {
 	case *parser.CallExpression:
 		e.emitCallExpression(expr, true)
 	case *parser.CatchExpression:
-		id, ok := e.uninlinables[expr]
+		name, ok := e.uninlinables[expr]
 		if !ok {
 			panic("Catch expression should have been extracted!")
 		}
-		e.write(fmt.Sprintf("_tmp%v", id))
+		e.write(name)
+		delete(e.uninlinables, expr)
+	case *parser.ForExpression:
+		name, ok := e.uninlinables[expr]
+		if !ok {
+			panic("Loop should have been extracted!")
+		}
+		e.write(name)
+		delete(e.uninlinables, expr)
+	case *parser.SelectExpression:
+		name, ok := e.uninlinables[expr]
+		if !ok {
+			panic("Select expression should have been extracted!")
+		}
+		e.write(name)
+		delete(e.uninlinables, expr)
+	case *parser.TaskGroup:
+		name, ok := e.uninlinables[expr]
+		if !ok {
+			panic("Task group should have been extracted!")
+		}
+		e.write(name)
 		delete(e.uninlinables, expr)
 	case *parser.ComputedAccessExpression:
 		e.emitComputedAccessExpression(expr)
@@ -115,10 +187,12 @@ func (e *Emitter) emitExpression(expr parser.Expression) {
 		e.write(expr.Token.Text())
 	case *parser.ParenthesizedExpression:
 		e.write("(")
-		e.emit(expr.Expr)
+		e.emitExpression(expr.Expr)
 		e.write(")")
 	case *parser.PropertyAccessExpression:
 		e.emitPropertyAccessExpression(expr)
+	case *parser.RangeExpression:
+		e.emitRangeExpression(expr)
 	case *parser.TupleExpression:
 		e.emitTupleExpression(expr)
 	case *parser.UnaryExpression:
@@ -128,21 +202,81 @@ func (e *Emitter) emitExpression(expr parser.Expression) {
 
 func EmitProgram(nodes []parser.Node) string {
 	e := makeEmitter()
-	e.write("const io = { log(data) { console.log(data) } }\n")
-
+	prelude := parser.Prelude()
+	e.reserveNames(prelude)
+	e.reserveNames(nodes)
 	for _, node := range nodes {
 		e.emit(node)
 	}
-	e.write("\n")
+	program := e.string()
+	body := e.emitPrelude(prelude) + program
+
+	// Helpers are written first, since classes cannot be used before
+	// their declaration.
+	e.builder.Reset()
+	for _, i := range e.imports {
+		e.write(i)
+	}
 	if e.hasFlag(SumFlag) {
-		e.write("class _Sum {\n")
+		e.write(fmt.Sprintf("class %v {\n", e.helper("_Sum")))
 		e.write("    constructor(_tag, _value) {\n")
 		e.write("        this._tag = _tag;\n")
 		e.write("        if (arguments.length > 1) { this._value = _value }\n")
 		e.write("    }\n}\n")
 	}
 	if e.hasFlag(RefComparisonFlag) {
-		e.write("function __refEquals(a, b) { return a(4) == b(4) && a(2) == b(2) }\n")
+		e.write(fmt.Sprintf(refEqualsHelper, e.helper("__refEquals")))
+	}
+	if e.hasFlag(FieldRefFlag) {
+		e.write(fmt.Sprintf(fieldRefHelper, e.helper("__FieldRef")))
+	}
+	if e.hasFlag(AttemptFlag) {
+		sum := e.helper("_Sum")
+		e.write(fmt.Sprintf("function %v(f) {\n", e.helper("__attempt")))
+		e.write(fmt.Sprintf("    try { return new %v(\"Ok\", f()) }\n", sum))
+		e.write(fmt.Sprintf("    catch (e) { return new %v(\"Err\", e instanceof Error ? e.message : String(e)) }\n}\n", sum))
+	}
+	if e.hasFlag(AsyncAttemptFlag) {
+		sum := e.helper("_Sum")
+		e.write(fmt.Sprintf("async function %v(f) {\n", e.helper("__attemptAsync")))
+		e.write(fmt.Sprintf("    try { return new %v(\"Ok\", await f()) }\n", sum))
+		e.write(fmt.Sprintf("    catch (e) { return new %v(\"Err\", e instanceof Error ? e.message : String(e)) }\n}\n", sum))
+	}
+	if e.hasFlag(PrintFlag) {
+		e.write(fmt.Sprintf(printHelper, e.helper("__print")))
+	}
+	if e.hasFlag(DisplayFlag) {
+		e.write(fmt.Sprintf(showHelper, e.helper("__show")))
+	}
+	if e.hasFlag(JsonEncodingFlag) {
+		e.write(fmt.Sprintf(toJsonHelper, e.helper("__toJson")))
+	}
+	if e.hasFlag(JsonDecodingFlag) {
+		e.write(fmt.Sprintf(fromJsonHelper, e.helper("__fromJson"), e.helper("_Sum")))
 	}
+	if e.hasFlag(RangeFlag) {
+		e.write(fmt.Sprintf(rangeHelper, e.helper("__Range")))
+	}
+	if e.hasFlag(ChanFlag) {
+		e.write(fmt.Sprintf(chanHelper, e.helper("__Chan")))
+	}
+	if e.hasFlag(SelectFlag) {
+		e.write(fmt.Sprintf(selectHelper, e.helper("__select")))
+	}
+	if e.hasFlag(IndexFlag) || e.hasFlag(ElementFlag) {
+		e.write(fmt.Sprintf(indexHelper, e.helper("__index")))
+	}
+	if e.hasFlag(ElementFlag) {
+		e.write(fmt.Sprintf(elementHelper, e.helper("__at"), e.helper("__index")))
+	}
+	if e.hasFlag(SliceFlag) {
+		e.write(fmt.Sprintf(sliceHelper, e.helper("__slice")))
+	}
+	if e.hasFlag(TaskGroupFlag) {
+		e.write(fmt.Sprintf(taskGroupHelper, e.helper("__TaskGroup")))
+	}
+	e.emitPromiseHelpers()
+	e.write("\n")
+	e.write(body)
 	return e.string()
 }
@@ -32,3 +32,79 @@ func testEmitter(t *testing.T, source string, expected string, line int) {
 		t.Fatalf("expected output:\n%v\n\ngot:\n%v", expected, received)
 	}
 }
+
+func TestEmitPrelude(t *testing.T) {
+	ast, errors := parser.Parse(strings.NewReader("io.log(42)"))
+	if len(errors) > 0 {
+		t.Fatalf("Expected no errors, got %#v", errors)
+	}
+	text := EmitProgram(ast)
+	if text != "\nconsole.log(42);\n" {
+		t.Fatalf("Expected no prelude declarations, got:\n%v", text)
+	}
+}
+
+func TestEmitOptionalPreludeMethodCall(t *testing.T) {
+	source := "lists := [][]number{[]number{1}}\n"
+	source += "x := lists.get(0)\n"
+	source += "o := 1\n"
+	source += "x?.has(o)"
+	ast, errors := parser.Parse(strings.NewReader(source))
+	if len(errors) > 0 {
+		t.Fatalf("Expected no errors, got %#v", errors)
+	}
+	text := EmitProgram(ast)
+	expected := "((o1) => o1 === undefined ? undefined : __List_has(o1, o))(x);\n"
+	if !strings.HasSuffix(text, expected) {
+		t.Fatalf("Expected receiver not to capture 'o', got:\n%v", text)
+	}
+}
+
+func TestEmitOptionalPreludeMethodValue(t *testing.T) {
+	source := "lists := [][]number{[]number{1}}\n"
+	source += "x := lists.get(0)\n"
+	source += "has := x?.has"
+	expected := "let has = ((o) => o === undefined ? undefined : __List_has.bind(undefined, o))(x);\n"
+	testEmitter(t, source, expected, 2)
+}
+
+func TestEmitUsedPreludeMethods(t *testing.T) {
+	source := "l := []number{1}\n"
+	source += "l.set(0, 2)"
+	ast, errors := parser.Parse(strings.NewReader(source))
+	if len(errors) > 0 {
+		t.Fatalf("Expected no errors, got %#v", errors)
+	}
+	text := EmitProgram(ast)
+	if !strings.Contains(text, "function __List_set(l, index, value) {") {
+		t.Fatalf("Expected used method, got:\n%v", text)
+	}
+	// 'set' calls 'has'
+	if !strings.Contains(text, "function __List_has(l, index) {") {
+		t.Fatalf("Expected method used by the prelude, got:\n%v", text)
+	}
+	if strings.Contains(text, "__List_insert") || strings.Contains(text, "prototype") {
+		t.Fatalf("Expected only used methods, got:\n%v", text)
+	}
+	if !strings.HasSuffix(text, "__List_set(l, 0, 2);\n") {
+		t.Fatalf("Expected call to method function, got:\n%v", text)
+	}
+}
+
+func TestEmitSetAlgebra(t *testing.T) {
+	source := "a := Set{1, 2}\n"
+	source += "b := Set{2, 3}\n"
+	source += "a.intersection(b)"
+	ast, errors := parser.Parse(strings.NewReader(source))
+	if len(errors) > 0 {
+		t.Fatalf("Expected no errors, got %#v", errors)
+	}
+	text := EmitProgram(ast)
+	// JS sets only have 'intersection' since ES2025
+	if !strings.Contains(text, "function __Set_intersection(s, other) {") {
+		t.Fatalf("Expected prelude function, got:\n%v", text)
+	}
+	if !strings.HasSuffix(text, "__Set_intersection(a, b);\n") {
+		t.Fatalf("Expected call to prelude function, got:\n%v", text)
+	}
+}
@@ -5,18 +5,24 @@ import "github.com/bmelicque/test-parser/parser"
 func (e *Emitter) emitListInstance(constructor *parser.ListTypeExpression, args *parser.TupleExpression) {
 	e.write("[")
 	c := constructor.Expr
-	max := len(args.Elements) - 1
-	for _, arg := range args.Elements[:max] {
+	for i, arg := range args.Elements {
+		if i > 0 {
+			e.write(", ")
+		}
+		// only elements of instanciable types are written without their
+		// constructor, other values are written as is
+		switch c.(type) {
+		case *parser.ListTypeExpression, *parser.PropertyAccessExpression,
+			*parser.ComputedAccessExpression, *parser.Identifier:
+		default:
+			e.emitExpression(arg)
+			continue
+		}
 		e.emitInstance(
 			c,
 			&parser.TupleExpression{Elements: []parser.Expression{arg}},
 		)
-		e.write(", ")
 	}
-	e.emitInstance(
-		c,
-		&parser.TupleExpression{Elements: []parser.Expression{args.Elements[max]}},
-	)
 	e.write("]")
 }
 func (e *Emitter) emitMapInstance(args *parser.TupleExpression) {
@@ -35,6 +41,20 @@ func (e *Emitter) emitMapInstance(args *parser.TupleExpression) {
 
 	e.write("])")
 }
+func (e *Emitter) emitSetInstance(args *parser.TupleExpression) {
+	if len(args.Elements) == 0 {
+		e.write("new Set()")
+		return
+	}
+	e.write("new Set([")
+	for i, arg := range args.Elements {
+		if i > 0 {
+			e.write(", ")
+		}
+		e.emitExpression(arg)
+	}
+	e.write("])")
+}
 func emitMapEntry(e *Emitter, arg parser.Expression) {
 	entry := arg.(*parser.Entry)
 	var key parser.Expression
@@ -116,10 +136,18 @@ func (e *Emitter) emitInstance(constructor parser.Expression, args *parser.Tuple
 		e.emitListInstance(c, args)
 	case *parser.PropertyAccessExpression:
 		e.emitSumInstance(c, args)
+	case *parser.ComputedAccessExpression:
+		// type args are erased
+		e.emitInstance(c.Expr, args)
 	case *parser.Identifier:
-		if c.Text() == "Map" {
+		switch c.Text() {
+		case "Map":
 			e.emitMapInstance(args)
-		} else {
+		case "Set":
+			e.emitSetInstance(args)
+		case "Chan":
+			e.emitChanInstance(args)
+		default:
 			e.emitObjectInstance(c, args)
 		}
 	}
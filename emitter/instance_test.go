@@ -26,3 +26,22 @@ func TestObjectInstanceWithOptionals(t *testing.T) {
 
 	testEmitter(t, source, expected, 1)
 }
+
+func TestListInstance(t *testing.T) {
+	source := "[]number{1, 2, 3}"
+	expected := "[1, 2, 3];\n"
+	testEmitter(t, source, expected, 0)
+}
+
+func TestSetInstance(t *testing.T) {
+	testEmitter(t, "Set{1, 2}", "new Set([1, 2]);\n", 0)
+}
+
+func TestEmptySetInstance(t *testing.T) {
+	testEmitter(t, "Set[number]{}", "new Set();\n", 0)
+}
+
+func TestListOfTuplesInstance(t *testing.T) {
+	source := "[](number, string){(1, \"a\")}"
+	testEmitter(t, source, "[([1, \"a\"])];\n", 0)
+}
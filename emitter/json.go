@@ -0,0 +1,212 @@
+package emitter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bmelicque/test-parser/parser"
+)
+
+// JSON conversions are done at runtime by the __toJson and __fromJson
+// helpers, following a description of the converted type:
+//
+//	"number", "boolean", "string"
+//	["option", payload]
+//	["tuple", [elements...]]
+//	["list", element], ["set", element], ["map", key, value]
+//	["object", Class, [[key, type, property?]...]]
+//	["sum", "Name", Class, {Constructor: payload | null}]
+//
+// Fields with a default value are described as options, so that they can
+// be missing from decoded objects.
+const toJsonHelper = `function %[1]v(v, t) {
+    if (typeof t === "string") return v;
+    switch (t[0]) {
+    case "option":
+        return v === undefined ? null : %[1]v(v, t[1]);
+    case "tuple":
+        return v.map((x, i) => %[1]v(x, t[1][i]));
+    case "list":
+    case "set":
+        return [...v].map((x) => %[1]v(x, t[1]));
+    case "map":
+        if (t[1] === "string") return Object.fromEntries([...v].map(([k, x]) => [k, %[1]v(x, t[2])]));
+        return [...v].map(([k, x]) => [%[1]v(k, t[1]), %[1]v(x, t[2])]);
+    case "object":
+        return Object.fromEntries(t[2].map(([k, d, p = k]) => [k, %[1]v(v[p], d)]));
+    case "sum":
+        if (t[3][v._tag] === null) return { tag: v._tag };
+        return { tag: v._tag, value: %[1]v(v._value, t[3][v._tag]) };
+    }
+}
+`
+
+const fromJsonHelper = `function %[1]v(text, t) {
+    let json;
+    try { json = JSON.parse(text) }
+    catch (e) { return new %[2]v("Err", "invalid JSON: " + e.message) }
+    const isObject = (v) => typeof v === "object" && v !== null && !Array.isArray(v);
+    const expect = (ok, path, expected) => {
+        if (!ok) throw (path ? path + ": " : "") + "expected " + expected;
+    };
+    const decode = (v, t, path) => {
+        if (typeof t === "string") {
+            expect(typeof v === t, path, t);
+            return v;
+        }
+        switch (t[0]) {
+        case "option":
+            return v === null || v === undefined ? undefined : decode(v, t[1], path);
+        case "tuple":
+            expect(Array.isArray(v) && v.length === t[1].length, path, t[1].length + "-tuple");
+            return v.map((x, i) => decode(x, t[1][i], path + "[" + i + "]"));
+        case "list":
+            expect(Array.isArray(v), path, "array");
+            return v.map((x, i) => decode(x, t[1], path + "[" + i + "]"));
+        case "set":
+            expect(Array.isArray(v), path, "array");
+            return new Set(v.map((x, i) => decode(x, t[1], path + "[" + i + "]")));
+        case "map":
+            if (t[1] === "string") {
+                expect(isObject(v), path, "object");
+                return new Map(Object.entries(v).map(([k, x]) => [k, decode(x, t[2], path + "[" + JSON.stringify(k) + "]")]));
+            }
+            expect(Array.isArray(v), path, "array");
+            return new Map(v.map((entry, i) => {
+                const p = path + "[" + i + "]";
+                expect(Array.isArray(entry) && entry.length === 2, p, "entry");
+                return [decode(entry[0], t[1], p + "[0]"), decode(entry[1], t[2], p + "[1]")];
+            }));
+        case "object":
+            expect(isObject(v), path, "object");
+            return new t[1](...t[2].map(([k, d]) => decode(v[k], d, path + "." + k)));
+        case "sum": {
+            expect(isObject(v) && Object.hasOwn(t[3], v.tag), path, t[1]);
+            const d = t[3][v.tag];
+            return d === null ? new t[2](v.tag) : new t[2](v.tag, decode(v.value, d, path + ".value"));
+        }
+        }
+    };
+    try { return new %[2]v("Ok", decode(json, t, "")) }
+    catch (e) { return new %[2]v("Err", e) }
+}
+`
+
+// Emit a call like 'toJson(value)'.
+// Primitive values are encoded directly.
+func (e *Emitter) emitJsonEncoding(expr *parser.CallExpression) {
+	value := expr.Args.Expr.(*parser.TupleExpression).Elements[0]
+	description := e.describeJson(value.Type())
+	e.write("JSON.stringify(")
+	if strings.HasPrefix(description, "\"") {
+		e.emitExpression(value)
+		e.write(")")
+		return
+	}
+	e.addFlag(JsonEncodingFlag)
+	e.write(e.helper("__toJson"))
+	e.write("(")
+	e.emitExpression(value)
+	e.write(", ")
+	e.write(description)
+	e.write("))")
+}
+
+// Emit a call like 'fromJson(Type, text)'
+func (e *Emitter) emitJsonDecoding(expr *parser.CallExpression) {
+	e.addFlag(SumFlag | JsonDecodingFlag)
+	args := expr.Args.Expr.(*parser.TupleExpression).Elements
+	e.write(e.helper("__fromJson"))
+	e.write("(")
+	e.emitExpression(args[1])
+	e.write(", ")
+	e.write(e.describeJson(args[0].Type().(parser.Type).Value))
+	e.write(")")
+}
+
+// Get the description of a type, as used by JSON conversions
+func (e *Emitter) describeJson(t parser.ExpressionType) string {
+	switch t := t.(type) {
+	case parser.Boolean, parser.Number, parser.String:
+		return fmt.Sprintf("\"%v\"", t.Text())
+	case parser.Generic:
+		return e.describeJson(t.Value)
+	case parser.Tuple:
+		elements := make([]string, len(t.Elements))
+		for i, element := range t.Elements {
+			elements[i] = e.describeJson(element)
+		}
+		return fmt.Sprintf("[\"tuple\", [%v]]", strings.Join(elements, ", "))
+	case parser.List:
+		return fmt.Sprintf("[\"list\", %v]", e.describeJson(t.Element))
+	case parser.Set:
+		return fmt.Sprintf("[\"set\", %v]", e.describeJson(t.Element))
+	case parser.Map:
+		return fmt.Sprintf("[\"map\", %v, %v]", e.describeJson(t.Key), e.describeJson(t.Value))
+	case parser.TypeAlias:
+		return e.describeJsonAlias(t)
+	default:
+		// rejected while type checking
+		return "null"
+	}
+}
+
+func (e *Emitter) describeJsonAlias(t parser.TypeAlias) string {
+	if isOptionType(t) {
+		return fmt.Sprintf("[\"option\", %v]", e.describeJson(getOptionPayload(t)))
+	}
+	switch ref := t.Ref.(type) {
+	case parser.Object:
+		return e.describeJsonObject(e.sanitize(t.Name), ref)
+	case parser.Sum:
+		class := e.sanitize(t.Name)
+		if isResultType(t) {
+			class = e.helper("_Sum")
+		}
+		return e.describeJsonSum(t.Text(), class, ref)
+	default:
+		return e.describeJson(ref)
+	}
+}
+
+// Fields are described in the order of the constructor's params
+func (e *Emitter) describeJsonObject(class string, object parser.Object) string {
+	fields := []string{}
+	members := append(object.Members[:0:0], object.Members...)
+	for i, member := range append(members, object.Defaults...) {
+		d := e.describeJson(member.Type)
+		if i >= len(object.Members) {
+			d = fmt.Sprintf("[\"option\", %v]", d)
+		}
+		field := fmt.Sprintf("[%q, %v", member.Name, d)
+		if property := e.sanitize(member.Name); property != member.Name {
+			field += fmt.Sprintf(", %q", property)
+		}
+		fields = append(fields, field+"]")
+	}
+	return fmt.Sprintf("[\"object\", %v, [%v]]", class, strings.Join(fields, ", "))
+}
+
+// Constructors with several params hold a tuple
+func (e *Emitter) describeJsonSum(name string, class string, sum parser.Sum) string {
+	names := make([]string, 0, len(sum.Members))
+	for constructor := range sum.Members {
+		names = append(names, constructor)
+	}
+	sort.Strings(names)
+	payloads := make([]string, len(names))
+	for i, constructor := range names {
+		params := sum.Members[constructor].Params
+		d := "null"
+		switch {
+		case params == nil || len(params.Elements) == 0:
+		case len(params.Elements) == 1:
+			d = e.describeJson(params.Elements[0])
+		default:
+			d = e.describeJson(*params)
+		}
+		payloads[i] = fmt.Sprintf("%v: %v", constructor, d)
+	}
+	return fmt.Sprintf("[\"sum\", %q, %v, {%v}]", name, class, strings.Join(payloads, ", "))
+}
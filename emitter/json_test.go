@@ -0,0 +1,73 @@
+package emitter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bmelicque/test-parser/parser"
+)
+
+func TestEmitPrimitiveJsonEncoding(t *testing.T) {
+	testEmitter(t, "toJson(\"a\")", "JSON.stringify(\"a\");\n", 0)
+}
+
+func TestEmitJsonEncoding(t *testing.T) {
+	source := "l := [](number, ?string){}\n"
+	source += "toJson(l)"
+	expected := "JSON.stringify(__toJson(l, [\"list\", [\"tuple\", [\"number\", [\"option\", \"string\"]]]]));\n"
+	testEmitter(t, source, expected, 1)
+}
+
+func TestEmitObjectJsonDecoding(t *testing.T) {
+	source := "Point :: {\n"
+	source += "    x number\n"
+	source += "    tags: Set[string]{}\n"
+	source += "}\n"
+	source += "fromJson(Point, \"{}\")"
+	expected := "__fromJson(\"{}\", [\"object\", Point, [[\"x\", \"number\"], [\"tags\", [\"option\", [\"set\", \"string\"]]]]]);\n"
+	testEmitter(t, source, expected, 1)
+}
+
+func TestEmitSumJsonDecoding(t *testing.T) {
+	source := "Shape :: | Circle{number} | Rect{number, number} | Empty\n"
+	source += "fromJson(Map[string, Shape], \"{}\")"
+	expected := "__fromJson(\"{}\", [\"map\", \"string\", [\"sum\", \"Shape\", Shape, {Circle: \"number\", Empty: null, Rect: [\"tuple\", [\"number\", \"number\"]]}]]);\n"
+	testEmitter(t, source, expected, 1)
+}
+
+func TestEmitJsonDecodingHelper(t *testing.T) {
+	source := "User :: {\n"
+	source += "    name string\n"
+	source += "}\n"
+	source += "Team :: {\n"
+	source += "    users []User\n"
+	source += "}\n"
+	source += "io.log(fromJson(Team, \"{}\"))"
+	ast, errors := parser.Parse(strings.NewReader(source))
+	if len(errors) > 0 {
+		t.Fatalf("Expected no errors, got %#v", errors)
+	}
+	text := EmitProgram(ast)
+
+	// errors are prefixed by their path, like '.users[1].name: expected string'
+	for _, line := range []string{
+		"class _Sum {",
+		"function __fromJson(text, t) {",
+		"        if (!ok) throw (path ? path + \": \" : \"\") + \"expected \" + expected;",
+		"            return v.map((x, i) => decode(x, t[1], path + \"[\" + i + \"]\"));",
+		"            return new t[1](...t[2].map(([k, d]) => decode(v[k], d, path + \".\" + k)));",
+		"    try { return new _Sum(\"Ok\", decode(json, t, \"\")) }",
+		"    catch (e) { return new _Sum(\"Err\", e) }",
+	} {
+		if !strings.Contains(text, line+"\n") {
+			t.Fatalf("Expected line:\n%v\ngot:\n%v", line, text)
+		}
+	}
+	call := "console.log(__fromJson(\"{}\", [\"object\", Team, [[\"users\", [\"list\", [\"object\", User, [[\"name\", \"string\"]]]]]]]));\n"
+	if !strings.HasSuffix(text, call) {
+		t.Fatalf("Expected call to the decoding helper, got:\n%v", text)
+	}
+	if strings.Contains(text, "__toJson") {
+		t.Fatalf("Expected no encoding helper, got:\n%v", text)
+	}
+}
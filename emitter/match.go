@@ -7,36 +7,35 @@ import (
 )
 
 func (e *Emitter) emitMatchStatement(m parser.MatchExpression) {
-	e.write("const _m = ")
+	matched := e.fresh("_m")
+	e.write(fmt.Sprintf("const %v = ", matched))
 	e.emitExpression(m.Value)
 	e.write(";\n")
-	if _, ok := m.Value.Type().(parser.Sum); ok {
-		e.write("switch (_m._tag) {\n")
-	} else {
-		e.write("switch (_m.constructor) {\n")
+	e.indent()
+	t := m.Value.Type()
+	switch {
+	case isOptionType(t):
+		e.write(fmt.Sprintf("switch (%v === undefined ? \"None\" : \"Some\") {\n", matched))
+	case isSumType(t):
+		e.write(fmt.Sprintf("switch (%v._tag) {\n", matched))
+	default:
+		e.write(fmt.Sprintf("switch (%v.constructor) {\n", matched))
 	}
 	for _, c := range m.Cases {
 		e.indent()
 		if c.IsCatchall() {
-			e.write("default:")
-		} else if call, ok := c.Pattern.(*parser.CallExpression); ok {
-			e.write("case ")
-			e.emitExpression(call.Callee)
-			e.write(": {\n")
-		} else if id, ok := c.Pattern.(*parser.Identifier); ok {
+			e.write("default: {\n")
+		} else if constructor := getPatternConstructor(c.Pattern); isSumType(t) {
+			e.write(fmt.Sprintf("case \"%v\": {\n", constructor.Text()))
+		} else {
 			e.write("case ")
-			e.emitIdentifier(id)
+			e.emitIdentifier(constructor)
 			e.write(": {\n")
 		}
 		e.depth++
-		if c.Pattern != nil {
-			id := c.Pattern.(*parser.Identifier)
-			e.indent()
-			if _, ok := m.Value.Type().(parser.Sum); ok {
-				e.write(fmt.Sprintf("let %v = _m._value;\n", id.Text()))
-			} else {
-				e.write(fmt.Sprintf("let %v = _m;\n", id.Text()))
-			}
+		e.pushNames(nil)
+		if !c.IsCatchall() {
+			e.emitPatternBindings(c.Pattern, matched, t)
 		}
 		for _, s := range c.Statements {
 			e.indent()
@@ -44,9 +43,10 @@ func (e *Emitter) emitMatchStatement(m parser.MatchExpression) {
 		}
 		e.indent()
 		e.write("break;\n")
+		e.depth--
 		e.indent()
 		e.write("}\n")
-		e.depth--
+		e.dropNames()
 	}
 	e.indent()
 	e.write("}\n")
@@ -0,0 +1,116 @@
+package emitter
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/bmelicque/test-parser/parser"
+)
+
+// A JS scope in the emitted code.
+// It holds every name that cannot be used for generated code.
+type nameScope struct {
+	taken map[string]bool
+	outer *nameScope
+}
+
+func newNameScope(outer *nameScope) *nameScope {
+	return &nameScope{taken: map[string]bool{}, outer: outer}
+}
+
+func (s *nameScope) has(name string) bool {
+	if s.taken[name] {
+		return true
+	}
+	return s.outer != nil && s.outer.has(name)
+}
+
+// Push a new scope, reserving every user binding visible from given scope
+func (e *Emitter) pushNames(scope *parser.Scope) {
+	e.names = newNameScope(e.names)
+	if scope == nil {
+		return
+	}
+	for _, name := range scope.Names() {
+		e.names.taken[name] = true
+	}
+}
+
+func (e *Emitter) dropNames() {
+	e.names = e.names.outer
+}
+
+// Reserve every name used in user code, so that generated names
+// never shadow nor get shadowed by user bindings.
+func (e *Emitter) reserveNames(nodes []parser.Node) {
+	for _, node := range nodes {
+		parser.Walk(node, func(n parser.Node, skip func()) {
+			switch n := n.(type) {
+			case *parser.Block:
+				if n.Scope() == nil {
+					return
+				}
+				for _, name := range n.Scope().Names() {
+					e.reserved[name] = true
+				}
+			case *parser.Identifier:
+				e.reserved[n.Text()] = true
+			}
+		})
+	}
+}
+
+func (e *Emitter) isTaken(name string) bool {
+	return e.reserved[name] || e.names.has(name)
+}
+
+// Get a collision-free name based on the given one, and bind it in current scope.
+func (e *Emitter) fresh(base string) string {
+	if !e.isTaken(base) {
+		e.names.taken[base] = true
+		return base
+	}
+	return e.freshIndexed(base, 1)
+}
+
+// Get a collision-free name made of the given base and an index,
+// starting at the given one.
+func (e *Emitter) freshIndexed(base string, from int) string {
+	for i := from; ; i++ {
+		name := fmt.Sprintf("%v%v", base, i)
+		if !e.isTaken(name) {
+			e.names.taken[name] = true
+			return name
+		}
+	}
+}
+
+// Get the name of a runtime helper.
+// Helpers live in the program scope, so their names are allocated only once.
+func (e *Emitter) helper(base string) string {
+	if name, ok := e.helpers[base]; ok {
+		return name
+	}
+	current := e.names
+	e.names = e.root
+	name := e.fresh(base)
+	e.names = current
+	e.helpers[base] = name
+	return name
+}
+
+// Get the emitted name for a user identifier
+func (e *Emitter) sanitize(name string) string {
+	if !slices.Contains(reservedWords, name) {
+		return name
+	}
+	if renamed, ok := e.renamed[name]; ok {
+		return renamed
+	}
+	current := e.names
+	e.names = e.root
+	renamed := e.fresh(name + "_")
+	e.names = current
+	e.renamed[name] = renamed
+	return renamed
+}
@@ -0,0 +1,66 @@
+package emitter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bmelicque/test-parser/parser"
+)
+
+func TestFreshNameCollision(t *testing.T) {
+	emitter := makeEmitter()
+	emitter.reserved["_m"] = true
+	emitter.reserved["_m1"] = true
+
+	if name := emitter.fresh("_m"); name != "_m2" {
+		t.Fatalf("Expected '_m2', got '%v'", name)
+	}
+	if name := emitter.fresh("_m"); name != "_m3" {
+		t.Fatalf("Expected '_m3', got '%v'", name)
+	}
+}
+
+func TestFreshNameInSiblingScopes(t *testing.T) {
+	emitter := makeEmitter()
+	emitter.pushNames(nil)
+	first := emitter.freshIndexed("_tmp", 0)
+	emitter.dropNames()
+	emitter.pushNames(nil)
+	second := emitter.freshIndexed("_tmp", 0)
+	emitter.dropNames()
+
+	if first != "_tmp0" || second != "_tmp0" {
+		t.Fatalf("Expected '_tmp0' twice, got '%v' and '%v'", first, second)
+	}
+}
+
+func TestHelperNameCollision(t *testing.T) {
+	source := "_Sum := 42\n"
+	source += "io.log(_Sum)\n"
+	source += "Shape :: | Circle{number} | Square{number}"
+	ast, errors := parser.Parse(strings.NewReader(source))
+	if len(errors) > 0 {
+		t.Fatalf("Expected no errors, got %#v", errors)
+	}
+	text := EmitProgram(ast)
+	if !strings.Contains(text, "class _Sum1 {") {
+		t.Fatalf("Expected renamed helper, got:\n%v", text)
+	}
+	if !strings.Contains(text, "class Shape extends _Sum1 {}") {
+		t.Fatalf("Expected sum type to extend renamed helper, got:\n%v", text)
+	}
+}
+
+func TestSanitizedNameCollision(t *testing.T) {
+	source := "class := 1\n"
+	source += "class_ := 2\n"
+	source += "io.log(class + class_)"
+	ast, errors := parser.Parse(strings.NewReader(source))
+	if len(errors) > 0 {
+		t.Fatalf("Expected no errors, got %#v", errors)
+	}
+	text := EmitProgram(ast)
+	if !strings.Contains(text, "console.log(class_1 + class_);") {
+		t.Fatalf("Expected renamed reserved word, got:\n%v", text)
+	}
+}
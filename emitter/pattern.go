@@ -0,0 +1,127 @@
+package emitter
+
+import (
+	"fmt"
+
+	"github.com/bmelicque/test-parser/parser"
+)
+
+// Options are emitted as their value when present, and as undefined when absent.
+// Other sum types are emitted as instances of _Sum, holding a tag and a value.
+// Trait values are class instances.
+func isOptionType(t parser.ExpressionType) bool {
+	alias, ok := t.(parser.TypeAlias)
+	return ok && alias.Name == "?"
+}
+
+// Results are sum types, tagged "Ok" or "Err"
+func isResultType(t parser.ExpressionType) bool {
+	alias, ok := t.(parser.TypeAlias)
+	return ok && alias.Name == "!"
+}
+
+// Get the type held by an option
+func getOptionPayload(t parser.ExpressionType) parser.ExpressionType {
+	return t.(parser.TypeAlias).Params[0].Value
+}
+
+func isSumType(t parser.ExpressionType) bool {
+	switch t := t.(type) {
+	case parser.Sum:
+		return true
+	case parser.TypeAlias:
+		_, ok := t.Ref.(parser.Sum)
+		return ok
+	default:
+		return false
+	}
+}
+
+// Get the name of the constructor used in a pattern, or "_" for catch-alls
+func getPatternConstructor(pattern parser.Expression) *parser.Identifier {
+	switch pattern := pattern.(type) {
+	case *parser.Identifier:
+		return pattern
+	case *parser.CallExpression:
+		identifier, _ := pattern.Callee.(*parser.Identifier)
+		return identifier
+	case *parser.InstanceExpression:
+		identifier, _ := pattern.Typing.(*parser.Identifier)
+		return identifier
+	default:
+		return nil
+	}
+}
+
+func getPatternElements(pattern parser.Expression) []parser.Expression {
+	switch pattern := pattern.(type) {
+	case *parser.CallExpression:
+		if pattern.Args == nil || pattern.Args.Expr == nil {
+			return nil
+		}
+		if tuple, ok := pattern.Args.Expr.(*parser.TupleExpression); ok {
+			return tuple.Elements
+		}
+		return []parser.Expression{pattern.Args.Expr}
+	case *parser.InstanceExpression:
+		return pattern.Args.Expr.(*parser.TupleExpression).Elements
+	default:
+		return nil
+	}
+}
+
+// Get a name to refer to a tested value.
+// If the value is not a simple identifier, it is stored in a constant first.
+func (e *Emitter) emitTestedValue(value parser.Expression) string {
+	if identifier, ok := parser.Unwrap(value).(*parser.Identifier); ok {
+		return e.sanitize(identifier.Text())
+	}
+	name := e.fresh("_m")
+	e.write(fmt.Sprintf("const %v = ", name))
+	e.emitExpression(value)
+	e.write(";\n")
+	e.indent()
+	return name
+}
+
+// Emit the test of a value against a constructor pattern
+func (e *Emitter) emitPatternTest(pattern parser.Expression, value string, matched parser.ExpressionType) {
+	constructor := getPatternConstructor(pattern).Text()
+	switch {
+	case isOptionType(matched) && constructor == "None":
+		e.write(fmt.Sprintf("%v === undefined", value))
+	case isOptionType(matched):
+		e.write(fmt.Sprintf("%v !== undefined", value))
+	case isSumType(matched):
+		e.write(fmt.Sprintf("%v._tag === \"%v\"", value, constructor))
+	default:
+		e.write(fmt.Sprintf("%v instanceof %v", value, e.sanitize(constructor)))
+	}
+}
+
+// Emit the declarations of the identifiers bound by a pattern
+func (e *Emitter) emitPatternBindings(pattern parser.Expression, value string, matched parser.ExpressionType) {
+	elements := getPatternElements(pattern)
+	if len(elements) == 0 {
+		return
+	}
+	if isSumType(matched) && !isOptionType(matched) {
+		value += "._value"
+	}
+	e.indent()
+	if len(elements) == 1 {
+		e.write("let ")
+		e.emitBinding(elements[0].(*parser.Identifier), func() { e.write(value) })
+		e.write(";\n")
+		return
+	}
+	e.write("let [")
+	for i, element := range elements {
+		if i > 0 {
+			e.write(", ")
+		}
+		e.write(e.sanitize(element.(*parser.Identifier).Text()))
+	}
+	e.write(fmt.Sprintf("] = %v;\n", value))
+	e.emitCells(elements...)
+}
@@ -0,0 +1,81 @@
+package emitter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bmelicque/test-parser/parser"
+)
+
+func TestEmitOptionPattern(t *testing.T) {
+	source := "f :: (option ?number) => number {\n"
+	source += "    if Some(s) := option {\n"
+	source += "        return s\n"
+	source += "    }\n"
+	source += "    0\n"
+	source += "}\n"
+	source += "io.log(f)"
+	ast, errors := parser.Parse(strings.NewReader(source))
+	if len(errors) > 0 {
+		t.Fatalf("Expected no errors, got %#v", errors)
+	}
+	text := EmitProgram(ast)
+	expected := "    if (option !== undefined) {\n"
+	expected += "        let s = option;\n"
+	expected += "        return s;\n"
+	expected += "    }\n"
+	if !strings.Contains(text, expected) {
+		t.Fatalf("Expected string:\n%v\ngot:\n%v", expected, text)
+	}
+}
+
+func TestEmitNarrowedResult(t *testing.T) {
+	source := "f :: (result string!number) => number {\n"
+	source += "    if Err(e) := result {\n"
+	source += "        io.log(e)\n"
+	source += "        return 0\n"
+	source += "    }\n"
+	source += "    result + 1\n"
+	source += "}\n"
+	source += "io.log(f)"
+	ast, errors := parser.Parse(strings.NewReader(source))
+	if len(errors) > 0 {
+		t.Fatalf("Expected no errors, got %#v", errors)
+	}
+	text := EmitProgram(ast)
+	if !strings.Contains(text, "if (result._tag === \"Err\") {") {
+		t.Fatalf("Expected tag test, got:\n%v", text)
+	}
+	if !strings.Contains(text, "return result._value + 1;") {
+		t.Fatalf("Expected unwrapped result, got:\n%v", text)
+	}
+}
+
+func TestEmitSumMatch(t *testing.T) {
+	source := "Shape :: | Circle{number} | Square{number, number}\n"
+	source += "area :: (shape Shape) => number {\n"
+	source += "    match shape {\n"
+	source += "    case Circle(r):\n"
+	source += "        return r * r\n"
+	source += "    case Square(a, b):\n"
+	source += "        return a * b\n"
+	source += "    }\n"
+	source += "}\n"
+	source += "io.log(area)"
+	ast, errors := parser.Parse(strings.NewReader(source))
+	if len(errors) > 0 {
+		t.Fatalf("Expected no errors, got %#v", errors)
+	}
+	text := EmitProgram(ast)
+	expected := "    switch (_m._tag) {\n"
+	expected += "    case \"Circle\": {\n"
+	expected += "        let r = _m._value;\n"
+	expected += "        return r * r;\n"
+	expected += "        break;\n"
+	expected += "    }\n"
+	expected += "    case \"Square\": {\n"
+	expected += "        let [a, b] = _m._value;\n"
+	if !strings.Contains(text, expected) {
+		t.Fatalf("Expected string:\n%v\ngot:\n%v", expected, text)
+	}
+}
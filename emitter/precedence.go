@@ -10,6 +10,8 @@ func Precedence(expr parser.Expression) uint8 {
 		return 1
 	case *parser.BinaryExpression:
 		switch expr.Operator.Kind() {
+		case parser.Coalesce:
+			return 3
 		case parser.LogicalOr:
 			return 4
 		case parser.LogicalAnd:
@@ -27,7 +29,9 @@ func Precedence(expr parser.Expression) uint8 {
 		}
 	case *parser.UnaryExpression:
 		return 15
-	case *parser.CallExpression, *parser.PropertyAccessExpression:
+	case *parser.CallExpression,
+		*parser.ComputedAccessExpression,
+		*parser.PropertyAccessExpression:
 		return 18
 	case *parser.Identifier, *parser.Literal, *parser.ParenthesizedExpression:
 		return 20
@@ -0,0 +1,53 @@
+package emitter
+
+import "github.com/bmelicque/test-parser/parser"
+
+// Emit the declarations of the prelude used by the program.
+// Since their uses are found while emitting the program and the prelude
+// itself, declarations are emitted until no new one is used. They are
+// written in the prelude's order.
+func (e *Emitter) emitPrelude(prelude []parser.Node) string {
+	emitted := map[parser.Node]string{}
+	for found := true; found; {
+		found = false
+		for _, node := range prelude {
+			if _, ok := emitted[node]; ok || !e.isPreludeUsed(node) {
+				continue
+			}
+			e.builder.Reset()
+			e.emit(node)
+			emitted[node] = e.string()
+			found = true
+		}
+	}
+	e.builder.Reset()
+	for _, node := range prelude {
+		e.write(emitted[node])
+	}
+	return e.string()
+}
+
+// Check if a declaration of the prelude is used, either by name or, for
+// methods of builtin types, through their functions
+func (e *Emitter) isPreludeUsed(node parser.Node) bool {
+	a, ok := node.(*parser.Assignment)
+	if !ok {
+		return true
+	}
+	switch pattern := a.Pattern.(type) {
+	case *parser.Identifier:
+		return e.prelude[pattern.Text()]
+	case *parser.PropertyAccessExpression:
+		receiver := pattern.Expr.(*parser.ParenthesizedExpression).Expr.(*parser.Param)
+		complement, _ := parser.UnwrapMut(receiver.Complement)
+		name := getTypeIdentifier(complement)
+		if !builtinTypes[name] {
+			return true
+		}
+		method := pattern.Property.(*parser.Identifier).Text()
+		_, ok := e.helpers[builtinMethodBase(name, method)]
+		return ok
+	default:
+		return true
+	}
+}
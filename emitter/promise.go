@@ -0,0 +1,73 @@
+package emitter
+
+import (
+	"fmt"
+
+	"github.com/bmelicque/test-parser/parser"
+)
+
+// Promise combinators without JS equivalent, see the prelude
+var promiseHelpers = map[string]struct {
+	flag   EmitterFlag
+	source string
+}{
+	"any": {AnyFlag, `function %v(promises) {
+    const some = (p) => p.then((v) => v === undefined ? Promise.reject() : v);
+    return Promise.any(promises.map(some)).catch(() => undefined);
+}
+`},
+	"sleep": {SleepFlag, `function %v(ms) {
+    return new Promise((resolve) => setTimeout(resolve, ms));
+}
+`},
+	"timeout": {TimeoutFlag, `function %v(promise, ms) {
+    let id;
+    const timer = new Promise((resolve) => { id = setTimeout(resolve, ms) });
+    return Promise.race([promise, timer]).finally(() => clearTimeout(id));
+}
+`},
+}
+
+// Emit a call to a function implemented by a runtime helper, like
+// 'promise.sleep(100)' as '__sleep(100)'
+func (e *Emitter) emitHelperCall(expr *parser.CallExpression) {
+	name := expr.Callee.(*parser.PropertyAccessExpression).Property.(*parser.Identifier).Text()
+	e.addFlag(promiseHelpers[name].flag)
+	e.write(e.helper("__" + name))
+	e.write("(")
+	for i, arg := range expr.Args.Expr.(*parser.TupleExpression).Elements {
+		if i > 0 {
+			e.write(", ")
+		}
+		e.emitExpression(arg)
+	}
+	e.write(")")
+}
+
+// Emit the promise helpers used by the program
+func (e *Emitter) emitPromiseHelpers() {
+	for _, name := range []string{"any", "sleep", "timeout"} {
+		helper := promiseHelpers[name]
+		if e.hasFlag(helper.flag) {
+			e.write(fmt.Sprintf(helper.source, e.helper("__"+name)))
+		}
+	}
+}
+
+// Emit tuples of promises, like 'await (a, b)', as
+// 'await Promise.all([a, b])'.
+// Calls to async functions are not awaited one by one.
+func (e *Emitter) emitAwaitedTuple(tuple *parser.TupleExpression) {
+	e.write("await Promise.all([")
+	for i, element := range tuple.Elements {
+		if i > 0 {
+			e.write(", ")
+		}
+		if parser.IsAsyncCall(element) {
+			e.emitCallExpression(element.(*parser.CallExpression), false)
+		} else {
+			e.emitExpression(element)
+		}
+	}
+	e.write("])")
+}
@@ -0,0 +1,50 @@
+package emitter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bmelicque/test-parser/parser"
+)
+
+func TestEmitAwaitTuple(t *testing.T) {
+	source := "extern fetch :: async (number) -> string\n"
+	source += "p := async fetch(1)\n"
+	source += "a, b := await (p, fetch(2))"
+
+	expected := "let [a, b] = await Promise.all([p, fetch(2)]);\n"
+
+	testEmitter(t, source, expected, 2)
+}
+
+func TestEmitPromiseAll(t *testing.T) {
+	source := "extern fetch :: async (number) -> string\n"
+	source += "requests := []...string{async fetch(1), async fetch(2)}\n"
+	source += "await promise.all(requests)"
+
+	expected := "await Promise.all(requests);\n"
+
+	testEmitter(t, source, expected, 2)
+}
+
+func TestEmitPromiseHelpers(t *testing.T) {
+	source := "p := promise.sleep(10)\n"
+	source += "await promise.timeout(p, 100)"
+	ast, errors := parser.Parse(strings.NewReader(source))
+	if len(errors) > 0 {
+		t.Fatalf("Expected no errors, got %#v", errors)
+	}
+	text := EmitProgram(ast)
+	if !strings.Contains(text, "function __sleep(ms) {") {
+		t.Fatalf("Expected sleep helper, got:\n%v", text)
+	}
+	if !strings.Contains(text, "function __timeout(promise, ms) {") {
+		t.Fatalf("Expected timeout helper, got:\n%v", text)
+	}
+	if strings.Contains(text, "function __any(") {
+		t.Fatalf("Expected no unused helper, got:\n%v", text)
+	}
+	if !strings.HasSuffix(text, "let p = __sleep(10);\nawait __timeout(p, 100);\n") {
+		t.Fatalf("Expected helper calls, got:\n%v", text)
+	}
+}
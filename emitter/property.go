@@ -1,18 +1,66 @@
 package emitter
 
-import "github.com/bmelicque/test-parser/parser"
+import (
+	"fmt"
+
+	"github.com/bmelicque/test-parser/parser"
+)
 
 func (e *Emitter) emitPropertyAccessExpression(p *parser.PropertyAccessExpression) {
-	e.emitExpression(p.Expr)
-	if _, isRef := p.Expr.Type().(parser.Ref); isRef {
-		e.write("(1)")
+	// methods of builtin types used as values are bound to their receiver
+	if method, ok := e.getBuiltinMethod(p); ok && p.Optional {
+		o := e.fresh("o")
+		e.write(fmt.Sprintf("((%[1]v) => %[1]v === undefined ? undefined : %[2]v.bind(undefined, %[1]v))(", o, method))
+		e.emitDereferenced(p.Expr)
+		e.write(")")
+		return
+	} else if ok {
+		e.write(method + ".bind(undefined, ")
+		e.emitDereferenced(p.Expr)
+		e.write(")")
+		return
+	}
+	if extern := p.Extern(); extern != nil && extern.IsStatic() {
+		e.emitStaticMember(p, extern.Path())
+		return
 	}
-	if _, ok := p.Expr.Type().(parser.Tuple); ok {
+	e.emitDereferenced(p.Expr)
+	object := p.Expr.Type()
+	if p.Optional {
+		e.write("?.")
+		object = getOptionPayload(object)
+	}
+	if _, ok := object.(parser.Tuple); ok {
 		e.write("[")
 		e.emitExpression(p.Property)
 		e.write("]")
 	} else {
-		e.write(".")
-		e.emitExpression(p.Property)
+		if !p.Optional {
+			e.write(".")
+		}
+		if extern := p.Extern(); extern != nil {
+			e.write(extern.Path())
+		} else {
+			e.emitExpression(p.Property)
+		}
 	}
 }
+
+// Emit a member bound to a global, like 'Number.POSITIVE_INFINITY'.
+// The receiver is still evaluated, unless it is a variable.
+func (e *Emitter) emitStaticMember(p *parser.PropertyAccessExpression, path string) {
+	if p.Optional {
+		o := e.fresh("o")
+		e.write(fmt.Sprintf("((%[1]v) => %[1]v === undefined ? undefined : %[2]v)(", o, path))
+		e.emitExpression(p.Expr)
+		e.write(")")
+		return
+	}
+	if _, ok := p.Expr.(*parser.Identifier); ok {
+		e.write(path)
+		return
+	}
+	e.write("(")
+	e.emitExpression(p.Expr)
+	e.write(", " + path + ")")
+}
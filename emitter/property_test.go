@@ -18,7 +18,18 @@ func TestEmitIndirectPropertyAccess(t *testing.T) {
 	source += "ref := &x\n"
 	source += "ref.value"
 
-	expected := "ref(1).value;\n"
+	expected := "ref.v.value;\n"
 
 	testEmitter(t, source, expected, 3)
 }
+
+func TestEmitOptionalPropertyAccess(t *testing.T) {
+	source := "Type :: { value number }\n"
+	source += "f :: (x ?Type) => ?number { x?.value }"
+
+	expected := "const f = (x) => {\n"
+	expected += "    return x?.value;\n"
+	expected += "}\n"
+
+	testEmitter(t, source, expected, 1)
+}
@@ -0,0 +1,127 @@
+package emitter
+
+import (
+	"fmt"
+
+	"github.com/bmelicque/test-parser/parser"
+)
+
+// Ranges used as values are instances of the __Range helper.
+// Their elements are computed from the start and the step, so that the
+// emitted methods agree with loops over range literals.
+const rangeHelper = `class %[1]v {
+    constructor(start, end, step, inclusive) {
+        this.start = start;
+        this.end = end;
+        this.step = step;
+        this.inclusive = inclusive;
+    }
+    len() {
+        if (this.step === 0) return 0;
+        const n = (this.end - this.start) / this.step;
+        return Math.max(0, this.inclusive ? Math.floor(n) + 1 : Math.ceil(n));
+    }
+    at(i) { return this.start + i * this.step }
+    contains(x) {
+        const i = (x - this.start) / this.step;
+        return Number.isInteger(i) && i >= 0 && i < this.len();
+    }
+    reversed() {
+        return new %[1]v(this.at(this.len() - 1), this.start, -this.step, true);
+    }
+    *[Symbol.iterator]() {
+        for (let i = 0, len = this.len(); i < len; i++) yield this.at(i);
+    }
+    show() {
+        const step = this.step === 1 ? "" : " by " + this.step;
+        return this.start + (this.inclusive ? "..=" : "..") + this.end + step;
+    }
+}
+`
+
+// Emit a range value, like '0..10 by 2' as 'new __Range(0, 10, 2, false)'
+func (e *Emitter) emitRangeExpression(r *parser.RangeExpression) {
+	e.addFlag(RangeFlag)
+	e.write(fmt.Sprintf("new %v(", e.helper("__Range")))
+	emitRangeStart(e, r)
+	e.write(", ")
+	if r.Right != nil {
+		e.emitExpression(r.Right)
+	} else {
+		e.write("Infinity")
+	}
+	e.write(", ")
+	if r.Step != nil {
+		e.emitExpression(r.Step)
+	} else {
+		e.write("1")
+	}
+	e.write(fmt.Sprintf(", %v)", r.Operator.Kind() == parser.InclusiveRange))
+}
+
+func emitRangeStart(e *Emitter, r *parser.RangeExpression) {
+	if r.Left != nil {
+		e.emitExpression(r.Left)
+	} else {
+		e.write("0")
+	}
+}
+
+// Emit the condition of a loop over a range literal.
+// Steps whose sign is unknown are stored in the given variable: the loop
+// stops right away if it is 0, like range values are empty.
+// Unbound ranges have no condition.
+func emitRangeCondition(e *Emitter, r *parser.RangeExpression, counter parser.Expression, step string) {
+	if r.Right == nil {
+		return
+	}
+	less, greater := " < ", " > "
+	if r.Operator.Kind() == parser.InclusiveRange {
+		less, greater = " <= ", " >= "
+	}
+	switch {
+	case step != "":
+		e.write(fmt.Sprintf("%v > 0 ? ", step))
+		e.emitExpression(counter)
+		e.write(less)
+		e.emitExpression(r.Right)
+		e.write(fmt.Sprintf(" : %v < 0 && ", step))
+		e.emitExpression(counter)
+		e.write(greater)
+	case r.IsDescending():
+		e.emitExpression(counter)
+		e.write(greater)
+	default:
+		e.emitExpression(counter)
+		e.write(less)
+	}
+	e.emitExpression(r.Right)
+}
+
+func emitRangeIncrement(e *Emitter, r *parser.RangeExpression, counter parser.Expression, step string) {
+	e.emitExpression(counter)
+	switch {
+	case r.Step == nil:
+		e.write("++")
+	case step != "":
+		e.write(fmt.Sprintf(" += %v", step))
+	case r.IsDescending():
+		e.write(" -= ")
+		e.emitExpression(r.Step.(*parser.UnaryExpression).Operand)
+	default:
+		e.write(" += ")
+		e.emitExpression(r.Step)
+	}
+}
+
+// Declare the step of a range literal in a loop header, if its sign is only
+// known at runtime, and return its name
+func declareRangeStep(e *Emitter, r *parser.RangeExpression) string {
+	if r.HasStaticDirection() {
+		return ""
+	}
+	step := e.fresh("__step")
+	e.write(fmt.Sprintf(", %v = ", step))
+	e.emitExpression(r.Step)
+	return step
+}
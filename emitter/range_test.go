@@ -0,0 +1,38 @@
+package emitter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bmelicque/test-parser/parser"
+)
+
+func TestEmitRangeValue(t *testing.T) {
+	source := "r := 0..=10 by 2"
+	expected := "let r = new __Range(0, 10, 2, true);\n"
+	testEmitter(t, source, expected, 0)
+}
+
+func TestEmitOpenRangeValue(t *testing.T) {
+	source := "r := 1.."
+	expected := "let r = new __Range(1, Infinity, 1, false);\n"
+	testEmitter(t, source, expected, 0)
+}
+
+func TestEmitRangeMethods(t *testing.T) {
+	source := "r := 0..10\n"
+	source += "r.reversed().contains(r.len())"
+	expected := "r.reversed().contains(r.len());\n"
+	testEmitter(t, source, expected, 1)
+}
+
+func TestEmitRangeHelper(t *testing.T) {
+	ast, errors := parser.Parse(strings.NewReader("r := 0..10"))
+	if len(errors) > 0 {
+		t.Fatalf("Expected no errors, got %#v", errors)
+	}
+	text := EmitProgram(ast)
+	if !strings.Contains(text, "class __Range {") {
+		t.Fatalf("Expected range helper, got:\n%v", text)
+	}
+}
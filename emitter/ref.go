@@ -0,0 +1,106 @@
+package emitter
+
+import (
+	"fmt"
+
+	"github.com/bmelicque/test-parser/parser"
+)
+
+// References are objects holding their target in a 'v' property.
+//
+// Variables whose reference is taken are stored in cells, like '{ v: 42 }',
+// which are shared by the variable and all of its references: '&x' is the
+// cell itself, and reading or writing 'x' goes through 'x.v'.
+//
+// References to fields and elements are instances of the __FieldRef helper,
+// which forwards 'v' to the referenced key of the referenced object.
+// Elements are referenced at a checked index, like indexed lists.
+const fieldRefHelper = `class %v {
+    constructor(o, k) {
+        this.o = o;
+        this.k = k;
+    }
+    get v() { return this.o[this.k] }
+    set v(value) { this.o[this.k] = value }
+}
+`
+
+// Two references are equal if they are the same cell, or if they target the
+// same key of the same object
+const refEqualsHelper = `function %v(a, b) { return a === b || a.o !== undefined && a.o === b.o && a.k === b.k }
+`
+
+// Check if the variable behind an identifier is stored in a cell
+func isCell(i *parser.Identifier) bool {
+	v := i.Variable()
+	return v != nil && v.IsReferenced()
+}
+
+// Emit the declaration of an identifier with its initial value, like
+// 'x = value', wrapping the value in a cell if needed
+func (e *Emitter) emitBinding(i *parser.Identifier, emitValue func()) {
+	e.write(e.sanitize(i.Text()))
+	e.write(" = ")
+	if !isCell(i) {
+		emitValue()
+		return
+	}
+	e.write("{ v: ")
+	emitValue()
+	e.write(" }")
+}
+
+// Wrap the values of already declared identifiers in cells, if needed.
+// This is used for bindings that cannot be initialized with a cell, like
+// destructured values or function params.
+func (e *Emitter) emitCells(identifiers ...parser.Expression) {
+	for _, expr := range identifiers {
+		i, ok := expr.(*parser.Identifier)
+		if !ok || !isCell(i) {
+			continue
+		}
+		name := e.sanitize(i.Text())
+		e.indent()
+		e.write(fmt.Sprintf("%v = { v: %v };\n", name, name))
+	}
+}
+
+func (e *Emitter) emitReference(expr parser.Expression) {
+	if i, ok := expr.(*parser.Identifier); ok && isCell(i) {
+		e.write(e.sanitize(i.Text()))
+		return
+	}
+	switch expr := expr.(type) {
+	case *parser.PropertyAccessExpression:
+		e.addFlag(FieldRefFlag)
+		e.write(fmt.Sprintf("new %v(", e.helper("__FieldRef")))
+		e.emitDereferenced(expr.Expr)
+		e.write(", \"")
+		e.emitExpression(expr.Property)
+		e.write("\")")
+	case *parser.ComputedAccessExpression:
+		e.addFlag(FieldRefFlag)
+		e.addFlag(IndexFlag)
+		e.write(fmt.Sprintf("new %v(", e.helper("__FieldRef")))
+		e.emitDereferenced(expr.Expr)
+		e.write(fmt.Sprintf(", %v(", e.helper("__index")))
+		e.emitDereferenced(expr.Expr)
+		e.write(", ")
+		e.emitExpression(expr.Property.Expr)
+		e.write("))")
+	default:
+		// new values, or method receivers which cannot be stored in cells
+		e.write("{ v: ")
+		e.emitExpression(expr)
+		e.write(" }")
+	}
+}
+
+// Emit an expression, followed by '.v' if it is a reference, since
+// references are implicitly dereferenced when accessing their members
+func (e *Emitter) emitDereferenced(expr parser.Expression) {
+	e.emitExpression(expr)
+	if _, ok := expr.Type().(parser.Ref); ok {
+		e.write(".v")
+	}
+}
@@ -0,0 +1,193 @@
+package emitter
+
+import (
+	"fmt"
+
+	"github.com/bmelicque/test-parser/parser"
+)
+
+// Channels are instances of the __Chan helper.
+// Waiting senders and receivers are queued with a 'claim' function, which
+// tells if they still wait: the ones of a select expression stop waiting as
+// soon as one of its operations is taken.
+const chanHelper = `class %[1]v {
+    constructor(capacity) {
+        this.capacity = capacity;
+        this.buffer = [];
+        this.senders = [];
+        this.receivers = [];
+        this.closed = false;
+    }
+    static take(waiters) {
+        while (waiters.length > 0) {
+            const waiter = waiters.shift();
+            if (waiter.claim()) return waiter;
+        }
+    }
+    trySend(value) {
+        if (this.closed) throw new Error("send on closed channel");
+        const receiver = %[1]v.take(this.receivers);
+        if (receiver) {
+            receiver.resolve(value);
+            return true;
+        }
+        if (this.buffer.length < this.capacity) {
+            this.buffer.push(value);
+            return true;
+        }
+        return false;
+    }
+    tryReceive() {
+        const sender = %[1]v.take(this.senders);
+        if (this.buffer.length > 0) {
+            const value = this.buffer.shift();
+            if (sender) {
+                this.buffer.push(sender.value);
+                sender.resolve();
+            }
+            return { value };
+        }
+        if (sender) {
+            sender.resolve();
+            return { value: sender.value };
+        }
+        if (this.closed) return { value: undefined };
+    }
+    send(value) {
+        if (this.trySend(value)) return Promise.resolve();
+        return new Promise((resolve, reject) => {
+            this.senders.push({ value, resolve, reject, claim: () => true });
+        });
+    }
+    receive() {
+        const received = this.tryReceive();
+        if (received) return Promise.resolve(received.value);
+        return new Promise((resolve) => {
+            this.receivers.push({ resolve, claim: () => true });
+        });
+    }
+    close() {
+        this.closed = true;
+        for (const receiver of this.receivers.splice(0)) {
+            if (receiver.claim()) receiver.resolve(undefined);
+        }
+        for (const sender of this.senders.splice(0)) {
+            if (sender.claim()) sender.reject(new Error("send on closed channel"));
+        }
+    }
+}
+`
+
+// Select expressions resolve with the index of the operation taken, and the
+// received value if any.
+// Operations are written '[channel]' to receive, '[channel, value]' to send.
+// The index is -1 if no operation is ready and there is a default case.
+const selectHelper = `function %v(operations, fallback) {
+    for (const [i, [chan, ...sent]] of operations.entries()) {
+        if (sent.length > 0 && chan.trySend(sent[0])) return Promise.resolve([i]);
+        const received = sent.length === 0 && chan.tryReceive();
+        if (received) return Promise.resolve([i, received.value]);
+    }
+    if (fallback) return Promise.resolve([-1]);
+    return new Promise((resolve, reject) => {
+        let done = false;
+        const claim = () => !done && (done = true);
+        for (const [i, [chan, ...sent]] of operations.entries()) {
+            if (sent.length > 0) {
+                chan.senders.push({ value: sent[0], resolve: () => resolve([i]), reject, claim });
+            } else {
+                chan.receivers.push({ resolve: (value) => resolve([i, value]), claim });
+            }
+        }
+    });
+}
+`
+
+// Emit a channel, like 'Chan[number]{8}' as 'new __Chan(8)'
+func (e *Emitter) emitChanInstance(args *parser.TupleExpression) {
+	e.addFlag(ChanFlag)
+	e.write(fmt.Sprintf("new %v(", e.helper("__Chan")))
+	if len(args.Elements) > 0 {
+		e.emitExpression(args.Elements[0])
+	} else {
+		e.write("0")
+	}
+	e.write(")")
+}
+
+// Emit a select expression, like:
+//
+//	const __selected = await __select([[a], [b, value]], false);
+//	if (__selected[0] === 0) {
+//	    let x = __selected[1];
+//	    ...
+//	} else if (__selected[0] === 1) {
+//	    ...
+//	}
+//
+// Selects used as values assign their last expression to the given name.
+func (e *Emitter) emitSelect(s *parser.SelectExpression, name string) {
+	e.addFlag(ChanFlag | SelectFlag)
+	selected := e.fresh("__selected")
+	e.write(fmt.Sprintf("const %v = await %v([", selected, e.helper("__select")))
+	fallback := false
+	for i, c := range s.Cases {
+		if c.IsDefault() {
+			fallback = true
+			continue
+		}
+		if i > 0 {
+			e.write(", ")
+		}
+		emitSelectOperation(e, c.Call())
+	}
+	e.write(fmt.Sprintf("], %v);\n", fallback))
+
+	e.indent()
+	for i, c := range s.Cases {
+		if i > 0 {
+			e.write(" else ")
+		}
+		if !c.IsDefault() {
+			e.write(fmt.Sprintf("if (%v[0] === %v) ", selected, i))
+		}
+		emitSelectCase(e, c, selected, name)
+	}
+	e.write("\n")
+}
+
+// Emit an operation as '[channel]' or '[channel, value]'
+func emitSelectOperation(e *Emitter, call *parser.CallExpression) {
+	e.write("[")
+	e.emitExpression(call.Callee.(*parser.PropertyAccessExpression).Expr)
+	if parser.GetChannelOperation(call) == "send" {
+		e.write(", ")
+		e.emitExpression(call.Args.Expr.(*parser.TupleExpression).Elements[0])
+	}
+	e.write("]")
+}
+
+func emitSelectCase(e *Emitter, c parser.SelectCase, selected string, name string) {
+	e.write("{\n")
+	e.depth++
+	e.pushNames(nil)
+	if binding := c.Binding(); binding != nil {
+		e.indent()
+		e.write("let ")
+		e.emitBinding(binding.(*parser.Identifier), func() {
+			e.write(selected + "[1]")
+		})
+		e.write(";\n")
+	}
+	for i, statement := range c.Statements {
+		e.indent()
+		if name != "" && i == len(c.Statements)-1 && !parser.Exits(statement) {
+			e.write(fmt.Sprintf("%v = ", name))
+		}
+		e.emit(statement)
+	}
+	e.dropNames()
+	e.depth--
+	e.indent()
+	e.write("}")
+}
@@ -0,0 +1,85 @@
+package emitter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bmelicque/test-parser/parser"
+)
+
+func TestEmitChanInstance(t *testing.T) {
+	testEmitter(t, "c := Chan[number]{}", "let c = new __Chan(0);\n", 0)
+	testEmitter(t, "c := Chan[number]{8}", "let c = new __Chan(8);\n", 0)
+}
+
+func TestEmitSelect(t *testing.T) {
+	source := "a := Chan[number]{}\n"
+	source += "b := Chan[string]{}\n"
+	source += "select {\n"
+	source += "case x := a.receive():\n"
+	source += "    io.log(x)\n"
+	source += "case b.send(\"hi\"):\n"
+	source += "    io.log(1)\n"
+	source += "}"
+
+	expected := "const __selected = await __select([[a], [b, \"hi\"]], false);\n"
+	expected += "if (__selected[0] === 0) {\n"
+	expected += "    let x = __selected[1];\n"
+	expected += "    console.log(x);\n"
+	expected += "} else if (__selected[0] === 1) {\n"
+	expected += "    console.log(1);\n"
+	expected += "}\n"
+
+	testEmitter(t, source, expected, 2)
+}
+
+func TestEmitSelectValue(t *testing.T) {
+	source := "a := Chan[number]{}\n"
+	source += "n := select {\n"
+	source += "case x := a.receive():\n"
+	source += "    x ?? 0\n"
+	source += "case _:\n"
+	source += "    -1\n"
+	source += "}"
+
+	expected := "let _tmp0;\n"
+	expected += "const __selected = await __select([[a]], true);\n"
+	expected += "if (__selected[0] === 0) {\n"
+	expected += "    let x = __selected[1];\n"
+	expected += "    _tmp0 = x ?? 0;\n"
+	expected += "} else {\n"
+	expected += "    _tmp0 = -1;\n"
+	expected += "}\n"
+	expected += "let n = _tmp0;\n"
+
+	testEmitter(t, source, expected, 1)
+}
+
+func TestEmitChanHelpers(t *testing.T) {
+	source := "a := Chan[number]{}\n"
+	source += "a.send(1)"
+	ast, errors := parser.Parse(strings.NewReader(source))
+	if len(errors) > 0 {
+		t.Fatalf("Expected no errors, got %#v", errors)
+	}
+	text := EmitProgram(ast)
+	if !strings.Contains(text, "class __Chan {") {
+		t.Fatalf("Expected channel helper, got:\n%v", text)
+	}
+	if strings.Contains(text, "function __select(") {
+		t.Fatalf("Expected no unused select helper, got:\n%v", text)
+	}
+	if !strings.HasSuffix(text, "let a = new __Chan(0);\nawait a.send(1);\n") {
+		t.Fatalf("Expected channel operations, got:\n%v", text)
+	}
+}
+
+func TestEmitChanNotCopied(t *testing.T) {
+	source := "Worker :: {\n"
+	source += "    jobs Chan[number]\n"
+	source += "}\n"
+	source += "w := Worker{jobs: Chan[number]{}}\n"
+	source += "jobs := w.jobs"
+
+	testEmitter(t, source, "let jobs = w.jobs;\n", 2)
+}
@@ -0,0 +1,111 @@
+// Micro-benchmarks of the JS emitted for references.
+// Run with: node emitter/testdata/refs_bench.mjs
+//
+// 'closure' is the former representation, where references were closures
+// called with an access mode: 0 to write, 1 to read, 2 for the key and 4 for
+// the owner, used to compare references.
+// 'cell' is the current one, where referenced variables are stored in cells
+// and references to fields are instances of __FieldRef.
+
+const N = 10_000_000;
+
+const __s = Symbol();
+function closureRefEquals(a, b) { return a(4) == b(4) && a(2) == b(2) }
+
+class __FieldRef {
+    constructor(o, k) {
+        this.o = o;
+        this.k = k;
+    }
+    get v() { return this.o[this.k] }
+    set v(value) { this.o[this.k] = value }
+}
+function cellRefEquals(a, b) { return a === b || a.o !== undefined && a.o === b.o && a.k === b.k }
+
+const benchmarks = {
+    "increment through a variable reference": {
+        closure() {
+            const incr = (r) => { r(0, r(1) + 1) };
+            let count = 0;
+            const ref = (a,p)=>(a&4?__s:a&2?"count":a?count:void (count=p));
+            for (let i = 0; i < N; i++) incr(ref);
+            return count;
+        },
+        cell() {
+            const incr = (r) => { r.v = r.v + 1 };
+            let count = { v: 0 };
+            const ref = count;
+            for (let i = 0; i < N; i++) incr(ref);
+            return count.v;
+        },
+    },
+    "increment through a field reference": {
+        closure() {
+            const incr = (r) => { r(0, r(1) + 1) };
+            const point = { x: 0, y: 0 };
+            const ref = ((o,k)=>(a,p)=>(a&4?o:a&2?k:a?o[k]:void (o[k]=p)))(point,"x");
+            for (let i = 0; i < N; i++) incr(ref);
+            return point.x;
+        },
+        cell() {
+            const incr = (r) => { r.v = r.v + 1 };
+            const point = { x: 0, y: 0 };
+            const ref = new __FieldRef(point, "x");
+            for (let i = 0; i < N; i++) incr(ref);
+            return point.x;
+        },
+    },
+    "take a variable reference": {
+        closure() {
+            let count = 0;
+            let sum = 0;
+            for (let i = 0; i < N; i++) {
+                const ref = (a,p)=>(a&4?__s:a&2?"count":a?count:void (count=p));
+                sum += ref(1);
+            }
+            return sum;
+        },
+        cell() {
+            let count = { v: 0 };
+            let sum = 0;
+            for (let i = 0; i < N; i++) {
+                const ref = count;
+                sum += ref.v;
+            }
+            return sum;
+        },
+    },
+    "compare references": {
+        closure() {
+            let count = 0;
+            const a = (a,p)=>(a&4?__s:a&2?"count":a?count:void (count=p));
+            const b = (a,p)=>(a&4?__s:a&2?"count":a?count:void (count=p));
+            let equal = 0;
+            for (let i = 0; i < N; i++) if (closureRefEquals(a, b)) equal++;
+            return equal;
+        },
+        cell() {
+            let count = { v: 0 };
+            const a = count;
+            const b = count;
+            let equal = 0;
+            for (let i = 0; i < N; i++) if (cellRefEquals(a, b)) equal++;
+            return equal;
+        },
+    },
+};
+
+function measure(f) {
+    f(); // warm up
+    const start = performance.now();
+    f();
+    return (performance.now() - start) * 1e6 / N;
+}
+
+for (const [name, { closure, cell }] of Object.entries(benchmarks)) {
+    const before = measure(closure);
+    const after = measure(cell);
+    console.log(`${name}:`);
+    console.log(`    closure ${before.toFixed(2)} ns/op`);
+    console.log(`    cell    ${after.toFixed(2)} ns/op (x${(before / after).toFixed(1)})`);
+}
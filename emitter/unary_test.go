@@ -8,17 +8,104 @@ func TestEmitReference(t *testing.T) {
 	source := "value := 0\n"
 	source += "&value"
 
-	expected := "(a,p)=>(a&4?__s:a&2?\"value\":a?value:void (value=p));\n"
+	testEmitter(t, source, "let value = { v: 0 };\n", 0)
+	testEmitter(t, source, "value;\n", 1)
+}
+
+func TestEmitFieldReference(t *testing.T) {
+	source := "Type :: { value number }\n"
+	source += "x := Type{ value: 42 }\n"
+	source += "&x.value"
+
+	testEmitter(t, source, "let x = new Type(42);\n", 1)
+	testEmitter(t, source, "new __FieldRef(x, \"value\");\n", 2)
+}
+
+func TestEmitElementReference(t *testing.T) {
+	source := "list := []number{1, 2}\n"
+	source += "&list[1]"
+
+	testEmitter(t, source, "new __FieldRef(list, __index(list, 1));\n", 1)
+}
+
+func TestEmitNewValueReference(t *testing.T) {
+	source := "Type :: { value number }\n"
+	source += "r := &mut Type{ value: 42 }"
+
+	testEmitter(t, source, "let r = { v: new Type(42) };\n", 1)
+}
+
+func TestEmitReferencedParam(t *testing.T) {
+	source := "incr :: (r &mut number) => { *r = *r + 1 }\n"
+	source += "f :: (n number) => number {\n"
+	source += "    incr(&mut n)\n"
+	source += "    n\n"
+	source += "}"
+
+	expected := "const f = (n) => {\n"
+	expected += "    n = { v: n };\n"
+	expected += "    incr(n);\n"
+	expected += "    return n.v;\n"
+	expected += "}\n"
 
 	testEmitter(t, source, expected, 1)
 }
 
+func TestEmitReferenceType(t *testing.T) {
+	source := "Type :: { value number }\n"
+	source += "get :: (r &Type) => number { r.value }\n"
+	source += "x := Type{ value: 42 }"
+
+	testEmitter(t, source, "let x = new Type(42);\n", 2)
+}
+
 func TestEmitDeref(t *testing.T) {
 	source := "value := 0\n"
 	source += "ref := &value\n"
 	source += "*ref"
 
-	expected := "ref(1);\n"
+	expected := "ref.v;\n"
 
 	testEmitter(t, source, expected, 2)
 }
+
+func TestEmitOptionPropagation(t *testing.T) {
+	source := "f :: (x ?number) => ?number {\n"
+	source += "    y := try x\n"
+	source += "    y + 1\n"
+	source += "}"
+
+	expected := "const f = (x) => {\n"
+	expected += "    let _tmp0;\n"
+	expected += "    if ((_tmp0 = x) === undefined) return;\n"
+	expected += "    let y = _tmp0;\n"
+	expected += "    return y + 1;\n"
+	expected += "}\n"
+
+	testEmitter(t, source, expected, 0)
+}
+
+func TestEmitResultPropagation(t *testing.T) {
+	source := "f :: (x string!number) => string!number {\n"
+	source += "    y := try x\n"
+	source += "    y + 1\n"
+	source += "}"
+
+	expected := "const f = (x) => {\n"
+	expected += "    let _tmp0;\n"
+	expected += "    if ((_tmp0 = x)._tag === \"Err\") return _tmp0;\n"
+	expected += "    let y = _tmp0._value;\n"
+	expected += "    return new _Sum(\"Ok\", y + 1);\n"
+	expected += "}\n"
+
+	testEmitter(t, source, expected, 0)
+}
+
+func TestEmitNegation(t *testing.T) {
+	source := "a := 2\n"
+	source += "-a ** 2 + - -a"
+
+	expected := "(-a) ** 2 + - -a;\n"
+
+	testEmitter(t, source, expected, 1)
+}
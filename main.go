@@ -7,6 +7,7 @@ import (
 
 	"github.com/bmelicque/test-parser/emitter"
 	"github.com/bmelicque/test-parser/parser"
+	"github.com/bmelicque/test-parser/schema"
 )
 
 type TokenKind int
@@ -20,9 +21,22 @@ const (
 	AssignmentOperator
 )
 
+// Usage:
+//
+//	test-parser <source> <output>         compile a program to JS
+//	test-parser schema <source> <output>  export its types as JSON Schema
 func main() {
-	source := os.Args[1]
-	output := os.Args[2]
+	generate := emitter.EmitProgram
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "schema" {
+		generate = schema.Export
+		args = args[1:]
+	}
+	if len(args) != 2 {
+		log.Fatal("usage: test-parser [schema] <source> <output>")
+	}
+	source := args[0]
+	output := args[1]
 
 	file, err := os.Open(source)
 	if err != nil {
@@ -38,7 +52,7 @@ func main() {
 		}
 		defer f.Close()
 
-		_, err = f.WriteString(emitter.EmitProgram(ast))
+		_, err = f.WriteString(generate(ast))
 		if err != nil {
 			log.Fatal(err)
 		}
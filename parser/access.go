@@ -4,7 +4,7 @@ import (
 	"slices"
 )
 
-var operators = []TokenKind{LeftBracket, LeftParenthesis, Dot}
+var operators = []TokenKind{LeftBracket, LeftParenthesis, Dot, QuestionDot}
 
 func (p *Parser) parseAccessExpression() Expression {
 	expression := fallback(p)
@@ -13,6 +13,12 @@ func (p *Parser) parseAccessExpression() Expression {
 		if next == LeftParenthesis && !p.allowCallExpr {
 			return expression
 		}
+		// in params like 'list []number' or 'f (number) -> number',
+		// brackets and parentheses are part of the type
+		isAdjacent := expression == nil || p.Peek().Loc().Start == expression.Loc().End
+		if next != Dot && next != QuestionDot && !isAdjacent {
+			return expression
+		}
 		expression = parseOneAccess(p, expression)
 	}
 	return expression
@@ -27,6 +33,8 @@ func parseOneAccess(p *Parser, expr Expression) Expression {
 		return parseCallExpression(p, expr)
 	case Dot:
 		return parsePropertyAccess(p, expr)
+	case QuestionDot:
+		return parseOptionalPropertyAccess(p, expr)
 	default:
 		panic("switch should've been exhaustive!")
 	}
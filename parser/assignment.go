@@ -152,6 +152,9 @@ func reportDuplicatedFields(p *Parser, b *Block) {
 		case *Entry:
 			identifier = s.Key.(*Identifier)
 		}
+		if identifier == nil {
+			continue
+		}
 		name := identifier.Text()
 		if name != "" {
 			declarations[name] = append(declarations[name], identifier)
@@ -189,8 +192,14 @@ func typeCheckAssignment(p *Parser, a *Assignment) {
 	p.writing = a
 	a.Pattern.typeCheck(p)
 	p.writing = outer
+	readonly := reportReadOnlyWrite(p, a.Pattern, a.Pattern)
 	a.Value.typeCheck(p)
 	reportInvalidVariableType(p, a.Value)
+	reportEscapingPromise(p, a)
+	reportEscapingAssignment(p, a)
+	if readonly {
+		return
+	}
 
 	switch pattern := a.Pattern.(type) {
 	case *Identifier:
@@ -214,6 +223,19 @@ func typeCheckAssignment(p *Parser, a *Assignment) {
 			return
 		}
 		p.error(pattern, CannotAssignType, t.To, a.Value.Type())
+	case *ComputedAccessExpression:
+		if _, ok := pattern.Property.Expr.(*RangeExpression); ok {
+			p.error(pattern, InvalidPattern)
+			return
+		}
+		// strings are immutable
+		if _, ok := deref(pattern.Expr.Type()).(String); ok {
+			p.error(pattern, InvalidPattern)
+			return
+		}
+		if !pattern.typing.Extends(a.Value.Type()) {
+			p.error(a.Value, CannotAssignType, pattern.typing, a.Value.Type())
+		}
 	default:
 		p.error(a.Pattern, InvalidPattern)
 	}
@@ -226,7 +248,7 @@ func readDeref(p *Parser, pattern *UnaryExpression) {
 	}
 	v, ok := p.scope.Find(identifier.Text())
 	if ok {
-		v.reads = append(v.reads, pattern.Loc())
+		v.readAt(pattern.Loc())
 	}
 }
 
@@ -235,6 +257,7 @@ func typeCheckOtherAssignment(p *Parser, a *Assignment) {
 	p.writing = a
 	a.Pattern.typeCheck(p)
 	p.writing = outer
+	reportReadOnlyWrite(p, a.Pattern, a.Pattern)
 	a.Value.typeCheck(p)
 
 	left := a.Pattern.Type()
@@ -274,21 +297,37 @@ func typeCheckOtherAssignment(p *Parser, a *Assignment) {
 // type check assignment where operator is ':='
 func typeCheckDeclaration(p *Parser, a *Assignment) {
 	a.Value.typeCheck(p)
+	if isConstructorPattern(a.Pattern) {
+		if !p.conditionalDeclaration {
+			p.error(a.Pattern, InvalidPattern)
+			return
+		}
+		p.typeCheckPattern(a.Pattern, a.Value.Type())
+		return
+	}
 	reportInvalidVariableType(p, a.Value)
 	switch pattern := a.Pattern.(type) {
 	case *Identifier:
 		declareIdentifier(p, pattern, a.Value.Type())
 	case *TupleExpression:
 		declareTuple(p, pattern, a.Value.Type())
-	case *CallExpression:
-		if !p.conditionalDeclaration {
-			p.error(a.Pattern, InvalidPattern)
-			return
-		}
-		p.typeCheckPattern(a.Pattern, a.Value.Type())
 	default:
 		p.error(a.Pattern, InvalidPattern)
 	}
+	bindRefTargets(a.Pattern, a.Value)
+}
+
+// Check if the pattern of a declaration is a constructor,
+// like in 'if Some(s) := option {}' or 'if None := option {}'
+func isConstructorPattern(pattern Expression) bool {
+	switch pattern := pattern.(type) {
+	case *Identifier:
+		return pattern.IsType()
+	case *CallExpression:
+		return true
+	default:
+		return false
+	}
 }
 
 func declareIdentifier(p *Parser, identifier *Identifier, typing ExpressionType) {
@@ -296,11 +335,19 @@ func declareIdentifier(p *Parser, identifier *Identifier, typing ExpressionType)
 	if name == "" || name == "_" {
 		return
 	}
-	if name == "Map" {
+	if name == "Map" || name == "Set" {
 		p.error(identifier, ReservedName, name)
 		return
 	}
-	p.scope.Add(name, identifier.Loc(), typing)
+	p.scope.bind(identifier, identifier.Loc(), typing)
+}
+
+// Declare a variable that cannot be modified, like definitions with '::'
+func defineIdentifier(p *Parser, identifier *Identifier, typing ExpressionType) {
+	declareIdentifier(p, identifier, typing)
+	if identifier.variable != nil {
+		identifier.variable.readonly = true
+	}
 }
 
 func declareTuple(p *Parser, pattern *TupleExpression, typing ExpressionType) {
@@ -332,7 +379,7 @@ func typeCheckDefinition(p *Parser, a *Assignment) {
 		if a.Value == nil {
 			return
 		}
-		a.Value.typeCheck(p)
+		typeCheckDefinedValue(p, a.Value)
 		reportInvalidVariableType(p, a.Value)
 		if pattern.IsType() {
 			typeCheckTypeDefinition(p, a)
@@ -348,12 +395,22 @@ func typeCheckDefinition(p *Parser, a *Assignment) {
 	}
 }
 
+// Type check the value of a definition.
+// Blocks define object types, their statements are fields.
+func typeCheckDefinedValue(p *Parser, value Expression) {
+	if b, ok := value.(*Block); ok {
+		typeCheckObjectDefinition(p, b)
+	} else {
+		value.typeCheck(p)
+	}
+}
+
 func typeCheckGenericTypeDefinition(p *Parser, a *Assignment) {
 	pattern := a.Pattern.(*ComputedAccessExpression)
 
 	p.pushScope(NewScope(ProgramScope))
 	typeCheckTypeParams(p, pattern.Property)
-	a.Value.typeCheck(p)
+	typeCheckDefinedValue(p, a.Value)
 	p.dropScope()
 
 	identifier, ok := pattern.Expr.(*Identifier)
@@ -366,16 +423,25 @@ func typeCheckGenericTypeDefinition(p *Parser, a *Assignment) {
 		Params: pattern.Property.getGenerics(),
 		Ref:    getInitType(p, a.Value),
 	}}
+	identifier.typing = t
 	p.scope.Add(identifier.Text(), pattern.Loc(), t)
 }
 
 func typeCheckTypeDefinition(p *Parser, a *Assignment) {
 	identifier := a.Pattern.(*Identifier)
+	ref := getInitType(p, a.Value)
+	// values of a trait type are values of any type implementing it
+	if trait, ok := ref.(Trait); ok {
+		identifier.typing = Type{trait}
+		defineIdentifier(p, identifier, identifier.typing)
+		return
+	}
 	t := Type{TypeAlias{
 		Name: identifier.Text(),
-		Ref:  getInitType(p, a.Value),
+		Ref:  ref,
 	}}
-	declareIdentifier(p, identifier, t)
+	identifier.typing = t
+	defineIdentifier(p, identifier, t)
 }
 
 func getInitType(p *Parser, expr Expression) ExpressionType {
@@ -427,14 +493,14 @@ func typeCheckFunctionDefinition(p *Parser, a *Assignment) {
 		p.error(a.Value, FunctionExpressionExpected)
 		return
 	}
-	declareIdentifier(p, identifier, t)
+	defineIdentifier(p, identifier, t)
 }
 
 func typeCheckMethod(p *Parser, expr *PropertyAccessExpression, init Expression) {
 	p.pushScope(NewScope(ProgramScope))
 	defer p.dropScope()
 
-	typeIdentifier := declareMethodReceiver(p, expr.Expr)
+	typeIdentifier, mutates := declareMethodReceiver(p, expr.Expr)
 
 	method, ok := expr.Property.(*Identifier)
 	if !ok {
@@ -443,10 +509,12 @@ func typeCheckMethod(p *Parser, expr *PropertyAccessExpression, init Expression)
 
 	init.typeCheck(p)
 
-	if _, ok := init.Type().(Function); !ok {
+	function, isFunction := init.Type().(Function)
+	if !isFunction {
 		p.error(init, FunctionExpressionExpected)
 		return
 	}
+	function.Mutates = mutates
 	if !ok || typeIdentifier == nil {
 		return
 	}
@@ -460,36 +528,94 @@ func typeCheckMethod(p *Parser, expr *PropertyAccessExpression, init Expression)
 		return
 	}
 
-	p.scope.AddMethod(method.Text(), alias, init.Type().(Function))
+	p.scope.AddMethod(method.Text(), alias, function)
 }
 
-func declareMethodReceiver(p *Parser, receiver Expression) *Identifier {
+// Declare the receiver of a method, like 'p' in '(p Point).method'.
+// Receivers are read-only, unless declared with 'mut', like in
+// '(p mut Point).method'. Return the receiver's type identifier, and whether
+// the receiver can be modified.
+func declareMethodReceiver(p *Parser, receiver Expression) (*Identifier, bool) {
 	paren, ok := receiver.(*ParenthesizedExpression)
 	if !ok || paren.Expr == nil {
 		p.error(receiver, ReceiverExpected)
-		return nil
+		return nil, false
 	}
 	param, ok := paren.Expr.(*Param)
 	if !ok {
 		p.error(paren.Expr, ReceiverExpected)
-		return nil
+		return nil, false
 	}
 
-	typeIdentifier, ok := param.Complement.(*Identifier)
+	complement, mutable := UnwrapMut(param.Complement)
+	typeIdentifier, ok := complement.(*Identifier)
 	if !ok || !typeIdentifier.IsType() {
 		p.error(param.Complement, TypeIdentifierExpected)
-		return nil
+		return nil, false
 	}
 	typeIdentifier.typeCheck(p)
 
-	if t, ok := typeIdentifier.Type().(Type); ok {
-		p.scope.Add(
-			param.Identifier.Text(),
-			param.Identifier.Loc(),
-			t.Value,
-		)
+	t, ok := typeIdentifier.Type().(Type)
+	if !ok {
+		return typeIdentifier, mutable
+	}
+	typing := t.Value
+	if alias, ok := t.Value.(TypeAlias); ok {
+		params := addReceiverTypeParams(p, alias)
+		typing = getReceiverType(alias, params)
+	}
+	p.scope.bind(param.Identifier, param.Identifier.Loc(), typing)
+	if param.Identifier.variable != nil {
+		param.Identifier.variable.readonly = !mutable
+		param.Identifier.variable.receiver = true
+	}
+	return typeIdentifier, mutable
+}
+
+// Unwrap the type of a receiver declared with 'mut', like in
+// '(p mut Point).method'. Also return whether 'mut' was used.
+func UnwrapMut(expr Expression) (Expression, bool) {
+	unary, ok := expr.(*UnaryExpression)
+	if !ok || unary.Operator.Kind() != MutKeyword {
+		return expr, false
+	}
+	return unary.Operand, true
+}
+
+// Get the type of a receiver, with its type's params replaced by given types
+func getReceiverType(alias TypeAlias, params []ExpressionType) ExpressionType {
+	// list, range and string values are not aliased
+	switch alias.Name {
+	case "List":
+		return List{params[0]}
+	case "Range":
+		return Range{params[0]}
+	case "String":
+		return String{}
+	}
+	if len(params) == 0 {
+		return alias
+	}
+	scope := NewScope(ProgramScope)
+	generics := make([]Generic, len(params))
+	for i, param := range alias.Params {
+		scope.Add(param.Name, Loc{}, params[i])
+		generics[i] = Generic{Name: param.Name, Value: params[i]}
+	}
+	ref, _ := alias.Ref.build(scope, nil)
+	return TypeAlias{Name: alias.Name, Params: generics, Ref: ref}
+}
+
+// Declare the type params of a receiver's type, like 'Type' for lists.
+// Like a function's type params, they are opaque types.
+func addReceiverTypeParams(p *Parser, alias TypeAlias) []ExpressionType {
+	params := make([]ExpressionType, len(alias.Params))
+	for i, param := range alias.Params {
+		t := TypeAlias{Name: param.Name, Ref: Generic{Name: param.Name}}
+		p.scope.Add(param.Name, Loc{}, Type{t})
+		params[i] = t
 	}
-	return typeIdentifier
+	return params
 }
 
 func reportInvalidVariableType(p *Parser, value Expression) {
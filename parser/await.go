@@ -0,0 +1,77 @@
+package parser
+
+// Awaiting a tuple awaits all of its elements concurrently:
+//
+//	user, posts := await (async getUser(id), async getPosts(id))
+//
+// Calls to async functions in the tuple are started without being awaited
+// first, so 'async' can be left out:
+//
+//	user, posts := await (getUser(id), getPosts(id))
+//
+// The result is a tuple of the awaited values, in the same order.
+// Lists of promises are awaited with 'promise.all', see the prelude.
+
+func typeCheckAwait(p *Parser, u *UnaryExpression) {
+	tuple, ok := Unwrap(u.Operand).(*TupleExpression)
+	if !ok {
+		if getPromisePayload(u.Operand.Type()) == nil {
+			p.error(u.Operand, PromiseExpected, u.Operand.Type())
+		}
+		return
+	}
+	for _, element := range tuple.Elements {
+		if getAwaitedType(element) == nil {
+			p.error(element, PromiseExpected, element.Type())
+		}
+	}
+}
+
+func getAwaitType(u *UnaryExpression) ExpressionType {
+	tuple, ok := Unwrap(u.Operand).(*TupleExpression)
+	if !ok {
+		if t := getPromisePayload(u.Operand.Type()); t != nil {
+			return t
+		}
+		return Unknown{}
+	}
+	elements := make([]ExpressionType, len(tuple.Elements))
+	for i, element := range tuple.Elements {
+		elements[i] = getAwaitedType(element)
+		if elements[i] == nil {
+			elements[i] = Unknown{}
+		}
+	}
+	return Tuple{elements}
+}
+
+// Get the type of an element of an awaited tuple, once awaited, or nil if the
+// element cannot be awaited
+func getAwaitedType(element Expression) ExpressionType {
+	if IsAsyncCall(element) {
+		return element.Type()
+	}
+	return getPromisePayload(element.Type())
+}
+
+// Check if an expression is a call to an async function, which is implicitly
+// awaited
+func IsAsyncCall(expr Expression) bool {
+	call, ok := expr.(*CallExpression)
+	if !ok || call.Callee == nil {
+		return false
+	}
+	f, ok := call.CalledType().(Function)
+	return ok && f.Async
+}
+
+// Get the type of the value a promise resolves to, or nil if t is not a
+// promise
+func getPromisePayload(t ExpressionType) ExpressionType {
+	alias, ok := t.(TypeAlias)
+	if !ok || alias.Name != "..." {
+		return nil
+	}
+	payload, _ := alias.Params[0].Value.build(nil, nil)
+	return payload
+}
@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckAwaitTuple(t *testing.T) {
+	source := "extern fetch :: async (number) -> string\n"
+	source += "p := async fetch(1)\n"
+	source += "both := await (p, fetch(2))"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	typing := statements[2].(*Assignment).Value.Type()
+	if text := typing.Text(); text != "(string, string)" {
+		t.Fatalf("Expected (string, string), got %v", text)
+	}
+}
+
+func TestCheckAwaitTupleNotPromise(t *testing.T) {
+	source := "extern fetch :: async (number) -> string\n"
+	source += "await (fetch(1), 2)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, PromiseExpected)
+}
+
+func TestCheckPromiseType(t *testing.T) {
+	source := "f :: (p ...number) => number {\n"
+	source += "    await p\n"
+	source += "}"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	f := statements[0].(*Assignment).Value.Type().(Function)
+	if !f.Async {
+		t.Fatal("Expected function awaiting a promise to be async")
+	}
+	if text := f.Params.Elements[0].Text(); text != "...number" {
+		t.Fatalf("Expected ...number, got %v", text)
+	}
+}
+
+func TestCheckPromiseAll(t *testing.T) {
+	source := "extern fetch :: async (number) -> string\n"
+	source += "requests := []...string{async fetch(1), async fetch(2)}\n"
+	source += "all := promise.all(requests)\n"
+	source += "first := promise.timeout(promise.race(requests), 100)"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	if text := statements[2].(*Assignment).Value.Type().Text(); text != "...[]string" {
+		t.Fatalf("Expected ...[]string, got %v", text)
+	}
+	if text := statements[3].(*Assignment).Value.Type().Text(); text != "...?[string]" {
+		t.Fatalf("Expected ...?[string], got %v", text)
+	}
+}
@@ -77,6 +77,11 @@ func (expr *BinaryExpression) Type() ExpressionType {
 		return Type{makeResultType(right, left)}
 	case InKeyword:
 		return Nil{}
+	case Coalesce:
+		if expr.Right == nil {
+			return Unknown{}
+		}
+		return expr.Right.Type()
 	default:
 		panic(fmt.Sprintf("operator '%v' not implemented", expr.Operator.Kind()))
 	}
@@ -100,7 +105,10 @@ func parseBinary(p *Parser, operators []TokenKind, fallback func(p *Parser) Expr
 	return expression
 }
 func parseBinaryErrorType(p *Parser) Expression {
-	return parseBinary(p, []TokenKind{Bang}, parseLogicalOr)
+	return parseBinary(p, []TokenKind{Bang}, parseCoalescing)
+}
+func parseCoalescing(p *Parser) Expression {
+	return parseBinary(p, []TokenKind{Coalesce}, parseLogicalOr)
 }
 func parseLogicalOr(p *Parser) Expression {
 	return parseBinary(p, []TokenKind{LogicalOr}, parseLogicalAnd)
@@ -160,6 +168,8 @@ func (b *BinaryExpression) typeCheck(p *Parser) {
 		p.typeCheckComparisonExpression(b.Left, b.Right)
 	case Bang:
 		typeCheckBinaryErrorType(p, b.Left, b.Right)
+	case Coalesce:
+		p.typeCheckCoalescingExpression(b.Left, b.Right)
 	default:
 		panic(fmt.Sprintf("operator '%v' not implemented", b.Operator.Kind()))
 	}
@@ -222,6 +232,23 @@ func (p *Parser) typeCheckArithmeticExpression(left Expression, right Expression
 		p.error(right, NumberExpected, right.Type())
 	}
 }
+
+// Type check 'option ?? fallback'.
+// The fallback can be either a value of the option's payload type,
+// or another option to chain defaults.
+func (p *Parser) typeCheckCoalescingExpression(left Expression, right Expression) {
+	if left == nil || right == nil {
+		return
+	}
+	payload := getOptionPayload(left.Type())
+	if payload == nil {
+		p.error(left, OptionExpected, left.Type())
+		return
+	}
+	if !payload.Extends(right.Type()) && !left.Type().Extends(right.Type()) {
+		p.error(right, CannotAssignType, payload, right.Type())
+	}
+}
 func typeCheckBinaryErrorType(p *Parser, left Expression, right Expression) {
 	if _, ok := left.Type().(Type); !ok {
 		p.error(left, TypeExpected)
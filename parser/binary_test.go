@@ -199,3 +199,21 @@ func TestCheckBinaryErrorType(t *testing.T) {
 		t.Fatalf("Number expected")
 	}
 }
+
+func TestCoalescing(t *testing.T) {
+	source := "f :: (option ?number) => number {\n"
+	source += "    option ?? 0\n"
+	source += "}\n"
+	source += "io.log(f)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+}
+
+func TestCoalescingWrongFallback(t *testing.T) {
+	source := "f :: (option ?number) => string {\n"
+	source += "    option ?? \"none\"\n"
+	source += "}\n"
+	source += "io.log(f)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, CannotAssignType)
+}
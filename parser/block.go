@@ -17,7 +17,7 @@ func (b *Block) getChildren() []Node {
 func (b *Block) typeCheck(p *Parser) {
 	b.scope = p.scope
 	for i := range b.Statements {
-		b.Statements[i].typeCheck(p)
+		typeCheckStatement(p, b.Statements[i])
 	}
 	if len(b.Statements) == 0 {
 		return
@@ -31,6 +31,37 @@ func (b *Block) typeCheck(p *Parser) {
 	}
 }
 
+// Type check a statement of a block or of a match case.
+// Params used as statements declare variables without initial value,
+// like 'x number'.
+func typeCheckStatement(p *Parser, statement Node) {
+	param, ok := statement.(*Param)
+	if !ok {
+		statement.typeCheck(p)
+		return
+	}
+	var typing ExpressionType = Unknown{}
+	if param.Complement != nil {
+		param.Complement.typeCheck(p)
+		if t, ok := param.Complement.Type().(Type); ok {
+			typing = t.Value
+		} else {
+			p.error(param.Complement, TypeExpected)
+		}
+	}
+	declareIdentifier(p, param.Identifier, typing)
+}
+
+// Type check the fields of an object type definition, like '{ x number }'
+func typeCheckObjectDefinition(p *Parser, b *Block) {
+	b.scope = p.scope
+	for _, statement := range b.Statements {
+		if statement != nil {
+			statement.typeCheck(p)
+		}
+	}
+}
+
 func (b *Block) Loc() Loc { return b.loc }
 func (b *Block) reportedNode() Node {
 	if len(b.Statements) > 0 {
@@ -63,7 +94,11 @@ func (p *Parser) parseBlock() *Block {
 	statements := []Node{}
 	stopAt := []TokenKind{RightBrace, EOL, EOF}
 	for p.Peek().Kind() != RightBrace && p.Peek().Kind() != EOF {
-		statements = append(statements, p.parseStatement())
+		statement := p.parseStatement()
+		if _, ok := statement.(*ExternDeclaration); ok {
+			p.error(statement, IllegalExtern)
+		}
+		statements = append(statements, statement)
 		if !slices.Contains(stopAt, p.Peek().Kind()) {
 			recover(p, RightBrace)
 		}
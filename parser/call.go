@@ -1,10 +1,16 @@
 package parser
 
+import (
+	"slices"
+	"strings"
+)
+
 // Callee(...Args)
 type CallExpression struct {
 	Callee Expression
 	Args   *ParenthesizedExpression // contains a *TupleExpression
 	typing ExpressionType
+	signal bool // true if the signal of the enclosing task group is passed
 }
 
 func (c *CallExpression) getChildren() []Node {
@@ -23,27 +29,75 @@ func (c *CallExpression) Loc() Loc {
 }
 func (c *CallExpression) Type() ExpressionType { return c.typing }
 
+// Check if the signal of the enclosing task group is passed as last argument
+func (c *CallExpression) PassesSignal() bool { return c.signal }
+
 // Parse a call expression.
 // It can be either a function call or an instanciation.
 func parseCallExpression(p *Parser, callee Expression) *CallExpression {
 	args := p.parseParenthesizedExpression()
 	args.Expr = makeTuple(args.Expr)
-	return &CallExpression{callee, args, nil}
+	return &CallExpression{Callee: callee, Args: args}
 }
 
 func (c *CallExpression) typeCheck(p *Parser) {
 	c.Callee.typeCheck(p)
-	switch c.Callee.Type().(type) {
+	switch callee := c.CalledType().(type) {
 	case Function:
-		typeCheckFunctionCall(p, c)
+		typeCheckFunctionCall(p, c, callee)
+		reportReadOnlyReceiver(p, c, callee)
+		reportEscapingArgs(p, c, callee)
+		if IsOptionalChain(c.Callee) {
+			c.typing = makeOptional(c.typing)
+		}
+	case Type:
+		if _, ok := callee.Value.(String); !ok {
+			p.error(c.Callee, FunctionExpressionExpected)
+			c.Args.typeCheck(p)
+			return
+		}
+		typeCheckStringConversion(p, c)
 	default:
 		p.error(c.Callee, FunctionExpressionExpected)
 		c.Args.typeCheck(p)
 	}
 }
 
-func typeCheckFunctionCall(p *Parser, c *CallExpression) {
-	function := c.Callee.Type().(Function)
+// Get the type of the called function.
+// In optional chains like 'a?.method()', this is the type of the method.
+func (c *CallExpression) CalledType() ExpressionType {
+	t := c.Callee.Type()
+	if IsOptionalChain(c.Callee) {
+		if payload := getOptionPayload(t); payload != nil {
+			return payload
+		}
+	}
+	return t
+}
+
+// Check if an expression is an optional property access, like 'a?.b'
+func IsOptionalChain(expr Expression) bool {
+	switch expr := expr.(type) {
+	case *PropertyAccessExpression:
+		return expr.Optional
+	default:
+		return false
+	}
+}
+
+func typeCheckFunctionCall(p *Parser, c *CallExpression, function Function) {
+	switch function.Intrinsic {
+	case FormatIntrinsic:
+		typeCheckFormatArguments(p, c.Args.Expr.(*TupleExpression))
+		c.typing = function.Returned
+		return
+	case EncodeIntrinsic:
+		typeCheckJsonEncoding(p, c)
+		return
+	case DecodeIntrinsic:
+		typeCheckJsonDecoding(p, c)
+		return
+	}
 
 	p.pushScope(NewScope(ProgramScope))
 	defer p.dropScope()
@@ -52,9 +106,15 @@ func typeCheckFunctionCall(p *Parser, c *CallExpression) {
 		p.scope.Add(param.Name, Loc{}, Type{param})
 	}
 
-	params := function.Params.Elements
-	typeCheckFunctionArguments(p, c.Args.Expr.(*TupleExpression), params)
-	validateArgumentsNumber(p, c.Args.Expr.(*TupleExpression), params)
+	args := c.Args.Expr.(*TupleExpression)
+	// inferred types are written to the params, not to the declared function
+	params := slices.Clone(function.Params.Elements)
+	if takesGroupSignal(p, args, params) {
+		c.signal = true
+		params = params[:len(params)-1]
+	}
+	typeCheckFunctionArguments(p, args, params)
+	validateArgumentsNumber(p, args, params)
 	t, ok := function.Returned.build(p.scope, nil)
 	if !ok {
 		p.error(c, MissingTypeArgs)
@@ -64,20 +124,92 @@ func typeCheckFunctionCall(p *Parser, c *CallExpression) {
 	c.typing = t
 }
 
-// Make sure that every parsed argument is compliant with the function's type
+// Check a conversion like 'string(value)', which renders any value as a
+// string
+func typeCheckStringConversion(p *Parser, c *CallExpression) {
+	args := c.Args.Expr.(*TupleExpression)
+	validateArgumentsNumber(p, args, []ExpressionType{Unknown{}})
+	typeCheckFormattedValues(p, args.Elements)
+	c.typing = String{}
+}
+
+// Check the arguments of functions like 'io.print', which take a format
+// string literal followed by a value for each '{}' in the format string
+func typeCheckFormatArguments(p *Parser, args *TupleExpression) {
+	if len(args.Elements) == 0 {
+		p.error(args, MissingElements, 1, 0)
+		return
+	}
+	format, ok := args.Elements[0].(*Literal)
+	if !ok || format.Kind() != StringLiteral {
+		args.Elements[0].typeCheck(p)
+		p.error(args.Elements[0], StringExpected)
+		return
+	}
+	typeCheckFormattedValues(p, args.Elements[1:])
+	expected := len(SplitFormat(format.Token.Text()))
+	if received := len(args.Elements); received > expected {
+		p.error(args, TooManyElements, expected, received)
+	} else if received < expected {
+		p.error(args, MissingElements, expected, received)
+	}
+}
+
+func typeCheckFormattedValues(p *Parser, values []Expression) {
+	for _, value := range values {
+		value.typeCheck(p)
+		if _, ok := value.Type().(Type); ok {
+			p.error(value, ValueExpected)
+		}
+	}
+}
+
+// Split a format string literal, like '"x = {}"', around its '{}'
+// placeholders. The literal's quotes are removed, and escaped braces
+// ('{{' and '}}') are unescaped.
+func SplitFormat(literal string) []string {
+	pieces := []string{}
+	piece := []byte{}
+	s := unquote(literal)
+	for i := 0; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "{}"):
+			pieces = append(pieces, string(piece))
+			piece = []byte{}
+			i++
+		case strings.HasPrefix(s[i:], "{{"), strings.HasPrefix(s[i:], "}}"):
+			piece = append(piece, s[i])
+			i++
+		default:
+			piece = append(piece, s[i])
+		}
+	}
+	return append(pieces, string(piece))
+}
+
+// Make sure that every parsed argument is compliant with the function's type.
+// Function expressions are checked last, so that the type arguments inferred
+// from other arguments can be used to type their params.
 func typeCheckFunctionArguments(p *Parser, args *TupleExpression, params []ExpressionType) {
 	l := len(params)
 	if len(args.Elements) < len(params) {
 		l = len(args.Elements)
 	}
 	for i, element := range args.Elements[:l] {
-		typeCheckFunctionArgument(p, &params[i], element)
+		if _, ok := element.(*FunctionExpression); !ok {
+			typeCheckFunctionArgument(p, &params[i], element)
+		}
+	}
+	for i, element := range args.Elements[:l] {
+		if _, ok := element.(*FunctionExpression); ok {
+			typeCheckFunctionArgument(p, &params[i], element)
+		}
 	}
 }
 
 func typeCheckFunctionArgument(p *Parser, expected *ExpressionType, received Expression) {
 	if f, ok := received.(*FunctionExpression); ok {
-		typeCheckFunctionHOFArgument(p, *expected, f)
+		typeCheckFunctionHOFArgument(p, expected, f)
 		return
 	}
 	received.typeCheck(p)
@@ -96,13 +228,38 @@ func typeCheckFunctionArgument(p *Parser, expected *ExpressionType, received Exp
 	}
 }
 
-func typeCheckFunctionHOFArgument(p *Parser, expected ExpressionType, received *FunctionExpression) {
-	if e, ok := expected.(Function); ok {
-		typeCheckHOF(p, received, *e.Params)
-	} else {
+// Check a function passed as argument against the expected function type.
+// Its returned type is used to infer the remaining type arguments.
+func typeCheckFunctionHOFArgument(p *Parser, expected *ExpressionType, received *FunctionExpression) {
+	e, ok := (*expected).(Function)
+	if !ok {
 		received.typeCheck(p)
-		p.error(received, CannotAssignType, expected, received.typing)
+		p.error(received, CannotAssignType, *expected, received.typing)
+		return
+	}
+	typeCheckHOF(p, received, buildKnownTypes(p.scope, *e.Params))
+	built, ok := e.build(p.scope, received.typing)
+	*expected = built
+	if !ok {
+		p.error(received, MissingTypeArgs)
+	} else if !built.Extends(received.typing) {
+		p.error(received, CannotAssignType, built, received.typing)
+	}
+}
+
+// Replace the generics already inferred in the given scope by their value.
+// Unknown generics are left as is.
+func buildKnownTypes(scope *Scope, t Tuple) Tuple {
+	elements := make([]ExpressionType, len(t.Elements))
+	for i, element := range t.Elements {
+		built, ok := element.build(scope, nil)
+		if ok && built != nil {
+			elements[i] = built
+		} else {
+			elements[i] = element
+		}
 	}
+	return Tuple{elements}
 }
 
 // Make sure that the correct number of arguments were passed to the function
@@ -44,11 +44,12 @@ func (c *CatchExpression) typeCheck(p *Parser) {
 		err = alias.Ref.(Sum).getMember("Err")
 	}
 	if c.Identifier != nil {
-		p.scope.Add(c.Identifier.Text(), c.Identifier.Loc(), err)
+		p.scope.bind(c.Identifier, c.Identifier.Loc(), err)
 	}
 
 	c.Body.typeCheck(p)
-	if !happy.Extends(c.Body.Type()) {
+	// a body leaving the function doesn't produce any value
+	if !Exits(c.Body) && !happy.Extends(c.Body.Type()) {
 		p.error(c.Body.reportedNode(), CannotAssignType, happy, c.Body.Type())
 	}
 }
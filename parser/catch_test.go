@@ -115,3 +115,27 @@ func TestCheckCatchExpressionBlockNotMatching(t *testing.T) {
 		t.Fatalf("Expected string, got %#v", expr.Type())
 	}
 }
+
+func TestCheckCatchExpressionThrowingBody(t *testing.T) {
+	source := "f :: (x string!number) => string!number {\n"
+	source += "    v := x catch e {\n"
+	source += "        throw e\n"
+	source += "    }\n"
+	source += "    v + 1\n"
+	source += "}\n"
+	source += "io.log(f)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+}
+
+func TestCheckCatchExpressionReturningBody(t *testing.T) {
+	source := "f :: (x string!number) => number {\n"
+	source += "    v := x catch {\n"
+	source += "        return 0\n"
+	source += "    }\n"
+	source += "    v + 1\n"
+	source += "}\n"
+	source += "io.log(f)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+}
@@ -0,0 +1,427 @@
+package parser
+
+// A node of a function's control flow graph.
+// Each node evaluates a piece of code, described by its effects on
+// variables.
+type flowNode struct {
+	node     Node          // evaluated node, nil for join points
+	declares []*Variable   // variables declared without initial value
+	reads    []*Identifier // in evaluation order
+	writes   []*Variable
+	next     []*flowNode
+	linked   bool // true if some node leads to this one
+}
+
+type flowGraph struct {
+	entry *flowNode
+	end   *flowNode // reached when falling off the end of the body
+	nodes []*flowNode
+}
+
+type flowLoop struct {
+	label string    // empty if the loop is not labeled
+	head  *flowNode // target of 'continue'
+	after *flowNode // target of 'break'
+}
+
+type flowBuilder struct {
+	p       *Parser
+	graph   *flowGraph
+	current *flowNode // nil if subsequent code is unreachable
+	exit    *flowNode // target of 'return' and 'throw'
+	loops   []flowLoop
+}
+
+// Build the control flow graph of a function body
+func buildFlowGraph(p *Parser, body *Block) *flowGraph {
+	b := &flowBuilder{p: p, graph: &flowGraph{}}
+	b.graph.entry = b.join()
+	b.exit = b.join()
+	b.current = b.graph.entry
+	b.statements(body.Statements, true)
+	b.graph.end = b.join()
+	b.link(b.current, b.graph.end)
+	return b.graph
+}
+
+// Create a node without linking it
+func (b *flowBuilder) join() *flowNode {
+	node := &flowNode{}
+	b.graph.nodes = append(b.graph.nodes, node)
+	return node
+}
+
+func (b *flowBuilder) link(from *flowNode, to *flowNode) {
+	if from != nil {
+		from.next = append(from.next, to)
+		to.linked = true
+	}
+}
+
+// Continue building from a join point.
+// If no node leads to it, subsequent code is unreachable.
+func (b *flowBuilder) resume(join *flowNode) {
+	if join.linked {
+		b.current = join
+	} else {
+		b.current = nil
+	}
+}
+
+// Add a node evaluating the given node after the current one
+func (b *flowBuilder) add(n Node) *flowNode {
+	node := b.join()
+	node.node = n
+	if n != nil {
+		collectEffects(node, n)
+	}
+	b.link(b.current, node)
+	b.current = node
+	return node
+}
+
+// Build a list of statements.
+// If used is false, the value of the last statement is discarded.
+func (b *flowBuilder) statements(statements []Node, used bool) {
+	last := len(statements) - 1
+	reported := false
+	for i, statement := range statements {
+		if b.current == nil {
+			if !reported {
+				b.reportUnreachable(statements, i)
+				reported = true
+			}
+			// unreachable code is still analyzed, from its own entry point
+			b.current = b.join()
+		}
+		isUsed := used && i == last
+		if !isUsed {
+			b.reportDiscardedResult(statement)
+		}
+		b.statement(statement, isUsed)
+	}
+}
+
+// Report unreachable statements, starting at given index.
+// Code directly following an exit statement is reported while parsing.
+func (b *flowBuilder) reportUnreachable(statements []Node, index int) {
+	if index > 0 {
+		if _, ok := statements[index-1].(*Exit); ok {
+			return
+		}
+	}
+	loc := Loc{
+		Start: statements[index].Loc().Start,
+		End:   statements[len(statements)-1].Loc().End,
+	}
+	b.p.error(&Block{loc: loc}, UnreachableCode)
+}
+
+// Report calls returning a result whose value is never used
+func (b *flowBuilder) reportDiscardedResult(statement Node) {
+	call, ok := statement.(*CallExpression)
+	if !ok {
+		return
+	}
+	if alias, ok := call.Type().(TypeAlias); ok && alias.Name == "!" {
+		b.p.error(call, DiscardedResult)
+	}
+}
+
+func (b *flowBuilder) statement(statement Node, used bool) {
+	switch statement := statement.(type) {
+	case *Assignment:
+		b.assignment(statement)
+	case *Block:
+		b.statements(statement.Statements, used)
+	case *CatchExpression:
+		b.catch(statement, used)
+	case *Exit:
+		b.exitStatement(statement)
+	case *ForExpression:
+		b.loop(statement)
+	case *IfExpression:
+		b.ifExpression(statement, used)
+	case *MatchExpression:
+		b.match(statement, used)
+	case *SelectExpression:
+		b.selectExpression(statement, used)
+	case *ParenthesizedExpression:
+		if statement.Expr != nil {
+			b.statement(statement.Expr, used)
+		}
+	case *TaskGroup:
+		if statement.Body != nil {
+			b.statements(statement.Body.Statements, used)
+		}
+	default:
+		b.add(statement)
+	}
+}
+
+func (b *flowBuilder) assignment(a *Assignment) {
+	switch a.Value.(type) {
+	case *Block, *CatchExpression, *ForExpression, *IfExpression, *MatchExpression, *SelectExpression, *TaskGroup:
+		if a.Operator.Kind() == Define {
+			break
+		}
+		b.statement(a.Value, true)
+		if b.current == nil {
+			return
+		}
+		node := b.add(nil)
+		node.node = a
+		collectAssignmentWrites(node, a)
+		return
+	}
+	b.add(a)
+}
+
+func (b *flowBuilder) exitStatement(e *Exit) {
+	node := b.add(e)
+	b.current = nil
+	switch e.Operator.Kind() {
+	case ReturnKeyword, ThrowKeyword:
+		b.link(node, b.exit)
+	case BreakKeyword:
+		if loop, ok := b.findLoop(e.Label); ok {
+			b.link(node, loop.after)
+		}
+	case ContinueKeyword:
+		if loop, ok := b.findLoop(e.Label); ok {
+			b.link(node, loop.head)
+		}
+	}
+}
+
+// Find the loop targeted by an exit statement.
+// Unlabeled exits target the innermost loop.
+func (b *flowBuilder) findLoop(label *Identifier) (flowLoop, bool) {
+	for i := len(b.loops) - 1; i >= 0; i-- {
+		if label == nil || b.loops[i].label == label.Text() {
+			return b.loops[i], true
+		}
+	}
+	return flowLoop{}, false
+}
+
+func (b *flowBuilder) ifExpression(i *IfExpression, used bool) {
+	condition := b.add(i.Condition)
+	after := b.join()
+	if i.Body != nil {
+		b.statements(i.Body.Statements, used)
+	}
+	b.link(b.current, after)
+
+	b.current = condition
+	if i.Alternate != nil {
+		b.statement(i.Alternate, used)
+	}
+	b.link(b.current, after)
+	b.resume(after)
+}
+
+// Match expressions are considered exhaustive,
+// since non-exhaustive ones are reported while type checking.
+func (b *flowBuilder) match(m *MatchExpression, used bool) {
+	value := b.add(m.Value)
+	after := b.join()
+	for _, c := range m.Cases {
+		b.current = value
+		b.statements(c.Statements, used)
+		b.link(b.current, after)
+	}
+	if len(m.Cases) == 0 {
+		b.link(value, after)
+	}
+	b.resume(after)
+}
+
+func (b *flowBuilder) selectExpression(s *SelectExpression, used bool) {
+	start := b.current
+	after := b.join()
+	for _, c := range s.Cases {
+		b.current = start
+		if c.Operation != nil && !c.IsDefault() {
+			b.statement(c.Operation, true)
+		}
+		b.statements(c.Statements, used)
+		b.link(b.current, after)
+	}
+	if len(s.Cases) == 0 {
+		b.link(start, after)
+	}
+	b.resume(after)
+}
+
+func (b *flowBuilder) loop(f *ForExpression) {
+	head := b.add(f.Expr)
+	after := b.join()
+	if f.Expr != nil {
+		// conditional loops and 'for ... in' loops can end
+		b.link(head, after)
+	}
+	loop := flowLoop{head: head, after: after}
+	if f.Label != nil {
+		loop.label = f.Label.Text()
+	}
+	b.loops = append(b.loops, loop)
+	if f.Body != nil {
+		b.statements(f.Body.Statements, false)
+	}
+	b.link(b.current, head)
+	b.loops = b.loops[:len(b.loops)-1]
+	b.resume(after)
+}
+
+func (b *flowBuilder) catch(c *CatchExpression, used bool) {
+	left := b.add(c.Left)
+	after := b.join()
+	b.link(left, after)
+	if c.Body != nil {
+		b.statements(c.Body.Statements, used)
+	}
+	b.link(b.current, after)
+	b.resume(after)
+}
+
+// Collect the variables declared, read and written by a node.
+// Nested functions are not walked: they have their own graph.
+func collectEffects(node *flowNode, n Node) {
+	switch n := n.(type) {
+	case *Param:
+		if n.Identifier != nil && n.Identifier.variable != nil {
+			node.declares = append(node.declares, n.Identifier.variable)
+		}
+		return
+	case *Assignment:
+		if n.Operator.Kind() == Define {
+			return
+		}
+		if n.Value != nil {
+			collectReads(node, n.Value)
+		}
+		if n.Operator.Kind() != Assign && n.Operator.Kind() != Declare {
+			collectReads(node, n.Pattern)
+		}
+		collectAssignmentWrites(node, n)
+		return
+	}
+	collectReads(node, n)
+}
+
+func collectReads(node *flowNode, n Node) {
+	Walk(n, func(n Node, skip func()) {
+		switch n := n.(type) {
+		case *FunctionExpression:
+			skip()
+		case *Assignment:
+			collectEffects(node, n)
+			skip()
+		case *Identifier:
+			if n.variable != nil {
+				node.reads = append(node.reads, n)
+			}
+		}
+	})
+}
+
+// Collect the variables written by an assignment.
+// Patterns like '*ref = value' or 'object.key = value' read their operand.
+func collectAssignmentWrites(node *flowNode, a *Assignment) {
+	switch pattern := a.Pattern.(type) {
+	case *Identifier:
+		if pattern.variable != nil {
+			node.writes = append(node.writes, pattern.variable)
+		}
+	case *TupleExpression:
+		for _, element := range pattern.Elements {
+			if identifier, ok := element.(*Identifier); ok && identifier.variable != nil {
+				node.writes = append(node.writes, identifier.variable)
+			}
+		}
+	case nil:
+	default:
+		if a.Operator.Kind() != Declare {
+			collectReads(node, pattern)
+		}
+	}
+}
+
+// Check if falling off the end of the body is possible
+func (g *flowGraph) fallsThrough() bool {
+	return g.reachable()[g.end]
+}
+
+func (g *flowGraph) reachable() map[*flowNode]bool {
+	reached := map[*flowNode]bool{g.entry: true}
+	stack := []*flowNode{g.entry}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, next := range node.next {
+			if !reached[next] {
+				reached[next] = true
+				stack = append(stack, next)
+			}
+		}
+	}
+	return reached
+}
+
+// Report variables declared without initial value that may be read before
+// being assigned.
+func (g *flowGraph) reportUseBeforeAssign(p *Parser) {
+	// variables that may be unassigned when entering each node
+	unassigned := map[*flowNode]map[*Variable]bool{}
+	for _, node := range g.nodes {
+		unassigned[node] = map[*Variable]bool{}
+	}
+	changed := true
+	for changed {
+		changed = false
+		for _, node := range g.nodes {
+			out := node.transfer(unassigned[node])
+			for _, next := range node.next {
+				for v := range out {
+					if !unassigned[next][v] {
+						unassigned[next][v] = true
+						changed = true
+					}
+				}
+			}
+		}
+	}
+
+	reported := map[*Variable]bool{}
+	for _, node := range g.nodes {
+		state := map[*Variable]bool{}
+		for v := range unassigned[node] {
+			state[v] = true
+		}
+		for _, v := range node.declares {
+			state[v] = true
+		}
+		for _, read := range node.reads {
+			if state[read.variable] && !reported[read.variable] {
+				reported[read.variable] = true
+				p.error(read, UseBeforeAssign, read.Text())
+			}
+		}
+	}
+}
+
+// Get the variables that may be unassigned after this node
+func (node *flowNode) transfer(in map[*Variable]bool) map[*Variable]bool {
+	out := map[*Variable]bool{}
+	for v := range in {
+		out[v] = true
+	}
+	for _, v := range node.declares {
+		out[v] = true
+	}
+	for _, v := range node.writes {
+		delete(out, v)
+	}
+	return out
+}
@@ -0,0 +1,157 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMissingReturn(t *testing.T) {
+	source := "f :: (b boolean) => number {\n"
+	source += "    if b {\n"
+	source += "        return 1\n"
+	source += "    }\n"
+	source += "    x := 2\n"
+	source += "}\n"
+	source += "io.log(f)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, MissingReturn, UnusedVariable)
+}
+
+func TestReturnOnEveryPath(t *testing.T) {
+	source := "f :: (b boolean) => number {\n"
+	source += "    if b {\n"
+	source += "        return 1\n"
+	source += "    } else {\n"
+	source += "        return 2\n"
+	source += "    }\n"
+	source += "}\n"
+	source += "io.log(f)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+}
+
+func TestInfiniteLoopDoesNotFallThrough(t *testing.T) {
+	source := "f :: () => number {\n"
+	source += "    for {\n"
+	source += "        return 1\n"
+	source += "    }\n"
+	source += "}\n"
+	source += "io.log(f)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+}
+
+func TestUnreachableAfterBranches(t *testing.T) {
+	source := "f :: (b boolean) => number {\n"
+	source += "    if b {\n"
+	source += "        return 1\n"
+	source += "    } else {\n"
+	source += "        return 2\n"
+	source += "    }\n"
+	source += "    io.log(b)\n"
+	source += "}\n"
+	source += "io.log(f)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, UnreachableCode)
+}
+
+func TestUseBeforeAssign(t *testing.T) {
+	source := "f :: (b boolean) => number {\n"
+	source += "    n number\n"
+	source += "    if b {\n"
+	source += "        n = 1\n"
+	source += "    }\n"
+	source += "    n\n"
+	source += "}\n"
+	source += "io.log(f)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, UseBeforeAssign)
+}
+
+func TestDefiniteAssignment(t *testing.T) {
+	source := "f :: (b boolean) => number {\n"
+	source += "    n number\n"
+	source += "    if b {\n"
+	source += "        n = 1\n"
+	source += "    } else {\n"
+	source += "        n = 2\n"
+	source += "    }\n"
+	source += "    n\n"
+	source += "}\n"
+	source += "io.log(f)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+}
+
+func TestAssignmentInLoop(t *testing.T) {
+	source := "f :: () => number {\n"
+	source += "    n number\n"
+	source += "    for x in 0..=4 {\n"
+	source += "        n = x\n"
+	source += "    }\n"
+	source += "    n\n"
+	source += "}\n"
+	source += "io.log(f)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, UseBeforeAssign)
+}
+
+func TestDiscardedResult(t *testing.T) {
+	source := "g :: () => !number { 1 }\n"
+	source += "f :: () => number {\n"
+	source += "    g()\n"
+	source += "    2\n"
+	source += "}\n"
+	source += "io.log(f)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, DiscardedResult)
+}
+
+func TestLabeledBreak(t *testing.T) {
+	source := "f :: () => number {\n"
+	source += "    n number\n"
+	source += "    outer: for {\n"
+	source += "        for {\n"
+	source += "            n = 1\n"
+	source += "            break outer\n"
+	source += "        }\n"
+	source += "    }\n"
+	source += "    n\n"
+	source += "}\n"
+	source += "io.log(f)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+}
+
+func TestLabeledBreakFallsThrough(t *testing.T) {
+	source := "f :: () => number {\n"
+	source += "    outer: for {\n"
+	source += "        for {\n"
+	source += "            break outer\n"
+	source += "        }\n"
+	source += "        return 1\n"
+	source += "    }\n"
+	source += "    x := 2\n"
+	source += "}\n"
+	source += "io.log(f)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, UnreachableCode, MissingReturn, UnusedVariable)
+}
+
+func TestLabeledContinue(t *testing.T) {
+	parser := MakeParser(strings.NewReader("outer: for { for { continue outer } }"))
+	loop := parser.parseStatement()
+	graph := buildFlowGraph(parser, &Block{Statements: []Node{loop}})
+
+	outerHead := graph.entry.next[0]
+	for _, node := range graph.nodes {
+		if _, ok := node.node.(*Exit); !ok {
+			continue
+		}
+		if len(node.next) != 1 || node.next[0] != outerHead {
+			t.Fatal("Expected 'continue outer' to lead to the outer loop")
+		}
+		return
+	}
+	t.Fatal("Expected a node for 'continue outer'")
+}
@@ -28,11 +28,15 @@ func parseComputedAccessExpression(p *Parser, expr Expression) *ComputedAccessEx
 
 func (expr *ComputedAccessExpression) typeCheck(p *Parser) {
 	expr.Expr.typeCheck(p)
-	switch t := expr.Expr.Type().(type) {
+	switch t := deref(expr.Expr.Type()).(type) {
 	case Type:
 		typeCheckGenericType(p, expr)
 	case Function:
 		typeCheckGenericFunction(p, expr)
+	case List:
+		typeCheckIndexAccess(p, expr, t, t.Element)
+	case String:
+		typeCheckIndexAccess(p, expr, t, t)
 	default:
 		p.error(expr.Expr, NotSubscriptable, t)
 		expr.typing = Unknown{}
@@ -76,5 +80,43 @@ func typeCheckGenericFunction(p *Parser, expr *ComputedAccessExpression) {
 		TypeParams: typeParams,
 		Params:     &Tuple{params},
 		Returned:   returned,
+		Host:       t.Host,
+	}
+}
+
+// Check indexing a list or a string like 'list[i]', or slicing it like
+// 'list[1..3]'.
+// Slices have the type of the sliced value, elements have the given type:
+// indexes and slice bounds out of range, including negative ones, throw at
+// runtime, while 'list.get(i)' returns an option.
+func typeCheckIndexAccess(p *Parser, expr *ComputedAccessExpression, sliced ExpressionType, element ExpressionType) {
+	// writing an element only writes the list
+	outer := p.writing
+	p.writing = nil
+	defer func() { p.writing = outer }()
+
+	switch index := expr.Property.Expr.(type) {
+	case nil:
+		p.error(expr.Property, ExpressionExpected)
+		expr.typing = Unknown{}
+	case *RangeExpression:
+		index.typeCheck(p)
+		if index.Step != nil {
+			p.error(index.Step, UnexpectedExpression)
+		}
+		for _, bound := range index.getChildren() {
+			typeCheckListIndex(p, bound.(Expression))
+		}
+		expr.typing = sliced
+	default:
+		index.typeCheck(p)
+		typeCheckListIndex(p, index)
+		expr.typing = element
+	}
+}
+
+func typeCheckListIndex(p *Parser, index Expression) {
+	if _, ok := index.Type().(Number); !ok {
+		p.error(index, CannotAssignType, Number{}, index.Type())
 	}
 }
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -88,3 +89,60 @@ func TestGenericFunctionWithTypeArgs(t *testing.T) {
 		t.Fatalf("Param should've been set to Number{}, got %#v", function.Returned)
 	}
 }
+
+func TestListIndex(t *testing.T) {
+	source := "list := []number{1, 2}\n"
+	source += "list[0] = list[1]"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+}
+
+func TestListBadIndex(t *testing.T) {
+	source := "list := []number{1, 2}\n"
+	source += "list[\"0\"] = \"a\""
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, CannotAssignType, CannotAssignType)
+}
+
+func TestListSlice(t *testing.T) {
+	source := "list := []number{1, 2, 3}\n"
+	source += "list[1..=2]"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	list, ok := statements[1].(*ComputedAccessExpression).Type().(List)
+	if !ok {
+		t.Fatalf("Expected list, got %#v", statements[1].(*ComputedAccessExpression).Type())
+	}
+	if _, ok := list.Element.(Number); !ok {
+		t.Fatalf("Expected []number, got %v", list.Text())
+	}
+}
+
+func TestListSliceAssignment(t *testing.T) {
+	source := "list := []number{1, 2, 3}\n"
+	source += "list[1..] = list"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, InvalidPattern)
+}
+
+func TestStringIndex(t *testing.T) {
+	source := "s := \"abc\"\n"
+	source += "s[1..]\n"
+	source += "s[0]"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	for _, statement := range statements[1:] {
+		if _, ok := statement.(*ComputedAccessExpression).Type().(String); !ok {
+			t.Fatalf("Expected string, got %v", statement.(Expression).Type().Text())
+		}
+	}
+}
+
+func TestStringIndexAssignment(t *testing.T) {
+	source := "s := \"abc\"\n"
+	source += "s[0] = \"d\""
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, InvalidPattern)
+}
@@ -96,6 +96,9 @@ func parseEntry(p *Parser, expr Expression) Expression {
 		return expr
 	}
 	p.Consume()
+	if identifier, ok := expr.(*Identifier); ok && p.Peek().Kind() == ForKeyword {
+		return parseLabeledLoop(p, identifier)
+	}
 	switch expr.(type) {
 	case *BracketedExpression, *Identifier, *Literal:
 	default:
@@ -16,13 +16,14 @@ const (
 	UnexpectedExpression
 	IntegerExpected
 	IdentifierExpected
+	StringExpected
 	TypeIdentifierExpected
 	TypeParamsExpected
-	RangeExpected
 	FieldExpected
 	FieldKeyExpected
 	FunctionExpressionExpected
 	CallExpressionExpected
+	ChannelOperationExpected
 	ParameterExpected
 	ReceiverExpected
 
@@ -30,9 +31,15 @@ const (
 	IllegalContinue
 	IllegalReturn
 	IllegalThrow
+	IllegalYield
 	IllegalResult
+	IllegalPropagation // [propagated type, returned type]
+	IllegalExtern
+	IllegalMut
 
 	ReservedName
+	InvalidExternName
+	HostErrorType
 	DuplicateIdentifier
 
 	InvalidPattern
@@ -41,6 +48,11 @@ const (
 	MissingElements // [expecter count, received count]
 	MandatoryAfterOptional
 	UnreachableCode
+	MissingReturn
+	UseBeforeAssign
+	DiscardedResult
+	EscapingPromise
+	EscapingRef // [name, declaration loc]
 	CatchallNotLast
 	NotExhaustive
 
@@ -56,7 +68,9 @@ const (
 	IterableExpected
 	FunctionExpected
 	PromiseExpected
+	OptionExpected
 	ResultExpected
+	FailableExpected
 	RefExpected
 	ObjectTypeExpected
 	FunctionTypeExpected
@@ -67,8 +81,11 @@ const (
 	UnneededAsync
 	UnusedVariable
 	CannotFind
+	UnusedLabel
+	CannotFindLabel
 
 	OutOfRange
+	ZeroStep
 	MissingTypeArgs
 	UnexpectedTypeArgs
 	CannotAssignType // [expected type, received type]
@@ -76,11 +93,15 @@ const (
 	NotInstanceable
 	Unmatchable
 	NotReferenceable
+	ReadOnlyWrite    // [name, declaration loc]
+	ReadOnlyRefWrite // [name, declaration loc], if the reference is a variable
+	NotSerializable  // [type]
 	MismatchedTypes
 	PropertyDoesNotExist
 	TypeDoesNotImplement
 	MissingKeys
 	MissingConstructor
+	ConstructorDoesNotExist
 )
 
 type ParserError struct {
@@ -109,12 +130,12 @@ func (p ParserError) Text() string {
 		return "Integer expected"
 	case IdentifierExpected:
 		return "Identifier expected"
+	case StringExpected:
+		return "String expected"
 	case TypeIdentifierExpected:
 		return "Type identifier expected"
 	case TypeParamsExpected:
 		return "Type params expected"
-	case RangeExpected:
-		return "Range literal expression expected"
 	case FieldExpected:
 		return "Field expected"
 	case FieldKeyExpected:
@@ -123,6 +144,8 @@ func (p ParserError) Text() string {
 		return "Function expression expected"
 	case CallExpressionExpected:
 		return "Call expression expected"
+	case ChannelOperationExpected:
+		return "Channel operation expected, like 'c.send(value)' or 'c.receive()'"
 	case ParameterExpected:
 		return "Parameter expected"
 	case ReceiverExpected:
@@ -135,12 +158,27 @@ func (p ParserError) Text() string {
 	case IllegalReturn:
 		return "Cannot use 'return' keyword outside of functions with explicit returns"
 	case IllegalThrow:
-		return "Cannot use 'throw' keyword outside of functions with explicit returns"
+		return "Cannot use 'throw' keyword outside of functions returning results"
+	case IllegalYield:
+		return "Cannot use 'yield' keyword outside of functions"
 	case IllegalResult:
 		return "Cannot use failable expressions outside of functions with explicit returns"
+	case IllegalPropagation:
+		t1 := p.Complements[0].(ExpressionType).Text()
+		t2 := p.Complements[1].(ExpressionType).Text()
+		return fmt.Sprintf("Cannot propagate %v from a function returning %v", t1, t2)
+	case IllegalExtern:
+		return "Extern declarations are only allowed at the top level"
+	case IllegalMut:
+		return "Cannot use 'mut' keyword outside of '&mut' references and method receivers"
 
 	case ReservedName:
 		return fmt.Sprintf("'%v' is a reserved name", p.Complements[0])
+	case InvalidExternName:
+		return fmt.Sprintf("'%v' is not a valid JavaScript name", p.Complements[0])
+	case HostErrorType:
+		got := p.Complements[0].(ExpressionType).Text()
+		return fmt.Sprintf("Host functions can only fail with string errors, got %v", got)
 	case DuplicateIdentifier:
 		return fmt.Sprintf("Duplicate identifier '%v'", p.Complements[0])
 	case InvalidPattern:
@@ -161,6 +199,17 @@ func (p ParserError) Text() string {
 		return "Cannot define mandatory fields after optional ones"
 	case UnreachableCode:
 		return "Unreachable code detected"
+	case MissingReturn:
+		return "Function lacks ending return statement"
+	case UseBeforeAssign:
+		return fmt.Sprintf("Variable '%v' is used before being assigned", p.Complements[0])
+	case DiscardedResult:
+		return "Result is discarded; consider using 'try' or 'catch'"
+	case EscapingPromise:
+		return "Promise cannot escape its task group; consider awaiting it"
+	case EscapingRef:
+		line := p.Complements[1].(Loc).Start.Line
+		return fmt.Sprintf("Reference to '%v' escapes the scope of its variable (declared at line %v); consider referencing a new value, like '&Type{}'", p.Complements[0], line)
 	case CatchallNotLast:
 		return "Catch-all case should be last"
 	case NotExhaustive:
@@ -190,16 +239,22 @@ func (p ParserError) Text() string {
 		return fmt.Sprintf("Concatenable (string or list) expected, got %v", got)
 	case IterableExpected:
 		got := p.Complements[0].(ExpressionType).Text()
-		return fmt.Sprintf("Iterable (list or slice) expected, got %v", got)
+		return fmt.Sprintf("Iterable (list, map, set, string or Iterator) expected, got %v", got)
 	case FunctionExpected:
 		got := p.Complements[0].(ExpressionType).Text()
 		return fmt.Sprintf("Function expected, got %v", got)
 	case PromiseExpected:
 		got := p.Complements[0].(ExpressionType).Text()
 		return fmt.Sprintf("Promise expected, got %v", got)
+	case OptionExpected:
+		got := p.Complements[0].(ExpressionType).Text()
+		return fmt.Sprintf("Option expected, got %v", got)
 	case ResultExpected:
 		got := p.Complements[0].(ExpressionType).Text()
 		return fmt.Sprintf("Result expected, got %v", got)
+	case FailableExpected:
+		got := p.Complements[0].(ExpressionType).Text()
+		return fmt.Sprintf("Option or result expected, got %v", got)
 	case RefExpected:
 		got := p.Complements[0].(ExpressionType).Text()
 		return fmt.Sprintf("Reference expected, got %v", got)
@@ -222,9 +277,15 @@ func (p ParserError) Text() string {
 		return fmt.Sprintf("Unused variable '%v'", p.Complements[0])
 	case CannotFind:
 		return fmt.Sprintf("Cannot find name '%v'", p.Complements[0])
+	case UnusedLabel:
+		return fmt.Sprintf("Unused label '%v'", p.Complements[0])
+	case CannotFindLabel:
+		return fmt.Sprintf("Cannot find label '%v'", p.Complements[0])
 
 	case OutOfRange:
 		return fmt.Sprintf("Index out of range: max %v, got %v", p.Complements[0], p.Complements[1])
+	case ZeroStep:
+		return "Range step cannot be 0"
 	case MissingTypeArgs:
 		return "Cannot fully determine type; probably missing some type arguments"
 	case UnexpectedTypeArgs:
@@ -244,6 +305,18 @@ func (p ParserError) Text() string {
 		return fmt.Sprintf("Cannot match against type %v", t)
 	case NotReferenceable:
 		return "Cannot reference such an expression"
+	case ReadOnlyWrite:
+		line := p.Complements[1].(Loc).Start.Line
+		return fmt.Sprintf("Cannot modify '%v', which is read-only (declared at line %v)", p.Complements[0], line)
+	case ReadOnlyRefWrite:
+		if p.Complements[0] == nil {
+			return "Cannot modify a value through a read-only reference; consider using '&mut'"
+		}
+		line := p.Complements[1].(Loc).Start.Line
+		return fmt.Sprintf("Cannot modify a value through '%v', a read-only reference (declared at line %v); consider using '&mut'", p.Complements[0], line)
+	case NotSerializable:
+		t := p.Complements[0].(ExpressionType).Text()
+		return fmt.Sprintf("Cannot convert values of type %v to or from JSON", t)
 	case MismatchedTypes:
 		t1 := p.Complements[0].(ExpressionType).Text()
 		t2 := p.Complements[1].(ExpressionType).Text()
@@ -259,6 +332,8 @@ func (p ParserError) Text() string {
 		return fmt.Sprintf("Missing key(s) %v", p.Complements[0])
 	case MissingConstructor:
 		return fmt.Sprintf("Missing constructor '%v'", p.Complements[0])
+	case ConstructorDoesNotExist:
+		return fmt.Sprintf("Constructor '%v' does not exist on this type", p.Complements[0])
 
 	default:
 		panic("Error type not implemented")
@@ -0,0 +1,300 @@
+package parser
+
+// References cannot outlive the variables they point to.
+//
+// A reference is bound to the scope of the variable it points to: '&x' is
+// bound to the scope declaring 'x', and so are references to its members,
+// like '&x.list[0]'. Variables holding references, like 'r' in 'r := &x',
+// values containing references, like '(&x, 42)', and closures capturing
+// references, like '() => { *r }', are bound to the same scope as their
+// references.
+//
+// References escape their scope when they are returned from its function,
+// when they break out of its loop, or when they are stored in a place that
+// outlives it, either by assignment or by calling a method declared on a
+// 'mut' receiver, like 'list.push(&x)'.
+//
+// References received as params are bound to the scope of their function:
+// they can be returned, but they cannot be stored in a place outliving the
+// call.
+//
+// References to new values, like '&Point{x: 1}', are allocated on the heap
+// and are not bound to any scope: this is the way to create references that
+// outlive their function. References to program-level variables are not
+// bound to any scope either.
+
+// Get the variable bounding the lifetime of the references held by a value,
+// or nil if they are not bound to any scope
+func getRefTarget(value Expression) *Identifier {
+	switch e := value.(type) {
+	case *ParenthesizedExpression:
+		return getRefTarget(e.Expr)
+	case *UnaryExpression:
+		if e.Operator.Kind() != BinaryAnd {
+			return nil
+		}
+		if _, ok := e.Type().(Ref); !ok {
+			return nil
+		}
+		return getPlaceOwner(e.Operand)
+	case *Identifier:
+		if e.variable == nil {
+			return nil
+		}
+		return e.variable.original().target
+	case *TupleExpression:
+		var target *Identifier
+		for _, element := range e.Elements {
+			target = innermostTarget(target, getRefTarget(element))
+		}
+		return target
+	case *InstanceExpression:
+		if e.Args == nil {
+			return nil
+		}
+		return getRefTarget(e.Args.Expr)
+	case *Entry:
+		return getRefTarget(e.Value)
+	case *Block:
+		return getResultTarget(e.Statements)
+	case *IfExpression:
+		target := getRefTarget(e.Body)
+		if e.Alternate != nil {
+			target = innermostTarget(target, getRefTarget(e.Alternate))
+		}
+		return target
+	case *MatchExpression:
+		var target *Identifier
+		for _, c := range e.Cases {
+			target = innermostTarget(target, getResultTarget(c.Statements))
+		}
+		return target
+	case *FunctionExpression:
+		return getCapturedTarget(e)
+	default:
+		return nil
+	}
+}
+
+// Get the variable bounding the references held by the value of a block,
+// which is its last statement
+func getResultTarget(statements []Node) *Identifier {
+	if len(statements) == 0 {
+		return nil
+	}
+	value, ok := statements[len(statements)-1].(Expression)
+	if !ok {
+		return nil
+	}
+	return getRefTarget(value)
+}
+
+// Get the variable bounding the references captured by a closure, like 'r'
+// in '() => { *r }' or 'x' in '() => { &x }'
+func getCapturedTarget(f *FunctionExpression) *Identifier {
+	if f.Body == nil || f.Body.scope == nil {
+		return nil
+	}
+	var target *Identifier
+	Walk(f.Body, func(n Node, skip func()) {
+		switch n.(type) {
+		case *Identifier, *UnaryExpression:
+		default:
+			return
+		}
+		captured := getRefTarget(n.(Expression))
+		if captured != nil && !targetScope(captured).within(f.Body.scope) {
+			target = innermostTarget(target, captured)
+		}
+	})
+	return target
+}
+
+// Get the variable holding a place, like 'x' in 'x.list[0]', or nil if the
+// place is not bound to any scope
+func getPlaceOwner(place Expression) *Identifier {
+	for place != nil {
+		var next Expression
+		switch e := place.(type) {
+		case *Identifier:
+			if e.variable == nil || !isBound(e.variable) {
+				return nil
+			}
+			return e
+		case *ParenthesizedExpression:
+			place = e.Expr
+			continue
+		case *UnaryExpression:
+			if e.Operator.Kind() == Mul {
+				return getRefTarget(e.Operand)
+			}
+			return nil
+		case *PropertyAccessExpression:
+			next = e.Expr
+		case *ComputedAccessExpression:
+			next = e.Expr
+		default:
+			// new values are allocated on the heap
+			return nil
+		}
+		// members of references are implicitly dereferenced
+		if _, ok := next.Type().(Ref); ok {
+			return getRefTarget(next)
+		}
+		place = next
+	}
+	return nil
+}
+
+// Check if references to the variable are bound to its scope.
+// Program-level variables live as long as the program.
+func isBound(v *Variable) bool {
+	scope := v.original().scope
+	return scope != nil && scope.kind != ProgramScope
+}
+
+// Get the scope bounding the references to a variable, or nil
+func targetScope(target *Identifier) *Scope {
+	if target == nil {
+		return nil
+	}
+	return target.variable.original().scope
+}
+
+// Get the target whose scope ends first
+func innermostTarget(a *Identifier, b *Identifier) *Identifier {
+	if a == nil {
+		return b
+	}
+	if b == nil || targetScope(a).within(targetScope(b)) {
+		return a
+	}
+	return b
+}
+
+// Report references of the value which would outlive their target if stored
+// in the given scope. A nil scope outlives all the others.
+func reportEscapingRef(p *Parser, value Expression, storage *Scope) {
+	target := getRefTarget(value)
+	if target == nil {
+		return
+	}
+	if storage != nil && storage.within(targetScope(target)) {
+		return
+	}
+	p.error(value, EscapingRef, target.Text(), target.variable.declaredAt)
+}
+
+// Report references returned from a function, which can return the
+// references received as its params
+func reportEscapingReturn(p *Parser, value Expression, function *Scope) {
+	target := getRefTarget(value)
+	if target != nil && isParamRef(target) && targetScope(target) == function {
+		return
+	}
+	reportEscapingRef(p, value, function.outer)
+}
+
+// Check if a target stands for the references received as a param.
+// Params are their own target, while references to params, like '&param',
+// target their uses.
+func isParamRef(target *Identifier) bool {
+	return target.variable.original().target == target
+}
+
+// Bind the references received as a param to the scope of its function
+func bindParamRef(param *Identifier) {
+	if param.variable == nil {
+		return
+	}
+	if _, ok := param.variable.Typing.(Ref); ok {
+		param.variable.target = param
+	}
+}
+
+// Report references stored in a place that outlives them, like in
+// 'outer = &x', and keep track of the references held by variables
+func reportEscapingAssignment(p *Parser, a *Assignment) {
+	owner := getPlaceOwner(a.Pattern)
+	reportEscapingRef(p, a.Value, targetScope(owner))
+	if identifier, ok := a.Pattern.(*Identifier); ok && identifier.variable != nil {
+		v := identifier.variable.original()
+		v.target = innermostTarget(v.target, getRefTarget(a.Value))
+	}
+}
+
+// Keep track of the references held by declared variables, like 'r' in
+// 'r := &x'
+func bindRefTargets(pattern Expression, value Expression) {
+	switch pattern := pattern.(type) {
+	case *Identifier:
+		if pattern.variable != nil {
+			pattern.variable.target = getRefTarget(value)
+		}
+	case *TupleExpression:
+		tuple, ok := value.(*TupleExpression)
+		for i, element := range pattern.Elements {
+			if ok && i < len(tuple.Elements) {
+				bindRefTargets(element, tuple.Elements[i])
+			} else {
+				bindRefTargets(element, value)
+			}
+		}
+	}
+}
+
+// Report references returned from the current function, or breaking out of
+// the current loop
+func reportEscapingExit(p *Parser, e *Exit) {
+	var exited *Scope
+	switch {
+	case e.Operator.Kind() == ReturnKeyword:
+		if function := p.scope.enclosing(FunctionScope); function != nil {
+			reportEscapingReturn(p, e.Value, function)
+		}
+		return
+	case e.Operator.Kind() != BreakKeyword:
+		return
+	case e.Label != nil:
+		exited, _ = p.scope.findLabel(e.Label.Text())
+	default:
+		exited = p.scope.enclosing(LoopScope)
+	}
+	if exited != nil {
+		reportEscapingRef(p, e.Value, exited.outer)
+	}
+}
+
+// Report references implicitly returned by the last expression of the body
+// of the current function
+func reportEscapingResult(p *Parser, body *Block) {
+	if !producesValue(body) {
+		return
+	}
+	last := body.Statements[len(body.Statements)-1]
+	if value, ok := last.(Expression); ok {
+		reportEscapingReturn(p, value, p.scope)
+	}
+}
+
+// Report references passed to methods declared on 'mut' receivers, which
+// could be stored in a receiver outliving them, like in 'list.push(&x)'
+func reportEscapingArgs(p *Parser, c *CallExpression, method Function) {
+	access, ok := c.Callee.(*PropertyAccessExpression)
+	if !method.Mutates || !ok || c.Args == nil {
+		return
+	}
+	var owner *Identifier
+	if _, ok := access.Expr.Type().(Ref); ok {
+		owner = getRefTarget(access.Expr)
+	} else {
+		owner = getPlaceOwner(access.Expr)
+	}
+	args, ok := c.Args.Expr.(*TupleExpression)
+	if !ok {
+		return
+	}
+	for _, arg := range args.Elements {
+		reportEscapingRef(p, arg, targetScope(owner))
+	}
+}
@@ -0,0 +1,201 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReturnLocalRef(t *testing.T) {
+	source := "f :: () => &number {\n"
+	source += "    x := 1\n"
+	source += "    return &x\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, EscapingRef)
+}
+
+func TestImplicitReturnFieldRef(t *testing.T) {
+	source := "Point :: {\n    x number\n}\n"
+	source += "f :: () => {\n"
+	source += "    p := Point{x: 1}\n"
+	source += "    r := &p.x\n"
+	source += "    (r, 2)\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, EscapingRef)
+}
+
+func TestReturnParamRef(t *testing.T) {
+	source := "f :: (r &number) => {\n"
+	source += "    r\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+}
+
+func TestReturnRefToParam(t *testing.T) {
+	source := "f :: (n number) => &number {\n"
+	source += "    return &n\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, EscapingRef)
+
+	source = "f :: (r &number) => {\n"
+	source += "    &r\n"
+	source += "}"
+	_, errors = Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, EscapingRef)
+}
+
+func TestStoreParamRefInOuterVariable(t *testing.T) {
+	source := "Box :: {\n    n number\n}\n"
+	source += "saved := &mut Box{n: 0}\n"
+	source += "store :: (r &mut Box) => {\n"
+	source += "    saved = r\n"
+	source += "}\n"
+	source += "f :: () => {\n"
+	source += "    x := Box{n: 1}\n"
+	source += "    store(&mut x)\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, EscapingRef)
+}
+
+func TestStoreParamRefInLocalVariable(t *testing.T) {
+	source := "f :: (r &number) => number {\n"
+	source += "    s := r\n"
+	source += "    *s\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+}
+
+func TestReturnBranchRef(t *testing.T) {
+	source := "g :: (c boolean) => &number {\n"
+	source += "    y := 1\n"
+	source += "    z := 2\n"
+	source += "    if c { &y } else { &z }\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, EscapingRef)
+
+	source = "z := 2\n"
+	source += "g :: (o ?number) => &number {\n"
+	source += "    y := 1\n"
+	source += "    match o {\n"
+	source += "    case Some(n):\n"
+	source += "        io.log(n)\n"
+	source += "        &y\n"
+	source += "    case None:\n"
+	source += "        &z\n"
+	source += "    }\n"
+	source += "}"
+	_, errors = Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, EscapingRef)
+}
+
+func TestReturnLocalElementRef(t *testing.T) {
+	source := "f :: () => &number {\n"
+	source += "    list := []number{1}\n"
+	source += "    &list[0]\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, EscapingRef)
+}
+
+func TestReturnNewValueRef(t *testing.T) {
+	source := "Point :: {\n    x number\n}\n"
+	source += "f :: () => {\n"
+	source += "    p := &Point{x: 1}\n"
+	source += "    &p.x\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+}
+
+func TestStoreRefInOuterVariable(t *testing.T) {
+	source := "outer := &[]number{}\n"
+	source += "f :: () => {\n"
+	source += "    list := []number{}\n"
+	source += "    outer = &list\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, EscapingRef)
+}
+
+func TestPushRefInOuterList(t *testing.T) {
+	source := "refs := []&number{}\n"
+	source += "f :: () => {\n"
+	source += "    x := 1\n"
+	source += "    refs.push(&x)\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, EscapingRef)
+}
+
+func TestBreakLoopVariableRef(t *testing.T) {
+	source := "found := for i in 0..3 {\n"
+	source += "    break &i\n"
+	source += "}\n"
+	source += "found"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, EscapingRef)
+}
+
+func TestStoreRefInSameScope(t *testing.T) {
+	source := "f :: () => number {\n"
+	source += "    x := 1\n"
+	source += "    y := 2\n"
+	source += "    r := &x\n"
+	source += "    r = &y\n"
+	source += "    *r\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+}
+
+func TestReturnClosureCapturingRef(t *testing.T) {
+	source := "f :: () => {\n"
+	source += "    x := 1\n"
+	source += "    r := &x\n"
+	source += "    () => { *r }\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, EscapingRef)
+}
+
+func TestStoreClosureCapturingRef(t *testing.T) {
+	source := "outer := () => { 0 }\n"
+	source += "f :: () => {\n"
+	source += "    x := 1\n"
+	source += "    r := &x\n"
+	source += "    g := () => { *r }\n"
+	source += "    outer = g\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, EscapingRef)
+}
+
+func TestCallClosureCapturingRef(t *testing.T) {
+	source := "f :: () => number {\n"
+	source += "    x := 1\n"
+	source += "    r := &x\n"
+	source += "    g := () => { *r }\n"
+	source += "    g()\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+}
+
+func TestParseNewValueRef(t *testing.T) {
+	parser := MakeParser(strings.NewReader("&mut Point{x: 1}"))
+	expr := parser.parseExpression()
+	unary, ok := expr.(*UnaryExpression)
+	if !ok || !unary.Mutable {
+		t.Fatalf("Expected '&mut' reference, got %#v", expr)
+	}
+	if _, ok := unary.Operand.(*InstanceExpression); !ok {
+		t.Fatalf("Expected instance, got %#v", unary.Operand)
+	}
+	testParserErrors(t, parser, 0)
+}
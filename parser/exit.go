@@ -1,8 +1,12 @@
 package parser
 
+// 'break' and 'continue' exit the innermost loop, or the loop with the given
+// label, like in 'break outer value'.
 type Exit struct {
 	Operator Token
+	Label    *Identifier // nil if the exit targets the innermost loop
 	Value    Expression
+	loop     *ForExpression
 }
 
 func (e *Exit) getChildren() []Node {
@@ -13,29 +17,43 @@ func (e *Exit) getChildren() []Node {
 }
 
 func (e *Exit) typeCheck(p *Parser) {
-	if e.Value != nil {
-		e.Value.typeCheck(p)
+	if e.Value == nil {
+		return
 	}
+	e.Value.typeCheck(p)
+	if e.Operator.Kind() == ReturnKeyword && p.scope.inGroup() && containsPromise(e.Value.Type()) {
+		p.error(e.Value, EscapingPromise)
+	}
+	reportEscapingExit(p, e)
 }
 
 func (e *Exit) Loc() Loc {
 	loc := e.Operator.Loc()
 	if e.Value != nil {
 		loc.End = e.Value.Loc().End
+	} else if e.Label != nil {
+		loc.End = e.Label.Loc().End
 	}
 	return loc
 }
 
+// Get the loop exited by a 'break' statement
+func (e *Exit) Loop() *ForExpression { return e.loop }
+
 func (p *Parser) parseExit() *Exit {
 	keyword := p.Consume()
+	operator := keyword.Kind()
 
+	var label *Identifier
+	if operator == BreakKeyword || operator == ContinueKeyword {
+		label = parseExitLabel(p, operator)
+	}
 	if p.Peek().Kind() == EOL {
-		return &Exit{keyword, nil}
+		return validateExit(p, &Exit{Operator: keyword, Label: label})
 	}
 
 	value := p.parseExpression()
 
-	operator := keyword.Kind()
 	if operator == ContinueKeyword && value != nil {
 		p.error(value, UnexpectedExpression)
 	}
@@ -43,7 +61,31 @@ func (p *Parser) parseExit() *Exit {
 		p.error(&Literal{p.Peek()}, ExpressionExpected)
 	}
 
-	statement := &Exit{keyword, value}
+	return validateExit(p, &Exit{Operator: keyword, Label: label, Value: value})
+}
+
+// Parse the label of a loop, like in 'break outer'.
+// Since 'break' can take a value, a name following it is a label only if
+// an enclosing loop has this label.
+func parseExitLabel(p *Parser, operator TokenKind) *Identifier {
+	if p.Peek().Kind() != Name {
+		return nil
+	}
+	scope, ok := p.scope.findLabel(p.Peek().Text())
+	if !ok && operator == BreakKeyword {
+		return nil
+	}
+	label := &Identifier{Token: p.Consume()}
+	if ok {
+		scope.labelUsed = true
+	} else {
+		p.error(label, CannotFindLabel, label.Text())
+	}
+	return label
+}
+
+func validateExit(p *Parser, statement *Exit) *Exit {
+	operator := statement.Operator.Kind()
 	inLoop := p.scope.in(LoopScope)
 	inFunction := p.scope.in(FunctionScope)
 	if operator == BreakKeyword && !inLoop {
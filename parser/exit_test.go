@@ -92,3 +92,54 @@ func TestThrowOutsideFunction(t *testing.T) {
 		t.Fatalf("Expected 1 error, got %#v", parser.errors)
 	}
 }
+
+func TestThrowInNonResultFunction(t *testing.T) {
+	source := "f :: (x number) => number {\n"
+	source += "    if x < 0 {\n"
+	source += "        throw \"negative\"\n"
+	source += "    }\n"
+	source += "    x\n"
+	source += "}\n"
+	source += "io.log(f)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, IllegalThrow)
+}
+
+func TestBreakLabel(t *testing.T) {
+	parser := MakeParser(strings.NewReader("break outer 42"))
+	scope := NewScope(LoopScope)
+	scope.label = "outer"
+	parser.pushScope(scope)
+	parser.pushScope(NewScope(LoopScope))
+	exit := parser.parseExit()
+
+	if len(parser.errors) != 0 {
+		t.Fatalf("Expected no errors, got %#v", parser.errors)
+	}
+	if exit.Label == nil || exit.Label.Text() != "outer" {
+		t.Fatal("Expected 'outer' label")
+	}
+	if exit.Value == nil {
+		t.Fatal("Expected a value")
+	}
+	if !scope.labelUsed {
+		t.Fatal("Expected label to be used")
+	}
+}
+
+func TestBreakValueNotLabel(t *testing.T) {
+	parser := MakeParser(strings.NewReader("break value"))
+	parser.pushScope(NewScope(LoopScope))
+	exit := parser.parseExit()
+
+	if exit.Label != nil || exit.Value == nil {
+		t.Fatal("Expected a value without label")
+	}
+}
+
+func TestContinueUnknownLabel(t *testing.T) {
+	parser := MakeParser(strings.NewReader("continue outer"))
+	parser.pushScope(NewScope(LoopScope))
+	parser.parseExit()
+	testErrorKinds(t, parser.errors, CannotFindLabel)
+}
@@ -0,0 +1,284 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// An extern declaration binds a type to a JavaScript value.
+// Without a module, the value is a global, named after the identifier unless
+// another path is given. With a module, the value is one of its exports.
+// With a receiver, the value is a member of the receiver's values.
+//
+//	extern log :: (string) -> () = "console.log"
+//	extern readFile :: async (string) -> string!string from "node:fs/promises"
+//	extern (List).length :: number
+//	extern (mut List).push :: (Type) -> ()
+//
+// Members declared on 'mut' receivers are methods modifying their receiver.
+// Members bound to a dotted path are globals, reached without their receiver,
+// like constants missing from a JS object:
+//
+//	extern (Math).inf :: number = "Number.POSITIVE_INFINITY"
+//
+// Functions returning results fail by throwing exceptions, which are turned
+// into errors holding their message: their error type must be a string.
+//
+// A type name without signature declares a type only known to the host,
+// whose values and members are declared by other extern declarations.
+//
+//	extern Response
+type ExternDeclaration struct {
+	Keyword    Token
+	Receiver   *Identifier // type whose member is declared, if any
+	Mutates    bool        // true if declared on a 'mut' receiver
+	Identifier *Identifier
+	Async      Token      // nil if the function is synchronous
+	Signature  Expression // type of the value
+	Name       *Literal   // JS path or member name, if any
+	Module     *Literal   // module specifier, if any
+}
+
+func (e *ExternDeclaration) getChildren() []Node {
+	children := []Node{}
+	if e.Receiver != nil {
+		children = append(children, e.Receiver)
+	}
+	if e.Identifier != nil {
+		children = append(children, e.Identifier)
+	}
+	if e.Signature != nil {
+		children = append(children, e.Signature)
+	}
+	return children
+}
+
+func (e *ExternDeclaration) Loc() Loc {
+	loc := e.Keyword.Loc()
+	switch {
+	case e.Module != nil:
+		loc.End = e.Module.Loc().End
+	case e.Name != nil:
+		loc.End = e.Name.Loc().End
+	case e.Signature != nil:
+		loc.End = e.Signature.Loc().End
+	case e.Identifier != nil:
+		loc.End = e.Identifier.Loc().End
+	}
+	return loc
+}
+
+func (e *ExternDeclaration) typeCheck(p *Parser) {
+	if e.Identifier == nil {
+		return
+	}
+	if e.Signature == nil {
+		typeCheckExternType(p, e)
+		return
+	}
+	if e.Receiver != nil {
+		typeCheckExternMember(p, e)
+		return
+	}
+	typing, ok := getExternType(p, e)
+	if !ok {
+		return
+	}
+	defineIdentifier(p, e.Identifier, typing)
+	if variable, ok := p.scope.Find(e.Identifier.Text()); ok {
+		variable.extern = e
+	}
+}
+
+func typeCheckExternType(p *Parser, e *ExternDeclaration) {
+	if e.Receiver != nil || !e.Identifier.IsType() {
+		return
+	}
+	name := e.Identifier.Text()
+	defineIdentifier(p, e.Identifier, Type{TypeAlias{Name: name, Ref: newObject()}})
+}
+
+// Register the member as a method of the receiver type.
+// The receiver's type params can be used in the member's type.
+func typeCheckExternMember(p *Parser, e *ExternDeclaration) {
+	e.Receiver.typeCheck(p)
+	t, _ := e.Receiver.Type().(Type)
+	alias, ok := t.Value.(TypeAlias)
+	if !ok || e.Receiver.variable == nil {
+		p.error(e.Receiver, TypeIdentifierExpected)
+		return
+	}
+
+	p.pushScope(NewScope(ProgramScope))
+	addReceiverTypeParams(p, alias)
+	typing, ok := getExternType(p, e)
+	p.dropScope()
+	if !ok {
+		return
+	}
+
+	if function, ok := typing.(Function); ok {
+		function.Mutates = e.Mutates
+		typing = function
+	}
+	name := e.Identifier.Text()
+	p.scope.AddMethod(name, alias, typing)
+	receiver := e.Receiver.variable
+	if receiver.externMembers == nil {
+		receiver.externMembers = map[string]*ExternDeclaration{}
+	}
+	receiver.externMembers[name] = e
+}
+
+// Get the type of the bound value.
+// Functions are marked as implemented by the host.
+func getExternType(p *Parser, e *ExternDeclaration) (ExpressionType, bool) {
+	e.Signature.typeCheck(p)
+	t, ok := e.Signature.Type().(Type)
+	if !ok {
+		p.error(e.Signature, TypeExpected)
+		return nil, false
+	}
+	function, ok := t.Value.(Function)
+	if !ok && e.Async != nil {
+		p.error(e.Signature, FunctionTypeExpected)
+		return nil, false
+	}
+	if !ok {
+		return t.Value, true
+	}
+	function.Async = e.Async != nil
+	function.Host = true
+	reportHostErrorType(p, e, function)
+	return function, true
+}
+
+// Report host functions whose results have errors that are not strings
+func reportHostErrorType(p *Parser, e *ExternDeclaration, function Function) {
+	alias, ok := function.Returned.(TypeAlias)
+	if !ok || alias.Name != "!" {
+		return
+	}
+	err := alias.Params[1].Value
+	if _, ok := err.(String); ok || err == nil {
+		return
+	}
+	p.error(e.Signature, HostErrorType, err)
+}
+
+// Get the JS path of a global, or the name of an exported value
+func (e *ExternDeclaration) Path() string {
+	if e.Name == nil {
+		return e.Identifier.Text()
+	}
+	return unquote(e.Name.Token.Text())
+}
+
+// Check if a member is bound to a global instead of a member of its receiver
+func (e *ExternDeclaration) IsStatic() bool {
+	return e.Receiver != nil && strings.Contains(e.Path(), ".")
+}
+
+// Get the specifier of the module exporting the value, if any
+func (e *ExternDeclaration) ModuleName() string {
+	if e.Module == nil {
+		return ""
+	}
+	return unquote(e.Module.Token.Text())
+}
+
+func unquote(s string) string {
+	if len(s) < 2 {
+		return s
+	}
+	return s[1 : len(s)-1]
+}
+
+var jsPath = regexp.MustCompile(`^[A-Za-z_$][\w$]*(\.[A-Za-z_$][\w$]*)*$`)
+
+func (p *Parser) parseExternDeclaration() *ExternDeclaration {
+	declaration := &ExternDeclaration{Keyword: p.Consume()}
+
+	if p.Peek().Kind() == LeftParenthesis {
+		declaration.Receiver, declaration.Mutates = parseExternReceiver(p)
+	}
+	token := p.parseToken()
+	identifier, ok := token.(*Identifier)
+	if token != nil && !ok {
+		p.error(token, IdentifierExpected)
+	}
+	declaration.Identifier = identifier
+
+	isType := identifier != nil && identifier.IsType() && declaration.Receiver == nil
+	if isType && p.Peek().Kind() != Define {
+		return declaration
+	}
+	if p.Peek().Kind() != Define && !recover(p, Define) {
+		return declaration
+	}
+	p.Consume() // ::
+
+	if p.Peek().Kind() == AsyncKeyword {
+		declaration.Async = p.Consume()
+	}
+	outer := p.allowBraceParsing
+	p.allowBraceParsing = false
+	declaration.Signature = p.parseBinaryExpression()
+	p.allowBraceParsing = outer
+
+	if p.Peek().Kind() == Assign {
+		p.Consume() // =
+		declaration.Name = parseExternString(p)
+	}
+	if p.Peek().Kind() == FromKeyword {
+		p.Consume() // from
+		declaration.Module = parseExternString(p)
+	}
+	validateExternName(p, declaration)
+	return declaration
+}
+
+// Parse a receiver like '(List).' or '(mut List).', up to the member's name
+func parseExternReceiver(p *Parser) (*Identifier, bool) {
+	paren := p.parseParenthesizedExpression()
+	receiver, mutates := UnwrapMut(paren.Expr)
+	identifier, ok := receiver.(*Identifier)
+	if !ok || !identifier.IsType() {
+		p.error(paren, TypeIdentifierExpected)
+		identifier = nil
+	}
+	if p.Peek().Kind() != Dot {
+		recover(p, Dot)
+	}
+	if p.Peek().Kind() == Dot {
+		p.Consume()
+	}
+	return identifier, mutates
+}
+
+// Globals are reached through a dotted path, exports through their name.
+// Members are reached through their name, or through a global's path.
+func validateExternName(p *Parser, e *ExternDeclaration) {
+	if e.Receiver != nil && e.Module != nil {
+		p.error(e.Module, UnexpectedExpression)
+	}
+	if e.Name == nil {
+		return
+	}
+	path := e.Path()
+	if !jsPath.MatchString(path) || e.Module != nil && strings.Contains(path, ".") {
+		p.error(e.Name, InvalidExternName, path)
+	}
+}
+
+func parseExternString(p *Parser) *Literal {
+	token := p.parseToken()
+	literal, ok := token.(*Literal)
+	if !ok || literal.Token.Kind() != StringLiteral {
+		if token != nil {
+			p.error(token, StringExpected)
+		}
+		return nil
+	}
+	return literal
+}
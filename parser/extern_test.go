@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExternDeclaration(t *testing.T) {
+	source := "extern readFile :: async (string) -> string!string from \"node:fs/promises\""
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	declaration, ok := statements[0].(*ExternDeclaration)
+	if !ok {
+		t.Fatalf("Expected extern declaration, got %#v", statements[0])
+	}
+	if declaration.Path() != "readFile" {
+		t.Fatalf("Expected path 'readFile', got '%v'", declaration.Path())
+	}
+	if declaration.ModuleName() != "node:fs/promises" {
+		t.Fatalf("Expected module 'node:fs/promises', got '%v'", declaration.ModuleName())
+	}
+}
+
+func TestExternType(t *testing.T) {
+	parser := MakeParser(strings.NewReader("extern sqrt :: (number) -> number = \"Math.sqrt\""))
+	declaration := parser.parseStatement().(*ExternDeclaration)
+	declaration.typeCheck(parser)
+	testParserErrors(t, parser, 0)
+
+	variable, ok := parser.scope.Find("sqrt")
+	if !ok {
+		t.Fatal("Expected 'sqrt' to be declared")
+	}
+	function, ok := variable.Typing.(Function)
+	if !ok || !function.Host || function.Async {
+		t.Fatalf("Expected sync host function, got %#v", variable.Typing)
+	}
+	if declaration.Path() != "Math.sqrt" {
+		t.Fatalf("Expected path 'Math.sqrt', got '%v'", declaration.Path())
+	}
+}
+
+func TestExternCallArguments(t *testing.T) {
+	source := "extern sqrt :: (number) -> number = \"Math.sqrt\"\n"
+	source += "sqrt(\"4\")"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, CannotAssignType)
+}
+
+func TestExternValue(t *testing.T) {
+	source := "extern pi :: number = \"Math.PI\"\n"
+	source += "pi * 2"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+}
+
+func TestAsyncExternNotFunction(t *testing.T) {
+	_, errors := Parse(strings.NewReader("extern pi :: async number = \"Math.PI\""))
+	testErrorKinds(t, errors, FunctionTypeExpected)
+}
+
+func TestExternInvalidName(t *testing.T) {
+	source := "extern parse :: (string) -> number = \"JSON.parse\" from \"json\""
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, InvalidExternName)
+}
+
+func TestExternResultNotStringError(t *testing.T) {
+	source := "extern parse :: (string) -> number!number = \"JSON.parse\""
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, HostErrorType)
+}
+
+func TestExternStaticMember(t *testing.T) {
+	source := "extern (List).max :: number = \"Number.MAX_SAFE_INTEGER\""
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	declaration := statements[0].(*ExternDeclaration)
+	if !declaration.IsStatic() {
+		t.Fatal("Expected member to be bound to a global")
+	}
+}
+
+func TestExternInBlock(t *testing.T) {
+	source := "f :: () => {\n"
+	source += "    extern log :: (string) -> () = \"console.log\"\n"
+	source += "    log(\"hello\")\n"
+	source += "}\n"
+	source += "io.log(f)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, IllegalExtern)
+}
@@ -0,0 +1,52 @@
+package parser
+
+// Check if a list of statements always leaves the enclosing block,
+// through 'return', 'throw', 'break' or 'continue'.
+// Subsequent code in the enclosing block is then unreachable.
+func alwaysExits(statements []Node) bool {
+	for _, statement := range statements {
+		if Exits(statement) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check if a statement always leaves the enclosing block
+func Exits(node Node) bool {
+	switch node := node.(type) {
+	case *Exit:
+		return true
+	case *Block:
+		return alwaysExits(node.Statements)
+	case *TaskGroup:
+		return node.Body != nil && alwaysExits(node.Body.Statements)
+	case *IfExpression:
+		if node.Body == nil || node.Alternate == nil {
+			return false
+		}
+		return Exits(node.Body) && Exits(node.Alternate)
+	case *MatchExpression:
+		if len(node.Cases) == 0 {
+			return false
+		}
+		for _, c := range node.Cases {
+			if !alwaysExits(c.Statements) {
+				return false
+			}
+		}
+		return true
+	case *SelectExpression:
+		if len(node.Cases) == 0 {
+			return false
+		}
+		for _, c := range node.Cases {
+			if !alwaysExits(c.Statements) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
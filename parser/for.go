@@ -1,6 +1,16 @@
 package parser
 
+// Loops can be labeled, so that nested loops can exit them, like in:
+//
+//	outer: for row in rows {
+//	    for cell in row {
+//	        if cell == target {
+//	            break outer cell
+//	        }
+//	    }
+//	}
 type ForExpression struct {
+	Label   *Identifier // nil if the loop is not labeled
 	Keyword Token
 	Expr    Expression // Expression (boolean)
 	Body    *Block
@@ -19,8 +29,13 @@ func (f *ForExpression) getChildren() []Node {
 }
 
 func (f *ForExpression) typeCheck(p *Parser) {
-	p.pushScope(NewScope(LoopScope))
+	scope := NewScope(LoopScope)
+	if f.Label != nil {
+		scope.label = f.Label.Text()
+	}
+	p.pushScope(scope)
 	defer p.dropScope()
+	widenLoopAssignments(p, f)
 
 	binary, ok := f.Expr.(*BinaryExpression)
 	if ok && binary.Operator.Kind() == InKeyword {
@@ -29,42 +44,38 @@ func (f *ForExpression) typeCheck(p *Parser) {
 		typeCheckForExpression(p, f.Expr)
 	}
 	f.Body.typeCheck(p)
-	f.typing = getLoopType(p, f.Body)
+	f.typing = getLoopType(p, f)
 }
 func typeCheckForInExpression(p *Parser, expr *BinaryExpression) {
 	var el ExpressionType = Unknown{}
 	if expr.Right != nil {
 		expr.Right.typeCheck(p)
-		checkExplicitRange(p, expr.Right)
-		el = getIteratedElementType(expr.Right.Type())
+		el = getIteratedElementType(p, expr.Right.Type())
 		if el == (Unknown{}) {
 			p.error(expr.Right, IterableExpected, expr.Right.Type())
 		}
 	}
 	switch pattern := expr.Left.(type) {
 	case *Identifier:
-		p.scope.Add(pattern.Text(), pattern.Loc(), el)
+		p.scope.bind(pattern, pattern.Loc(), el)
 	case *TupleExpression:
+		if !allowsTuplePattern(expr.Right.Type()) {
+			p.error(pattern, InvalidPattern)
+		}
+		var first, second ExpressionType = el, Number{}
+		if m, ok := getMapTypes(expr.Right.Type()); ok {
+			first, second = m.Key, m.Value
+		}
 		element := pattern.Elements[0].(*Identifier)
 		if element != nil {
-			p.scope.Add(element.Text(), element.Loc(), el)
+			p.scope.bind(element, element.Loc(), first)
 		}
 		index := pattern.Elements[1].(*Identifier)
 		if index != nil {
-			p.scope.Add(index.Text(), index.Loc(), Number{})
+			p.scope.bind(index, index.Loc(), second)
 		}
 	}
 }
-func checkExplicitRange(p *Parser, expr Expression) {
-	_, ok := expr.Type().(Range)
-	if !ok {
-		return
-	}
-	_, ok = Unwrap(expr).(*RangeExpression)
-	if !ok {
-		p.error(expr, RangeExpected)
-	}
-}
 func typeCheckForExpression(p *Parser, expr Expression) {
 	if expr == nil {
 		return
@@ -76,6 +87,9 @@ func typeCheckForExpression(p *Parser, expr Expression) {
 
 func (f *ForExpression) Loc() Loc {
 	loc := f.Keyword.Loc()
+	if f.Label != nil {
+		loc.Start = f.Label.Loc().Start
+	}
 	if f.Body != nil {
 		loc.End = f.Body.Loc().End
 	} else if f.Expr != nil {
@@ -86,14 +100,34 @@ func (f *ForExpression) Loc() Loc {
 func (f *ForExpression) Type() ExpressionType { return f.typing }
 
 func (p *Parser) parseForExpression() *ForExpression {
-	p.pushScope(NewScope(LoopScope))
+	return parseLabeledLoop(p, nil)
+}
+
+// Parse a loop like 'label: for ...', from the 'for' keyword
+func parseLabeledLoop(p *Parser, label *Identifier) *ForExpression {
+	scope := NewScope(LoopScope)
+	if label != nil {
+		if _, ok := p.scope.findLabel(label.Text()); ok {
+			p.error(label, DuplicateIdentifier, label.Text())
+		}
+		scope.label = label.Text()
+	}
+	p.pushScope(scope)
 	defer p.dropScope()
 
 	keyword := p.Consume()
 	expr := parseInExpression(p)
 	body := p.parseBlock()
 
-	return &ForExpression{keyword, expr, body, nil}
+	if label != nil && !scope.labelUsed {
+		p.error(label, UnusedLabel, label.Text())
+	}
+	return &ForExpression{
+		Label:   label,
+		Keyword: keyword,
+		Expr:    expr,
+		Body:    body,
+	}
 }
 
 func parseInExpression(p *Parser) Expression {
@@ -127,6 +161,15 @@ func validateInExpression(p *Parser, expr *BinaryExpression) {
 	case nil, *Identifier:
 	case *TupleExpression:
 		expr.Left = getValidatedForInTuple(p, left)
+	case *ParenthesizedExpression:
+		// like 'for (key, value) in map'
+		tuple, ok := left.Expr.(*TupleExpression)
+		if !ok {
+			p.error(expr.Left, InvalidPattern)
+			expr.Left = nil
+			return
+		}
+		expr.Left = getValidatedForInTuple(p, tuple)
 	default:
 		p.error(expr.Left, InvalidPattern)
 		expr.Left = nil
@@ -149,54 +192,147 @@ func getValidatedForInTuple(p *Parser, tuple *TupleExpression) *TupleExpression
 	return &TupleExpression{Elements: []Expression{index, value}}
 }
 
-func getLoopType(p *Parser, body *Block) ExpressionType {
-	breaks := findBreakStatements(body)
+func getLoopType(p *Parser, loop *ForExpression) ExpressionType {
+	breaks := findBreakStatements(loop)
+	for _, b := range breaks {
+		b.loop = loop
+	}
 	if len(breaks) == 0 {
 		return Nil{}
 	}
-	var t ExpressionType
-	if breaks[0].Value != nil {
-		t = breaks[0].Value.Type()
-	} else {
-		t = Nil{}
-	}
+	t := getExitType(breaks[0])
 	for _, b := range breaks[1:] {
+		received := getExitType(b)
 		if t == (Nil{}) && b.Value != nil {
-			p.error(b.Value, CannotAssignType, t, b.Value.Type())
+			p.error(b.Value, CannotAssignType, t, received)
 		}
-		if t != (Nil{}) && !t.Extends(b.Value.Type()) {
-			p.error(b.Value, CannotAssignType, t, b.Value.Type())
+		if t != (Nil{}) && !t.Extends(received) {
+			p.error(b, CannotAssignType, t, received)
 		}
 	}
 	return makeOptionType(t)
 }
 
-func findBreakStatements(body *Block) []*Exit {
+// Find the break statements exiting the loop: breaks without label outside
+// of nested loops, and breaks with the loop's label.
+func findBreakStatements(loop *ForExpression) []*Exit {
 	results := []*Exit{}
-	Walk(body, func(n Node, skip func()) {
+	Walk(loop.Body, func(n Node, skip func()) {
 		if isFunctionExpression(n) || isForExpression(n) {
 			skip()
 		}
-		if n, ok := n.(*Exit); ok && n.Operator.Kind() == BreakKeyword {
+		if n, ok := n.(*Exit); ok && n.Operator.Kind() == BreakKeyword && n.Label == nil {
+			results = append(results, n)
+		}
+	})
+	if loop.Label == nil {
+		return results
+	}
+	Walk(loop.Body, func(n Node, skip func()) {
+		if isFunctionExpression(n) {
+			skip()
+		}
+		if n, ok := n.(*Exit); ok && n.Operator.Kind() == BreakKeyword &&
+			n.Label != nil && n.Label.Text() == loop.Label.Text() {
 			results = append(results, n)
 		}
 	})
 	return results
 }
 
+// Check if a loop iterates over an async generator
+func isAsyncLoop(f *ForExpression) bool {
+	binary, ok := f.Expr.(*BinaryExpression)
+	if !ok || binary.Operator.Kind() != InKeyword || binary.Right == nil {
+		return false
+	}
+	return IsAsyncIterable(binary.Right.Type())
+}
+
 func isForExpression(n Node) bool {
 	_, ok := n.(*ForExpression)
 	return ok
 }
 
 // t might be a List or a Ref to a List.
-// Return the type iterated on in a loop
-func getIteratedElementType(t ExpressionType) ExpressionType {
+// Return the type iterated on in a loop.
+// Maps are iterated on as (key, value) tuples.
+// Types implementing the Iterator trait are iterated on by calling their
+// 'next' method until it returns None.
+func getIteratedElementType(p *Parser, t ExpressionType) ExpressionType {
 	switch t := t.(type) {
 	case List:
 		return t.Element
 	case Range:
 		return t.operands
+	case String:
+		return String{}
+	}
+	if m, ok := getMapTypes(t); ok {
+		return Tuple{[]ExpressionType{m.Key, m.Value}}
+	}
+	if alias, ok := t.(TypeAlias); ok && alias.Name == "Set" && len(alias.Params) == 1 {
+		return alias.Params[0].Value
+	}
+	if isGenerator(t) {
+		return t.(TypeAlias).Params[0].Value
+	}
+	// values of a trait type, like 'Iterator[number]'
+	if trait, ok := getTrait(t); ok {
+		return getNextElementType(trait.Members["next"])
+	}
+	if alias, ok := t.(TypeAlias); ok {
+		return getIteratorElementType(p, alias)
 	}
 	return Unknown{}
 }
+
+// Get the type returned by the 'next' method of an iterator, without its
+// option, or Unknown if the type is not an iterator
+func getIteratorElementType(p *Parser, t TypeAlias) ExpressionType {
+	args := make([]ExpressionType, len(t.Params))
+	for i, param := range t.Params {
+		args[i] = param.Value
+	}
+	method, _ := getDeclaredMethod(p, t.Name, "next", args)
+	if method == nil {
+		method, _ = t.method("next")
+	}
+	return getNextElementType(method)
+}
+
+// Get the type returned by a 'next' method, without its option, or Unknown
+// if the method cannot be used to iterate
+func getNextElementType(method ExpressionType) ExpressionType {
+	function, ok := method.(Function)
+	if !ok || function.Params != nil && len(function.Params.Elements) > 0 {
+		return Unknown{}
+	}
+	element := getOptionPayload(function.Returned)
+	if element == nil {
+		return Unknown{}
+	}
+	return element
+}
+
+// Check if a pattern like 'el, i' can be used while iterating over t.
+// Sets' and iterators' elements have no index, and strings' characters'
+// positions are not indexes, since these count UTF-16 units.
+func allowsTuplePattern(t ExpressionType) bool {
+	switch t := t.(type) {
+	case String:
+		return false
+	case TypeAlias:
+		return t.Name == "Map"
+	}
+	return true
+}
+
+// Get the key and value types of a map
+func getMapTypes(t ExpressionType) (Map, bool) {
+	alias, ok := t.(TypeAlias)
+	if !ok || alias.Name != "Map" || len(alias.Params) != 2 {
+		return Map{}, false
+	}
+	return Map{alias.Params[0].Value, alias.Params[1].Value}, true
+}
@@ -55,6 +55,19 @@ func TestParseForInTupleExpression(t *testing.T) {
 	}
 }
 
+func TestParseForInParenthesizedTuple(t *testing.T) {
+	parser := MakeParser(strings.NewReader("for (key, value) in m { 42 }"))
+	expr := parser.parseForExpression()
+
+	if len(parser.errors) != 0 {
+		t.Fatalf("Expected no errors, got %#v", parser.errors)
+	}
+	left := expr.Expr.(*BinaryExpression).Left
+	if _, ok := left.(*TupleExpression); !ok {
+		t.Fatalf("Expected tuple pattern, got %#v", left)
+	}
+}
+
 func TestParseForInTupleTooMany(t *testing.T) {
 	parser := MakeParser(strings.NewReader("for el, i, extra in array { 42 }"))
 	parser.parseForExpression()
@@ -190,7 +203,7 @@ func TestCheckForInWithTuple(t *testing.T) {
 
 func TestCheckForInBadType(t *testing.T) {
 	parser := MakeParser(nil)
-	parser.scope.Add("bad", Loc{}, Ref{Number{}})
+	parser.scope.Add("bad", Loc{}, Ref{To: Number{}})
 	expr := &ForExpression{
 		Keyword: token{kind: ForKeyword},
 		Expr: &BinaryExpression{
@@ -215,3 +228,206 @@ func TestCheckForInBadType(t *testing.T) {
 		t.Fatalf("Expected 'el' to be unknown, got '%v'", v.Typing.Text())
 	}
 }
+
+func TestCheckForInMap(t *testing.T) {
+	parser := MakeParser(nil)
+	parser.scope.Add("m", Loc{}, makeMapType(String{}, Number{}))
+	expr := &ForExpression{
+		Keyword: token{kind: ForKeyword},
+		Expr: &BinaryExpression{
+			Left: &TupleExpression{Elements: []Expression{
+				&Identifier{Token: &literal{kind: Name, value: "key"}},
+				&Identifier{Token: &literal{kind: Name, value: "value"}},
+			}},
+			Right:    &Identifier{Token: &literal{kind: Name, value: "m"}},
+			Operator: token{kind: InKeyword},
+		},
+		Body: &Block{Statements: []Node{
+			&Identifier{Token: &literal{kind: Name, value: "key"}},
+			&Identifier{Token: &literal{kind: Name, value: "value"}},
+		}},
+	}
+	expr.typeCheck(parser)
+	if len(parser.errors) != 0 {
+		t.Fatalf("Expected no errors, got %#v", parser.errors)
+	}
+
+	key, _ := expr.Body.scope.Find("key")
+	if _, ok := key.Typing.(String); !ok {
+		t.Fatalf("Expected 'key' to be a string, got %v", key.Typing.Text())
+	}
+	value, _ := expr.Body.scope.Find("value")
+	if _, ok := value.Typing.(Number); !ok {
+		t.Fatalf("Expected 'value' to be a number, got %v", value.Typing.Text())
+	}
+}
+
+func TestCheckForInMapEntry(t *testing.T) {
+	source := "m := Map{\"a\": 1}\n"
+	source += "for entry in m { entry }"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	body := statements[1].(*ForExpression).Body
+	entry, _ := body.scope.Find("entry")
+	if entry.Typing.Text() != "(string, number)" {
+		t.Fatalf("Expected (string, number), got %v", entry.Typing.Text())
+	}
+}
+
+func TestCheckForInString(t *testing.T) {
+	statements, errors := Parse(strings.NewReader("for c in \"abc\" { c }"))
+	testErrorKinds(t, errors)
+
+	c, _ := statements[0].(*ForExpression).Body.scope.Find("c")
+	if _, ok := c.Typing.(String); !ok {
+		t.Fatalf("Expected 'c' to be a string, got %v", c.Typing.Text())
+	}
+}
+
+func TestCheckForInStringTuple(t *testing.T) {
+	_, errors := Parse(strings.NewReader("for c, i in \"abc\" { c, i }"))
+	testErrorKinds(t, errors, InvalidPattern)
+}
+
+func TestCheckForInSet(t *testing.T) {
+	source := "set := Set{1}\n"
+	source += "for x in set { x }"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	x, _ := statements[1].(*ForExpression).Body.scope.Find("x")
+	if _, ok := x.Typing.(Number); !ok {
+		t.Fatalf("Expected 'x' to be a number, got %v", x.Typing.Text())
+	}
+}
+
+func TestCheckForInSetTuple(t *testing.T) {
+	source := "set := Set{1}\n"
+	source += "for x, i in set { x, i }"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, InvalidPattern)
+}
+
+func TestCheckForInIterator(t *testing.T) {
+	source := "Cursor[Type] :: {\n"
+	source += "    list []Type\n"
+	source += "    index number\n"
+	source += "}\n"
+	source += "(c mut Cursor).next :: () => ?Type {\n"
+	source += "    c.index += 1\n"
+	source += "    c.list.get(c.index - 1)\n"
+	source += "}\n"
+	source += "cursor := Cursor[string]{list: []string{\"a\"}, index: 0}\n"
+	source += "for x in cursor { x }"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	x, _ := statements[3].(*ForExpression).Body.scope.Find("x")
+	if _, ok := x.Typing.(String); !ok {
+		t.Fatalf("Expected 'x' to be a string, got %v", x.Typing.Text())
+	}
+}
+
+func TestCheckForInIteratorTuple(t *testing.T) {
+	source := "Counter :: {\n"
+	source += "    list []number\n"
+	source += "}\n"
+	source += "(c mut Counter).next :: () => ?number { c.list.pop() }\n"
+	source += "counter := Counter{list: []number{}}\n"
+	source += "for x, i in counter { x, i }"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, InvalidPattern)
+}
+
+func TestCheckForInIteratorTrait(t *testing.T) {
+	source := "f :: (it Iterator[number]) => {\n"
+	source += "    for x in it { x }\n"
+	source += "}"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	function := statements[0].(*Assignment).Value.(*FunctionExpression)
+	loop := function.Body.Statements[0].(*ForExpression)
+	x, _ := loop.Body.scope.Find("x")
+	if _, ok := x.Typing.(Number); !ok {
+		t.Fatalf("Expected 'x' to be a number, got %v", x.Typing.Text())
+	}
+}
+
+func TestCheckIteratorTraitArg(t *testing.T) {
+	source := "Counter :: {\n"
+	source += "    list []number\n"
+	source += "}\n"
+	source += "(c mut Counter).next :: () => ?number { c.list.pop() }\n"
+	source += "f :: (it Iterator[number]) => { io.log(it) }\n"
+	source += "f(Counter{list: []number{}})"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	source = strings.Replace(source, "Iterator[number]", "Iterator[string]", 1)
+	_, errors = Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, CannotAssignType)
+}
+
+func TestCheckForInNonIterator(t *testing.T) {
+	source := "Counter :: {\n"
+	source += "    count number\n"
+	source += "}\n"
+	source += "(c Counter).next :: () => number { c.count }\n"
+	source += "counter := Counter{count: 0}\n"
+	source += "for x in counter { x }"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, IterableExpected)
+}
+
+func TestCheckLabeledLoopType(t *testing.T) {
+	source := "found := outer: for i in 0..3 {\n"
+	source += "    for j in 0..3 {\n"
+	source += "        if i == j {\n"
+	source += "            break outer i\n"
+	source += "        }\n"
+	source += "        break\n"
+	source += "    }\n"
+	source += "}"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	loop := statements[0].(*Assignment).Value.(*ForExpression)
+	if loop.Label == nil || loop.Label.Text() != "outer" {
+		t.Fatal("Expected loop to be labeled 'outer'")
+	}
+	if text := loop.Type().Text(); text != "?[number]" {
+		t.Fatalf("Expected ?[number], got %v", text)
+	}
+}
+
+func TestCheckLabeledLoopBreakTypes(t *testing.T) {
+	source := "outer: for {\n"
+	source += "    for {\n"
+	source += "        break outer 1\n"
+	source += "    }\n"
+	source += "    break outer \"a\"\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, CannotAssignType)
+}
+
+func TestCheckUnusedLabel(t *testing.T) {
+	source := "outer: for {\n"
+	source += "    break\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, UnusedLabel)
+}
+
+func TestCheckDuplicateLabel(t *testing.T) {
+	source := "outer: for {\n"
+	source += "    outer: for {\n"
+	source += "        continue outer\n"
+	source += "    }\n"
+	source += "    break outer\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, DuplicateIdentifier)
+}
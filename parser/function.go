@@ -8,6 +8,7 @@ type FunctionExpression struct {
 	Body       *Block
 	typing     Function
 	canBeAsync bool
+	generator  ExpressionType // type of the returned generator, if the function yields
 }
 
 func (f *FunctionExpression) getChildren() []Node {
@@ -40,9 +41,12 @@ func (f *FunctionExpression) Type() ExpressionType { return f.typing }
 
 func (f *FunctionExpression) typeCheck(p *Parser) {
 	typeCheckFunctionExpression(p, f, func(params *TupleExpression) {
-		for _, param := range params.Elements {
-			if _, ok := param.(*Param); !ok {
-				p.error(param, ParameterExpected)
+		for _, element := range params.Elements {
+			param, ok := element.(*Param)
+			if !ok {
+				p.error(element, ParameterExpected)
+			} else if param.Complement != nil {
+				param.Complement.typeCheck(p)
 			}
 		}
 		addParamsToScope(p, params.Elements)
@@ -58,18 +62,31 @@ func typeCheckFunctionExpression(p *Parser, f *FunctionExpression, paramHandler
 	if f.Params != nil && f.Params.Expr != nil {
 		paramHandler(f.Params.Expr.(*TupleExpression))
 	}
+	if f.Explicit != nil {
+		f.Explicit.typeCheck(p)
+	}
 	f.Body.typeCheck(p)
+	reportEscapingResult(p, f.Body)
 
-	if f.Explicit != nil {
-		typeCheckExplicitReturn(p, f)
+	graph := buildFlowGraph(p, f.Body)
+	graph.reportUseBeforeAssign(p)
+	f.canBeAsync = containsAsync(f)
+	f.generator = nil
+	if yields := findYieldStatements(f.Body); len(yields) > 0 {
+		f.generator = typeCheckGenerator(p, f, yields)
+	} else if f.Explicit != nil {
+		typeCheckExplicitReturn(p, f, graph)
 	} else {
 		typeCheckImplicitReturn(p, f)
 	}
 
-	f.canBeAsync = containsAsync(f)
 	f.typing = getFunctionType(f)
 }
 
+// Check if the function is a generator, because it contains 'yield'
+// statements
+func (f *FunctionExpression) IsGenerator() bool { return f.generator != nil }
+
 func typeCheckHOF(p *Parser, f *FunctionExpression, expected Tuple) {
 	typeCheckFunctionExpression(p, f, func(params *TupleExpression) {
 		l := checkHOFParamsLength(p, expected, params)
@@ -116,32 +133,37 @@ func addHOFParamToScope(p *Parser, param Expression, expected ExpressionType) {
 	case *Param:
 		addParamToScope(p, param)
 	case *Identifier:
-		p.scope.Add(param.Text(), param.Loc(), expected)
+		p.scope.bind(param, param.Loc(), expected)
+		param.typing = expected
+		bindParamRef(param)
 	default:
 		panic("param or identifier expected")
 	}
 }
 
+// Generators are not async functions: async generators are created
+// synchronously, and their values are awaited
 func getFunctionType(f *FunctionExpression) Function {
 	returned := getFunctionReturnedType(f)
+	async := f.canBeAsync
+	if f.generator != nil {
+		returned, async = f.generator, false
+	}
 	typeParams := []Generic{}
 	if f.TypeParams != nil {
 		typeParams = f.TypeParams.getGenerics()
 	}
 	params := getFunctionParamsType(f)
-	return Function{typeParams, &params, returned, f.canBeAsync}
+	return Function{TypeParams: typeParams, Params: &params, Returned: returned, Async: async}
 }
 
 func getFunctionParamsType(f *FunctionExpression) Tuple {
-	if len(f.Params.Expr.(*TupleExpression).Elements) == 0 {
-		return Tuple{[]ExpressionType{}}
-	}
-	t := f.Params.Type()
-	if tu, ok := t.(Tuple); ok {
-		return tu
-	} else {
-		return Tuple{[]ExpressionType{t}}
+	elements := f.Params.Expr.(*TupleExpression).Elements
+	params := make([]ExpressionType, len(elements))
+	for i, element := range elements {
+		params[i] = element.Type()
 	}
+	return Tuple{params}
 }
 
 func getFunctionReturnedType(f *FunctionExpression) ExpressionType {
@@ -179,7 +201,7 @@ func (f *FunctionTypeExpression) Loc() Loc {
 		start = f.Expr.Loc().Start
 	}
 	if f.Expr != nil {
-		end = f.Loc().End
+		end = f.Expr.Loc().End
 	} else if f.Params != nil {
 		end = f.Params.Loc().End
 	} else {
@@ -199,7 +221,9 @@ func (f *FunctionTypeExpression) Type() ExpressionType {
 		p.Elements[i] = t.Value
 	}
 	var ret ExpressionType = Unknown{}
-	if f.Expr != nil {
+	if isNilReturn(f.Expr) {
+		ret = Nil{}
+	} else if f.Expr != nil {
 		t, ok := f.Expr.Type().(Type)
 		if ok {
 			ret = t.Value
@@ -220,14 +244,21 @@ func (f *FunctionTypeExpression) typeCheck(p *Parser) {
 		}
 	}
 
-	if f.Expr == nil {
+	if f.Expr == nil || isNilReturn(f.Expr) {
 		return
 	}
+	f.Expr.typeCheck(p)
 	if _, ok := f.Expr.Type().(Type); !ok {
 		p.error(f.Expr, TypeExpected)
 	}
 }
 
+// Empty parentheses denote functions returning nothing, like '(string) -> ()'
+func isNilReturn(expr Expression) bool {
+	paren, ok := expr.(*ParenthesizedExpression)
+	return ok && paren.Expr == nil
+}
+
 func (p *Parser) parseFunctionExpression(typeParams *BracketedExpression) Expression {
 	p.pushScope(NewScope(FunctionScope))
 	defer p.dropScope()
@@ -299,33 +330,54 @@ func validateFunctionParam(p *Parser, expr Expression) {
 
 // Type check all possible return points against the explicit return type.
 // Also check possible failure points.
-func typeCheckExplicitReturn(p *Parser, f *FunctionExpression) {
+func typeCheckExplicitReturn(p *Parser, f *FunctionExpression, graph *flowGraph) {
 	t, ok := f.Explicit.Type().(Type)
 	if !ok {
 		p.error(f.Explicit, TypeExpected)
 		return
 	}
 
-	typeCheckHappyReturn(p, f.Body, t.Value)
-
-	err := getErrorType(t.Value)
-	if err == nil {
-		return
+	if graph.fallsThrough() && !producesValue(f.Body) && !t.Value.Extends(Nil{}) {
+		p.error(f.Explicit, MissingReturn)
+	} else {
+		typeCheckHappyReturn(p, f.Body, t.Value, graph.fallsThrough())
 	}
+
 	tries := findTryExpressions(f.Body)
-	for _, t := range tries {
-		if !err.Extends(getErrorType(t.Operand.Type())) {
-			p.error(t.Operand, CannotAssignType, err, t.Operand.Type())
-		}
+	for _, try := range tries {
+		typeCheckPropagation(p, try, t.Value)
 	}
+	err := getErrorType(t.Value)
 	throws := findThrowStatements(f.Body)
 	for _, t := range throws {
-		if t.Value != nil && !err.Extends(t.Value.Type()) {
+		if err == nil {
+			p.error(t, IllegalThrow)
+		} else if t.Value != nil && !err.Extends(t.Value.Type()) {
 			p.error(t.Value, CannotAssignType, err, t.Value)
 		}
 	}
 }
 
+// Check that a try expression can propagate its failure to the function.
+// Options propagate 'None' from functions returning options,
+// results propagate their error from functions returning results.
+func typeCheckPropagation(p *Parser, try *UnaryExpression, returned ExpressionType) {
+	operand := try.Operand.Type()
+	switch {
+	case isOption(operand):
+		if !isOption(returned) {
+			p.error(try, IllegalPropagation, operand, returned)
+		}
+	case isResult(operand):
+		err := getErrorType(returned)
+		if err == nil {
+			p.error(try, IllegalPropagation, operand, returned)
+		} else if !err.Extends(getErrorType(operand)) {
+			p.error(try.Operand, CannotAssignType, err, operand)
+		}
+	}
+}
+
 // Type check all possible return points and see if they match
 func typeCheckImplicitReturn(p *Parser, f *FunctionExpression) {
 	returns := findReturnStatements(f.Body)
@@ -343,12 +395,28 @@ func typeCheckImplicitReturn(p *Parser, f *FunctionExpression) {
 	}
 }
 
+// Check if the last statement of a block can be used as its value
+func producesValue(b *Block) bool {
+	if len(b.Statements) == 0 {
+		return false
+	}
+	switch b.Statements[len(b.Statements)-1].(type) {
+	case *Assignment, *Exit, *Param:
+		return false
+	default:
+		return true
+	}
+}
+
 // Check all return points in a function body against an expected typing.
-func typeCheckHappyReturn(p *Parser, body *Block, expected ExpressionType) bool {
+// The last expression is checked only if the end of the body is reachable.
+func typeCheckHappyReturn(p *Parser, body *Block, expected ExpressionType, fallsThrough bool) bool {
 	happy := getHappyType(expected)
 	returns := findReturnStatements(body)
 	ok := true
-	if !expected.Extends(body.Type()) && !happy.Extends(body.Type()) {
+	if !fallsThrough {
+		// the last expression is never returned
+	} else if !expected.Extends(body.Type()) && !happy.Extends(body.Type()) {
 		p.error(body.reportedNode(), CannotAssignType, happy, body.Type())
 	}
 	for _, r := range returns {
@@ -438,16 +506,17 @@ func addParamToScope(p *Parser, expr Expression) {
 	}
 	if param.Complement == nil {
 		p.error(param, TypeExpected)
-		p.scope.Add(param.Identifier.Text(), param.Loc(), Unknown{})
+		p.scope.bind(param.Identifier, param.Loc(), Unknown{})
 		return
 	}
 	if _, ok := param.Complement.Type().(Type); !ok {
 		p.error(param, TypeExpected)
-		p.scope.Add(param.Identifier.Text(), param.Loc(), Unknown{})
+		p.scope.bind(param.Identifier, param.Loc(), Unknown{})
 		return
 	}
 	typing, _ := param.Complement.Type().(Type)
-	p.scope.Add(param.Identifier.Text(), param.Loc(), typing.Value)
+	p.scope.bind(param.Identifier, param.Loc(), typing.Value)
+	bindParamRef(param.Identifier)
 }
 
 func containsAsync(f *FunctionExpression) bool {
@@ -456,6 +525,18 @@ func containsAsync(f *FunctionExpression) bool {
 		if async || isFunctionExpression(n) {
 			skip()
 		}
+		if loop, ok := n.(*ForExpression); ok && isAsyncLoop(loop) {
+			async = true
+			skip()
+		}
+		if u, ok := n.(*UnaryExpression); ok && u.Operator.Kind() == AwaitKeyword {
+			async = true
+			skip()
+		}
+		if _, ok := n.(*TaskGroup); ok {
+			async = true
+			skip()
+		}
 		expr, ok := n.(Expression)
 		if !ok {
 			return
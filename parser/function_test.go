@@ -190,6 +190,15 @@ func TestCheckFunctionExpressionParams(t *testing.T) {
 	}
 }
 
+func TestCheckFunctionParamsType(t *testing.T) {
+	source := "f :: (a number, b boolean) => number {\n"
+	source += "    if b { a } else { 0 }\n"
+	source += "}\n"
+	source += "f(1, 2)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, CannotAssignType)
+}
+
 func TestCheckFunctionExpressionBadParam(t *testing.T) {
 	parser := MakeParser(nil)
 	expr := &FunctionExpression{
@@ -264,11 +273,10 @@ func TestCheckImplicitReturnBadReturns(t *testing.T) {
 	}
 	expr.typeCheck(parser)
 
-	// expect 2 errors for if expression types
 	// expect 1 error for early return
 	// expect 1 error for try with implicit return type
 	// expect 1 error for throw with implicit return type
-	testParserErrors(t, parser, 5)
+	testParserErrors(t, parser, 3)
 }
 
 func TestCheckExplicitReturn(t *testing.T) {
@@ -453,3 +461,21 @@ func TestCheckAsyncFunctionExpression(t *testing.T) {
 		t.Fatalf("Expected function to be async, got %#v", parser.errors)
 	}
 }
+
+func TestParseListParam(t *testing.T) {
+	source := "f :: (list []number) => { list[0] }"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+}
+
+func TestParseFunctionParam(t *testing.T) {
+	source := "f :: (g (number) -> number) => { g(1) }"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	function := statements[0].(*Assignment).Value.(*FunctionExpression)
+	param := function.Params.Expr.(*TupleExpression).Elements[0].(*Param)
+	if _, ok := param.Complement.(*FunctionTypeExpression); !ok {
+		t.Fatalf("Expected function type, got %#v", param.Complement)
+	}
+}
@@ -0,0 +1,120 @@
+package parser
+
+// Functions containing 'yield' statements are generators: calling them
+// returns a lazy sequence of the yielded values, which can be iterated on.
+//
+//	count :: (n number) => {
+//	    for i in 0..n {
+//	        yield i
+//	    }
+//	}
+//	for i in count(3) {} // 0, 1, 2
+//
+// Generators awaiting async calls are async generators. Iterating over
+// their values is async too.
+// The last expression of a generator is not returned, and 'return' stops
+// the generator without a value.
+
+// Parse a statement like 'yield value'
+func (p *Parser) parseYield() *UnaryExpression {
+	keyword := p.Consume()
+	var value Expression
+	if p.Peek().Kind() == EOL || p.Peek().Kind() == RightBrace {
+		p.error(&Literal{p.Peek()}, ExpressionExpected)
+	} else {
+		value = p.parseExpression()
+	}
+	statement := &UnaryExpression{Operator: keyword, Operand: value}
+	if !p.scope.in(FunctionScope) {
+		p.error(statement, IllegalYield)
+	}
+	return statement
+}
+
+// Find all the yield statements in a function body.
+// Don't check inside nested functions.
+func findYieldStatements(body *Block) []*UnaryExpression {
+	results := []*UnaryExpression{}
+	Walk(body, func(n Node, skip func()) {
+		if isFunctionExpression(n) {
+			skip()
+		}
+		if n, ok := n.(*UnaryExpression); ok && n.Operator.Kind() == YieldKeyword {
+			results = append(results, n)
+		}
+	})
+	return results
+}
+
+// Check a generator's body against its explicit type, if any, and get the
+// type of the generator.
+// Without explicit type, the first yielded value gives the elements' type.
+func typeCheckGenerator(p *Parser, f *FunctionExpression, yields []*UnaryExpression) ExpressionType {
+	inferred := makeGeneratorType(getYieldedType(yields[0]), f.canBeAsync)
+	generator := inferred
+	if f.Explicit != nil {
+		if t, ok := f.Explicit.Type().(Type); !ok {
+			p.error(f.Explicit, TypeExpected)
+		} else if explicit, ok := t.Value.(TypeAlias); ok && isGenerator(explicit) {
+			generator = explicit
+		} else {
+			p.error(f.Explicit, CannotAssignType, t.Value, inferred)
+		}
+	}
+	if generator.Name != inferred.Name {
+		p.error(f.Explicit, CannotAssignType, generator, inferred)
+	}
+
+	element := generator.Params[0].Value
+	if element == nil {
+		element = inferred.Params[0].Value
+	}
+	for _, y := range yields {
+		if y.Operand != nil && !element.Extends(y.Operand.Type()) {
+			p.error(y.Operand, CannotAssignType, element, y.Operand.Type())
+		}
+	}
+	for _, r := range findReturnStatements(f.Body) {
+		if r.Value != nil {
+			p.error(r.Value, UnexpectedExpression)
+		}
+	}
+	for _, t := range findTryExpressions(f.Body) {
+		p.error(t, IllegalResult)
+	}
+	for _, t := range findThrowStatements(f.Body) {
+		p.error(t, IllegalThrow)
+	}
+	return generator
+}
+
+func getYieldedType(y *UnaryExpression) ExpressionType {
+	if y.Operand == nil {
+		return Unknown{}
+	}
+	return y.Operand.Type()
+}
+
+// utility to create generator types
+func makeGeneratorType(element ExpressionType, async bool) TypeAlias {
+	name := "Generator"
+	if async {
+		name = "AsyncGenerator"
+	}
+	return TypeAlias{
+		Name:   name,
+		Params: []Generic{{Name: "Type", Value: element}},
+		Ref:    newObject(),
+	}
+}
+
+func isGenerator(t ExpressionType) bool {
+	alias, ok := t.(TypeAlias)
+	return ok && (alias.Name == "Generator" || alias.Name == "AsyncGenerator")
+}
+
+// Check if iterating over values of the given type is async
+func IsAsyncIterable(t ExpressionType) bool {
+	alias, ok := t.(TypeAlias)
+	return ok && alias.Name == "AsyncGenerator"
+}
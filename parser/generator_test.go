@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckGenerator(t *testing.T) {
+	source := "count :: (n number) => {\n"
+	source += "    for i in 0..n {\n"
+	source += "        yield i\n"
+	source += "    }\n"
+	source += "}\n"
+	source += "for x in count(3) { x }"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	function := statements[0].(*Assignment).Value.(*FunctionExpression)
+	if !function.IsGenerator() {
+		t.Fatal("Expected function to be a generator")
+	}
+	if text := function.Type().(Function).Returned.Text(); text != "Generator[number]" {
+		t.Fatalf("Expected Generator[number], got %v", text)
+	}
+	x, _ := statements[1].(*ForExpression).Body.scope.Find("x")
+	if _, ok := x.Typing.(Number); !ok {
+		t.Fatalf("Expected 'x' to be a number, got %v", x.Typing.Text())
+	}
+}
+
+func TestCheckAsyncGenerator(t *testing.T) {
+	source := "extern fetch :: async (number) -> string\n"
+	source += "pages :: () => {\n"
+	source += "    yield fetch(1)\n"
+	source += "}\n"
+	source += "read :: () => {\n"
+	source += "    for page in pages() {\n"
+	source += "        io.log(page)\n"
+	source += "    }\n"
+	source += "}"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	generator := statements[1].(*Assignment).Value.(*FunctionExpression).Type().(Function)
+	if generator.Async || generator.Returned.Text() != "AsyncGenerator[string]" {
+		t.Fatalf("Expected sync function returning AsyncGenerator[string], got %#v", generator.Returned.Text())
+	}
+	if !statements[2].(*Assignment).Value.(*FunctionExpression).Type().(Function).Async {
+		t.Fatal("Expected function iterating over an async generator to be async")
+	}
+}
+
+func TestCheckGeneratorExplicitType(t *testing.T) {
+	source := "f :: () => Generator[string] {\n"
+	source += "    yield 1\n"
+	source += "}\n"
+	source += "g :: () => number {\n"
+	source += "    yield 1\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, CannotAssignType, CannotAssignType)
+}
+
+func TestCheckGeneratorExits(t *testing.T) {
+	source := "f :: () => {\n"
+	source += "    yield 1\n"
+	source += "    yield \"a\"\n"
+	source += "    return 2\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, CannotAssignType, UnexpectedExpression)
+}
+
+func TestIllegalYield(t *testing.T) {
+	_, errors := Parse(strings.NewReader("yield 1"))
+	testErrorKinds(t, errors, IllegalYield)
+}
@@ -0,0 +1,130 @@
+package parser
+
+// Task groups scope the async calls started in them:
+//
+//	user, posts := async {
+//	    user := async getUser(id)
+//	    posts := async getPosts(id)
+//	    (await user, await posts)
+//	}
+//
+// Leaving a group waits for the calls that were not awaited yet.
+// Leaving it early, with 'return', 'break' or 'throw', cancels them instead.
+// Cancellation goes through the group's signal: functions taking a Signal as
+// last parameter get it implicitly when called in a group without it.
+// Promises cannot escape the group that started them without being awaited.
+// A group ending with a spawned call, like 'async { async consumer(c) }',
+// has no value: the call is only waited for.
+type TaskGroup struct {
+	Keyword Token
+	Body    *Block
+}
+
+func (g *TaskGroup) getChildren() []Node {
+	if g.Body == nil {
+		return []Node{}
+	}
+	return []Node{g.Body}
+}
+
+func (g *TaskGroup) Loc() Loc {
+	loc := g.Keyword.Loc()
+	if g.Body != nil {
+		loc.End = g.Body.loc.End
+	}
+	return loc
+}
+
+func (g *TaskGroup) Type() ExpressionType {
+	if g.Body == nil {
+		return Unknown{}
+	}
+	if g.EndsWithSpawn() {
+		return Nil{}
+	}
+	return g.Body.Type()
+}
+
+// Check if the last statement of the group spawns a call, whose promise is
+// discarded
+func (g *TaskGroup) EndsWithSpawn() bool {
+	if g.Body == nil || len(g.Body.Statements) == 0 {
+		return false
+	}
+	last := g.Body.Statements[len(g.Body.Statements)-1]
+	unary, ok := last.(*UnaryExpression)
+	return ok && unary.Operator.Kind() == AsyncKeyword
+}
+
+func (g *TaskGroup) typeCheck(p *Parser) {
+	if g.Body == nil {
+		return
+	}
+	p.pushScope(NewScope(GroupScope))
+	defer p.dropScope()
+	g.Body.typeCheck(p)
+	if containsPromise(g.Type()) {
+		p.error(g.Body.reportedNode(), EscapingPromise)
+	}
+}
+
+// Report promises assigned to variables declared outside of the current task
+// group, like 'p = async fetch()'
+func reportEscapingPromise(p *Parser, a *Assignment) {
+	if !p.scope.inGroup() {
+		return
+	}
+	pattern, ok := a.Pattern.(*TupleExpression)
+	if !ok {
+		identifier := getReferencedIdentifier(a.Pattern)
+		if identifier != nil && p.scope.isOutsideGroup(identifier.Text()) && containsPromise(a.Value.Type()) {
+			p.error(a.Value, EscapingPromise)
+		}
+		return
+	}
+	tuple, ok := a.Value.Type().(Tuple)
+	if !ok {
+		return
+	}
+	for i, element := range pattern.Elements {
+		identifier, ok := element.(*Identifier)
+		if !ok || i >= len(tuple.Elements) {
+			continue
+		}
+		if p.scope.isOutsideGroup(identifier.Text()) && containsPromise(tuple.Elements[i]) {
+			p.error(element, EscapingPromise)
+		}
+	}
+}
+
+// Check if values of the given type can hold promises
+func containsPromise(t ExpressionType) bool {
+	switch t := t.(type) {
+	case TypeAlias:
+		if t.Name == "..." {
+			return true
+		}
+		if payload := getOptionPayload(t); payload != nil {
+			return containsPromise(payload)
+		}
+	case List:
+		return containsPromise(t.Element)
+	case Tuple:
+		for _, element := range t.Elements {
+			if containsPromise(element) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Check if a call made in a task group leaves out a last Signal parameter,
+// which is then given the group's signal
+func takesGroupSignal(p *Parser, args *TupleExpression, params []ExpressionType) bool {
+	if len(params) == 0 || len(args.Elements) != len(params)-1 || !p.scope.inGroup() {
+		return false
+	}
+	alias, ok := params[len(params)-1].(TypeAlias)
+	return ok && alias.Name == "Signal"
+}
@@ -0,0 +1,108 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTaskGroup(t *testing.T) {
+	source := "extern fetch :: async (number) -> string\n"
+	source += "async {\n"
+	source += "    p := async fetch(1)\n"
+	source += "    await p\n"
+	source += "}"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	group, ok := statements[1].(*TaskGroup)
+	if !ok {
+		t.Fatalf("Expected task group, got %T", statements[1])
+	}
+	if len(group.Body.Statements) != 2 {
+		t.Fatalf("Expected 2 statements, got %v", len(group.Body.Statements))
+	}
+	if text := group.Type().Text(); text != "string" {
+		t.Fatalf("Expected string, got %v", text)
+	}
+}
+
+func TestCheckTaskGroupMakesAsync(t *testing.T) {
+	source := "extern fetch :: async (number) -> string\n"
+	source += "f :: () => {\n"
+	source += "    async {\n"
+	source += "        async fetch(1)\n"
+	source += "    }\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	// the spawned call is only waited for by the group
+	testErrorKinds(t, errors)
+
+	source = "f :: () => {\n"
+	source += "    async {\n"
+	source += "        2\n"
+	source += "    }\n"
+	source += "}"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+	f := statements[0].(*Assignment).Value.Type().(Function)
+	if !f.Async {
+		t.Fatal("Expected function with a task group to be async")
+	}
+}
+
+func TestCheckTrailingSpawn(t *testing.T) {
+	source := "extern consume :: async (number) -> ()\n"
+	source += "async {\n"
+	source += "    async consume(1)\n"
+	source += "}"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	group := statements[1].(*TaskGroup)
+	if _, ok := group.Type().(Nil); !ok {
+		t.Fatalf("Expected no value, got %v", group.Type().Text())
+	}
+}
+
+func TestCheckEscapingPromise(t *testing.T) {
+	source := "extern fetch :: async (number) -> string\n"
+	source += "p := async fetch(0)\n"
+	source += "async {\n"
+	source += "    p = async fetch(1)\n"
+	source += "}\n"
+	source += "await p"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, EscapingPromise)
+}
+
+func TestCheckReturnedPromise(t *testing.T) {
+	source := "extern fetch :: async (number) -> string\n"
+	source += "f :: () => ...string {\n"
+	source += "    async {\n"
+	source += "        return async fetch(1)\n"
+	source += "    }\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, EscapingPromise)
+}
+
+func TestCheckGroupSignal(t *testing.T) {
+	source := "extern fetch :: async (number, Signal) -> string\n"
+	source += "async {\n"
+	source += "    fetch(1)\n"
+	source += "}"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	call := statements[1].(*TaskGroup).Body.Statements[0].(*CallExpression)
+	if !call.PassesSignal() {
+		t.Fatal("Expected the group's signal to be passed")
+	}
+}
+
+func TestCheckSignalOutsideGroup(t *testing.T) {
+	source := "extern fetch :: async (number, Signal) -> string\n"
+	source += "fetch(1)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, MissingElements)
+}
@@ -34,13 +34,29 @@ func (i *IfExpression) typeCheck(p *Parser) {
 			p.error(i.Condition, BooleanExpected, expr.Type())
 		}
 	}
+	subject, constructor := getTestedVariable(i.Condition)
+	if subject != nil {
+		narrowVariable(p, subject, []string{constructor})
+	}
 	i.Body.typeCheck(p)
 	p.dropScope()
 
-	if i.Alternate == nil {
-		return
+	if i.Alternate != nil {
+		typeCheckAlternate(p, i, subject, constructor)
+	}
+	if subject != nil {
+		narrowAfterIf(p, i, subject, constructor)
+	}
+}
+
+func typeCheckAlternate(p *Parser, i *IfExpression, subject *Identifier, constructor string) {
+	p.pushScope(NewScope(BlockScope))
+	if subject != nil {
+		narrowVariableExcluding(p, subject, []string{constructor})
 	}
 	i.Alternate.typeCheck(p)
+	p.dropScope()
+
 	if !Match(i.Body.Type(), i.Alternate.Type()) {
 		loc := Loc{i.Keyword.Loc().Start, i.Alternate.Loc().End}
 		p.error(&Block{loc: loc}, CannotAssignType, i.Body.Type(), i.Alternate.Type())
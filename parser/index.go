@@ -38,24 +38,31 @@ func Walk(node Node, predicate func(n Node, skip func())) {
 
 func Parse(reader io.Reader) ([]Node, []ParserError) {
 	p := MakeParser(reader)
+	statements := p.parseProgram()
+	if len(p.errors) > 0 {
+		statements = []Node{}
+	}
+	return statements, p.errors
+}
+
+// Parse and type check all statements until the end of the input
+func (p *Parser) parseProgram() []Node {
 	statements := []Node{}
+	p.DiscardLineBreaks()
 
 	for p.Peek().Kind() != EOF {
-		statements = append(statements, p.parseStatement())
+		if statement := p.parseStatement(); statement != nil {
+			statements = append(statements, statement)
+		}
 		next := p.Peek().Kind()
-		if next == EOL {
-			p.DiscardLineBreaks()
-		} else if next != EOF {
-			p.error(&Literal{p.Peek()}, TokenExpected, token{kind: EOL})
+		if next != EOL && next != EOF {
+			recover(p, EOL)
 		}
+		p.DiscardLineBreaks()
 	}
 
 	for i := range statements {
-		statements[i].typeCheck(p)
-	}
-
-	if len(p.errors) > 0 {
-		statements = []Node{}
+		typeCheckStatement(p, statements[i])
 	}
-	return statements, p.errors
+	return statements
 }
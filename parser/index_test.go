@@ -1,6 +1,9 @@
 package parser
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func testParserErrors(t *testing.T, p *Parser, expected int) {
 	if len(p.errors) == expected {
@@ -17,3 +20,19 @@ func testParserErrors(t *testing.T, p *Parser, expected int) {
 	}
 	t.Fail()
 }
+
+func testErrorKinds(t *testing.T, errors []ParserError, kinds ...ErrorKind) {
+	if len(errors) != len(kinds) {
+		t.Fatalf("Expected %v error(s), got %#v", len(kinds), errors)
+	}
+	for i, kind := range kinds {
+		if errors[i].Kind != kind {
+			t.Fatalf("Expected error kind %v, got %#v", kind, errors[i])
+		}
+	}
+}
+
+func TestUnexpectedToken(t *testing.T) {
+	_, errors := Parse(strings.NewReader("a := 1 )\na"))
+	testErrorKinds(t, errors, TokenExpected)
+}
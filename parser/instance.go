@@ -46,9 +46,16 @@ func (i *InstanceExpression) typeCheck(p *Parser) {
 func typeCheckStructInstanciation(p *Parser, i *InstanceExpression) {
 	// next line should be ensured by calling function
 	alias := i.Typing.Type().(Type).Value.(TypeAlias)
-	if alias.Name == "Map" {
+	switch alias.Name {
+	case "Map":
 		typeCheckMapInstanciation(p, i, alias)
 		return
+	case "Set":
+		typeCheckSetInstanciation(p, i, alias)
+		return
+	case "Chan":
+		typeCheckChanInstanciation(p, i, alias)
+		return
 	}
 	object, ok := alias.Ref.(Object)
 	if !ok {
@@ -64,6 +71,9 @@ func typeCheckStructInstanciation(p *Parser, i *InstanceExpression) {
 	formatStructEntries(p, args)
 	for _, arg := range args {
 		entry := arg.(*Entry)
+		if entry.Value != nil {
+			entry.Value.typeCheck(p)
+		}
 		var name string
 		if entry.Key != nil {
 			name = entry.Key.(*Identifier).Text()
@@ -201,6 +211,64 @@ func getMapType(p *Parser, i *InstanceExpression) ExpressionType {
 	t.Ref = m
 	return t
 }
+
+// Check a set instanciation, like 'Set{1, 2}'.
+// Without type args, the elements' type is the first element's.
+func typeCheckSetInstanciation(p *Parser, i *InstanceExpression, t TypeAlias) {
+	p.pushScope(NewScope(ProgramScope))
+	defer p.dropScope()
+	typeCheckTypeArgs(p, nil, t.Params)
+	elements := i.Args.Expr.(*TupleExpression).Elements
+	for _, element := range elements {
+		if _, ok := element.(*Entry); ok {
+			p.error(element, UnexpectedExpression)
+			continue
+		}
+		element.typeCheck(p)
+	}
+
+	var first ExpressionType
+	if len(elements) > 0 {
+		first = elements[0].Type()
+	}
+	element, ok := t.Params[0].build(p.scope, first)
+	if !ok {
+		p.error(i, MissingTypeArgs)
+	}
+	t.Params = []Generic{{Name: t.Params[0].Name, Value: element}}
+	t.Ref = Set{element}
+	i.typing = t
+
+	for _, e := range elements {
+		if _, ok := e.(*Entry); !ok && !element.Extends(e.Type()) {
+			p.error(e, CannotAssignType, element, e.Type())
+		}
+	}
+}
+
+// Check a channel instanciation, like 'Chan[number]{}'.
+// The optional argument is the capacity of buffered channels.
+func typeCheckChanInstanciation(p *Parser, i *InstanceExpression, t TypeAlias) {
+	p.pushScope(NewScope(ProgramScope))
+	defer p.dropScope()
+	typeCheckTypeArgs(p, nil, t.Params)
+	args := i.Args.Expr.(*TupleExpression).Elements
+	if len(args) > 1 {
+		p.error(i.Args, TooManyElements, 1, len(args))
+	}
+	for _, arg := range args {
+		arg.typeCheck(p)
+		if _, ok := arg.Type().(Number); !ok {
+			p.error(arg, NumberExpected, arg.Type())
+		}
+	}
+	element, ok := t.Params[0].build(p.scope, nil)
+	if !ok {
+		p.error(i, MissingTypeArgs)
+	}
+	i.typing = makeChanType(element)
+}
+
 func typeCheckMapEntries(p *Parser, entries []Expression, t Map) {
 	for i := range entries {
 		entry := entries[i].(*Entry)
@@ -286,6 +354,11 @@ func (p *Parser) parseInstanceExpression() Expression {
 	}
 	args := p.parseBracedExpression()
 	args.Expr = makeTuple(args.Expr)
+	// references to new values, like '&Point{x: 1}', are heap-allocated
+	if unary, ok := expr.(*UnaryExpression); ok && unary.Operator.Kind() == BinaryAnd {
+		unary.Operand = &InstanceExpression{Typing: unary.Operand, Args: args}
+		return unary
+	}
 	return &InstanceExpression{
 		Typing: expr,
 		Args:   args,
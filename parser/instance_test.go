@@ -188,3 +188,33 @@ func TestCheckAnonymousList(t *testing.T) {
 		t.Fatalf("List expected")
 	}
 }
+
+func TestCheckSetInstanciation(t *testing.T) {
+	statements, errors := Parse(strings.NewReader("Set{1, 2}"))
+	testErrorKinds(t, errors)
+
+	typing := statements[0].(*InstanceExpression).Type()
+	if typing.Text() != "Set[number]" {
+		t.Fatalf("Expected Set[number], got %v", typing.Text())
+	}
+}
+
+func TestCheckSetInstanciationBadElement(t *testing.T) {
+	_, errors := Parse(strings.NewReader("Set{1, \"2\"}"))
+	testErrorKinds(t, errors, CannotAssignType)
+}
+
+func TestCheckSetInstanciationMissingTypeArg(t *testing.T) {
+	_, errors := Parse(strings.NewReader("Set{}"))
+	testErrorKinds(t, errors, MissingTypeArgs)
+}
+
+func TestCheckEmptySetInstanciation(t *testing.T) {
+	statements, errors := Parse(strings.NewReader("Set[string]{}"))
+	testErrorKinds(t, errors)
+
+	typing := statements[0].(*InstanceExpression).Type()
+	if typing.Text() != "Set[string]" {
+		t.Fatalf("Expected Set[string], got %v", typing.Text())
+	}
+}
@@ -0,0 +1,125 @@
+package parser
+
+// JSON conversions are derived from the type of the converted values.
+//
+//	text := toJson(user)
+//	user := try fromJson(User, text) // string!User
+//
+// Objects are encoded as JSON objects, tuples, lists and sets as arrays,
+// maps as objects if their keys are strings, and as arrays of entries
+// otherwise. Absent options are encoded as null.
+// Sum types are tagged, like '{"tag": "Circle", "value": 2}'.
+// Decoding errors give the path to the invalid value, like
+// '.users[3].name: expected string'.
+
+// Check a call like 'toJson(value)'
+func typeCheckJsonEncoding(p *Parser, c *CallExpression) {
+	args := c.Args.Expr.(*TupleExpression)
+	validateArgumentsNumber(p, args, []ExpressionType{Unknown{}})
+	typeCheckFormattedValues(p, args.Elements)
+	if len(args.Elements) > 0 {
+		validateJsonType(p, args.Elements[0], args.Elements[0].Type())
+	}
+	c.typing = String{}
+}
+
+// Check a call like 'fromJson(Type, text)'
+func typeCheckJsonDecoding(p *Parser, c *CallExpression) {
+	args := c.Args.Expr.(*TupleExpression)
+	validateArgumentsNumber(p, args, []ExpressionType{Unknown{}, String{}})
+	for _, arg := range args.Elements {
+		arg.typeCheck(p)
+	}
+	c.typing = Unknown{}
+	if len(args.Elements) > 1 {
+		if _, ok := args.Elements[1].Type().(String); !ok {
+			p.error(args.Elements[1], CannotAssignType, String{}, args.Elements[1].Type())
+		}
+	}
+	if len(args.Elements) == 0 {
+		return
+	}
+	t, ok := args.Elements[0].Type().(Type)
+	if !ok {
+		p.error(args.Elements[0], TypeExpected)
+		return
+	}
+	if validateJsonType(p, args.Elements[0], t.Value) {
+		c.typing = makeResultType(t.Value, String{})
+	}
+}
+
+func validateJsonType(p *Parser, node Node, t ExpressionType) bool {
+	if _, ok := t.(Type); ok {
+		return true // reported as a missing value
+	}
+	invalid := findNonJsonType(t)
+	if invalid != nil {
+		p.error(node, NotSerializable, invalid)
+	}
+	return invalid == nil
+}
+
+// Check if values of the given type can be converted from and to JSON
+func IsJsonType(t ExpressionType) bool {
+	return findNonJsonType(t) == nil
+}
+
+// Find a type that cannot be converted from or to JSON, nested in the
+// given type, if any
+func findNonJsonType(t ExpressionType) ExpressionType {
+	switch t := t.(type) {
+	case Boolean, Number, String:
+		return nil
+	case Generic:
+		if t.Value == nil {
+			return t
+		}
+		return findNonJsonType(t.Value)
+	case Tuple:
+		return findFirstNonJsonType(t.Elements)
+	case List:
+		return findNonJsonType(t.Element)
+	case Set:
+		return findNonJsonType(t.Element)
+	case Map:
+		return findFirstNonJsonType([]ExpressionType{t.Key, t.Value})
+	case TypeAlias:
+		return findNonJsonAliasedType(t)
+	default:
+		return t
+	}
+}
+
+func findNonJsonAliasedType(t TypeAlias) ExpressionType {
+	if isGenerator(t) {
+		return t
+	}
+	switch ref := t.Ref.(type) {
+	case Object:
+		types := []ExpressionType{}
+		for _, member := range append(ref.Members, ref.Defaults...) {
+			types = append(types, member.Type)
+		}
+		return findFirstNonJsonType(types)
+	case Sum:
+		types := []ExpressionType{}
+		for _, constructor := range ref.Members {
+			if constructor.Params != nil {
+				types = append(types, constructor.Params.Elements...)
+			}
+		}
+		return findFirstNonJsonType(types)
+	default:
+		return findNonJsonType(ref)
+	}
+}
+
+func findFirstNonJsonType(types []ExpressionType) ExpressionType {
+	for _, t := range types {
+		if invalid := findNonJsonType(t); invalid != nil {
+			return invalid
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckJsonEncoding(t *testing.T) {
+	source := "Point :: {\n"
+	source += "    x number\n"
+	source += "    tags []string\n"
+	source += "}\n"
+	source += "toJson(Point{x: 1, tags: []string{}})"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	if _, ok := statements[1].(*CallExpression).Type().(String); !ok {
+		t.Fatalf("Expected string, got %v", statements[1].(Expression).Type().Text())
+	}
+}
+
+func TestCheckJsonEncodingFunction(t *testing.T) {
+	source := "f :: () => number { 42 }\n"
+	source += "toJson((1, f))"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, NotSerializable)
+}
+
+func TestCheckJsonDecoding(t *testing.T) {
+	source := "Shape :: | Circle{number} | Square{number}\n"
+	source += "fromJson([]Shape, \"[]\")"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	alias, ok := statements[1].(*CallExpression).Type().(TypeAlias)
+	if !ok || alias.Name != "!" {
+		t.Fatalf("Expected result, got %v", statements[1].(Expression).Type().Text())
+	}
+	if _, ok := alias.Params[0].Value.(List); !ok {
+		t.Fatalf("Expected list, got %v", alias.Params[0].Value.Text())
+	}
+}
+
+func TestCheckJsonDecodingArgs(t *testing.T) {
+	source := "fromJson(1, \"1\")\n"
+	source += "fromJson(number, 1)\n"
+	source += "fromJson(number)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, TypeExpected, CannotAssignType, MissingElements)
+}
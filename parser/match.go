@@ -9,11 +9,14 @@ type MatchCase struct {
 	Statements []Node
 }
 
-func (m MatchCase) Type() ExpressionType {
-	if len(m.Statements) == 0 {
+func (m MatchCase) Type() ExpressionType { return getCaseType(m.Statements) }
+
+// Get the type of the last statement of a case, if it is an expression
+func getCaseType(statements []Node) ExpressionType {
+	if len(statements) == 0 {
 		return Nil{}
 	}
-	expr, ok := m.Statements[len(m.Statements)-1].(Expression)
+	expr, ok := statements[len(statements)-1].(Expression)
 	if !ok {
 		return Nil{}
 	}
@@ -64,24 +67,38 @@ func (m *MatchExpression) Type() ExpressionType {
 }
 
 func (m *MatchExpression) typeCheck(p *Parser) {
+	if m.Value == nil {
+		return
+	}
+	m.Value.typeCheck(p)
 	t := m.Value.Type()
 	if t == nil {
 		return
 	}
-	switch t.(type) {
-	case Sum, Trait:
-	default:
+	_, isSum := getSum(t)
+	_, isTrait := t.(Trait)
+	if !isSum && !isTrait {
 		p.error(m.Value, Unmatchable, t)
 	}
+	subject, _ := Unwrap(m.Value).(*Identifier)
 	for i := range m.Cases {
 		p.pushScope(NewScope(BlockScope))
 		p.typeCheckPattern(m.Cases[i].Pattern, t)
+		if subject != nil {
+			narrowVariable(p, subject, getCaseConstructors(m, i))
+		}
 		for j := range m.Cases[i].Statements {
-			m.Cases[i].Statements[j].typeCheck(p)
+			typeCheckStatement(p, m.Cases[i].Statements[j])
 		}
 		p.dropScope()
 	}
+	if len(m.Cases) == 0 {
+		return
+	}
 	reportMissingCases(p, m.Cases, t)
+	if subject != nil {
+		narrowAfterMatch(p, m, subject)
+	}
 }
 
 // TODO: validate type
@@ -119,22 +136,35 @@ func (p *Parser) parseMatchExpression() Expression {
 }
 
 func parseMatchCase(p *Parser) MatchCase {
-	pattern := parseCaseStatement(p)
+	return MatchCase{
+		Pattern:    parseCaseStatement(p),
+		Statements: parseCaseBody(p),
+	}
+}
+
+// Parse the statements of a case, up to the next case or the closing brace
+func parseCaseBody(p *Parser) []Node {
 	stopAt := []TokenKind{EOF, RightBrace, CaseKeyword}
 	statements := []Node{}
 	for !slices.Contains(stopAt, p.Peek().Kind()) {
 		statements = append(statements, p.parseStatement())
 		p.DiscardLineBreaks()
 	}
-	return MatchCase{
-		Pattern:    pattern,
-		Statements: statements,
-	}
+	return statements
 }
 
 func parseCaseStatement(p *Parser) Expression {
 	p.Consume()
+	outer := p.preventColon
+	p.preventColon = true
 	pattern := p.parseExpression()
+	p.preventColon = outer
+	parseCaseColon(p)
+	return pattern
+}
+
+// Parse the colon ending a case statement, up to the case's first statement
+func parseCaseColon(p *Parser) {
 	if p.Peek().Kind() == Colon || recover(p, Colon) {
 		p.Consume()
 	}
@@ -142,7 +172,6 @@ func parseCaseStatement(p *Parser) Expression {
 		recover(p, EOL)
 	}
 	p.DiscardLineBreaks()
-	return pattern
 }
 
 func validateCaseList(p *Parser, cases []MatchCase) {
@@ -195,11 +224,7 @@ func reportDuplicatedCases(p *Parser, cases []MatchCase) {
 
 // length of cases should be at least 1
 func reportMissingCases(p *Parser, cases []MatchCase, matched ExpressionType) {
-	last := cases[len(cases)-1]
-	loc := Loc{
-		cases[0].Pattern.Loc().Start,
-		last.Statements[len(last.Statements)-1].Loc().End,
-	}
+	b := &Block{loc: getCasesLoc(cases)}
 	names := map[string]bool{}
 	for _, c := range cases {
 		identifier := getCaseIdentifier(c)
@@ -212,17 +237,30 @@ func reportMissingCases(p *Parser, cases []MatchCase, matched ExpressionType) {
 		}
 		names[identifier.Text()] = true
 	}
-	sum, ok := matched.(Sum)
+	sum, ok := getSum(matched)
 	if !ok {
-		p.error(&Block{loc: loc}, NotExhaustive)
+		p.error(b, NotExhaustive)
+		return
 	}
 	for name := range sum.Members {
-		delete(names, name)
+		if !names[name] {
+			p.error(b, MissingConstructor, name)
+		}
 	}
-	b := &Block{loc: loc}
-	for name := range names {
-		p.error(b, MissingConstructor, name)
+}
+
+func getCasesLoc(cases []MatchCase) Loc {
+	var loc Loc
+	if cases[0].Pattern != nil {
+		loc.Start = cases[0].Pattern.Loc().Start
 	}
+	last := cases[len(cases)-1]
+	if len(last.Statements) > 0 {
+		loc.End = last.Statements[len(last.Statements)-1].Loc().End
+	} else if last.Pattern != nil {
+		loc.End = last.Pattern.Loc().End
+	}
+	return loc
 }
 
 func getCaseIdentifier(c MatchCase) *Identifier {
@@ -0,0 +1,84 @@
+package parser
+
+// Report modifications of read-only places.
+//
+// Places are variables, like 'x', dereferenced references, like '*r', and
+// their members, like 'x.list[0]'. They are modified by assignments, by
+// '&mut' references, and by calling methods declared on 'mut' receivers.
+//
+// Variables defined with '::' and receivers of methods not declared with
+// 'mut' are read-only, as are the places reached through '&' references.
+// Return true if the modification was reported.
+func reportReadOnlyWrite(p *Parser, site Node, place Expression) bool {
+	for place != nil {
+		var next Expression
+		switch e := place.(type) {
+		case *Identifier:
+			if e.variable == nil || !e.variable.readonly {
+				return false
+			}
+			p.error(site, ReadOnlyWrite, e.Text(), e.variable.declaredAt)
+			return true
+		case *TupleExpression:
+			reported := false
+			for _, element := range e.Elements {
+				reported = reportReadOnlyWrite(p, site, element) || reported
+			}
+			return reported
+		case *ParenthesizedExpression:
+			place = e.Expr
+			continue
+		case *UnaryExpression:
+			if e.Operator.Kind() == Mul {
+				return reportReadOnlyRef(p, site, e.Operand)
+			}
+			return false
+		case *PropertyAccessExpression:
+			next = e.Expr
+		case *ComputedAccessExpression:
+			next = e.Expr
+		default:
+			// temporary values, like 'f().list', can be modified
+			return false
+		}
+		// members of references are implicitly dereferenced
+		if _, ok := next.Type().(Ref); ok {
+			return reportReadOnlyRef(p, site, next)
+		}
+		place = next
+	}
+	return false
+}
+
+// Report modifications through a read-only reference.
+// References held by variables are reported with the variable's declaration.
+// Return true if the modification was reported.
+func reportReadOnlyRef(p *Parser, site Node, ref Expression) bool {
+	t, ok := ref.Type().(Ref)
+	if !ok || t.Mutable {
+		return false
+	}
+	identifier, ok := ref.(*Identifier)
+	if !ok || identifier.variable == nil {
+		p.error(site, ReadOnlyRefWrite)
+		return true
+	}
+	p.error(site, ReadOnlyRefWrite, identifier.Text(), identifier.variable.declaredAt)
+	return true
+}
+
+// Report calls to methods declared on 'mut' receivers, on read-only places
+func reportReadOnlyReceiver(p *Parser, c *CallExpression, method Function) {
+	if !method.Mutates {
+		return
+	}
+	access, ok := c.Callee.(*PropertyAccessExpression)
+	if !ok {
+		return
+	}
+	if _, ok := access.Expr.Type().(Ref); ok {
+		reportReadOnlyRef(p, c, access.Expr)
+	} else {
+		reportReadOnlyWrite(p, c, access.Expr)
+	}
+}
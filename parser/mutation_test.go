@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteToDefinition(t *testing.T) {
+	source := "f :: () => number { 1 }\nf = () => { 2 }"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, ReadOnlyWrite)
+}
+
+func TestWriteThroughReadOnlyRef(t *testing.T) {
+	source := "x := 1\nr := &x\n*r = 2\nx"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, ReadOnlyRefWrite)
+}
+
+func TestInvalidReadOnlyPattern(t *testing.T) {
+	source := "Box :: {\n    name string\n}\n"
+	source += "(b Box).rename :: () => {\n"
+	source += "    b.name[0] = \"x\"\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, ReadOnlyWrite)
+}
+
+func TestInvalidPatternThroughReadOnlyRef(t *testing.T) {
+	source := "x := 1\nr := &x\n(*r, x) = (1, 2)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, ReadOnlyRefWrite)
+}
+
+func TestWriteThroughMutableRef(t *testing.T) {
+	source := "x := 1\nr := &mut x\n*r = 2\nx"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+}
+
+func TestMutableRefToDefinition(t *testing.T) {
+	source := "f :: () => number { 1 }\nr := &mut f\n*r"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, ReadOnlyWrite)
+}
+
+func TestMutatingMethodThroughReadOnlyRef(t *testing.T) {
+	source := "list := []number{}\nr := &list\nr.push(1)\nlist"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, ReadOnlyRefWrite)
+}
+
+func TestReadOnlyReceiver(t *testing.T) {
+	source := "Counter :: {\n    count number\n}\n(c Counter).incr :: () => {\n    c.count += 1\n}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, ReadOnlyWrite)
+}
+
+func TestMutableReceiver(t *testing.T) {
+	source := "Counter :: {\n    count number\n}\n(c mut Counter).incr :: () => {\n    c.count += 1\n}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+}
+
+func TestWriteOnlyMutableRefParam(t *testing.T) {
+	source := "Counter :: {\n    count number\n}\nincr :: (c &mut Counter) => {\n    c.count += 1\n}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+}
+
+func TestPassReadOnlyRefAsMutable(t *testing.T) {
+	source := "incr :: (r &mut number) => { *r = *r + 1 }\nx := 1\nincr(&x)\nx"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, CannotAssignType)
+}
+
+func TestPassMutableRefAsReadOnly(t *testing.T) {
+	source := "show :: (r &number) => number { *r }\nx := 1\nshow(&mut x)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+}
+
+func TestIllegalMut(t *testing.T) {
+	source := "x := mut 1\nx"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, IllegalMut)
+}
+
+func TestMutableRefText(t *testing.T) {
+	ref := Ref{To: Number{}, Mutable: true}
+	if ref.Text() != "&mut number" {
+		t.Fatalf("Expected '&mut number', got '%v'", ref.Text())
+	}
+}
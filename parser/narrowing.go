@@ -0,0 +1,177 @@
+package parser
+
+import "slices"
+
+// Get the variable tested in a conditional declaration,
+// like 'option' in 'Some(s) := option', and the tested constructor.
+func getTestedVariable(condition Node) (*Identifier, string) {
+	a, ok := condition.(*Assignment)
+	if !ok || a.Operator.Kind() != Declare || !isConstructorPattern(a.Pattern) {
+		return nil, ""
+	}
+	if a.Value == nil {
+		return nil, ""
+	}
+	identifier, ok := Unwrap(a.Value).(*Identifier)
+	if !ok {
+		return nil, ""
+	}
+	return identifier, getPatternConstructor(a.Pattern)
+}
+
+// Get all the constructors of a sum type but the excluded ones.
+// Return false if the type is not a sum type.
+func getOtherConstructors(t ExpressionType, excluded []string) ([]string, bool) {
+	sum, ok := getSum(t)
+	if !ok {
+		return nil, false
+	}
+	constructors := []string{}
+	for name := range sum.Members {
+		if !slices.Contains(excluded, name) {
+			constructors = append(constructors, name)
+		}
+	}
+	return constructors, true
+}
+
+// Refine the type of a variable in the current scope, knowing that it was
+// built using one of the given constructors.
+//
+// Options and results are narrowed to their payload: after
+// 'if None := option { return }', 'option' can be used as its 'Some' value.
+// Trait values are narrowed to the type implementing the trait.
+func narrowVariable(p *Parser, subject *Identifier, constructors []string) {
+	if len(constructors) != 1 {
+		return
+	}
+	name := subject.Text()
+	constructor := constructors[0]
+	switch t := subject.Type().(type) {
+	case TypeAlias:
+		if t.Name != "?" && t.Name != "!" {
+			return
+		}
+		sum, ok := t.Ref.(Sum)
+		if !ok || sum.Members[constructor].arity() != 1 {
+			return
+		}
+		p.scope.narrow(name, sum.getMember(constructor), true)
+	case Trait:
+		v, ok := p.scope.Find(constructor)
+		if !ok {
+			return
+		}
+		typing, ok := v.Typing.(Type)
+		if !ok {
+			return
+		}
+		if alias, ok := typing.Value.(TypeAlias); ok && alias.implements(t) {
+			p.scope.narrow(name, alias, false)
+		}
+	}
+}
+
+// Refine the type of a variable in the current scope, knowing that it was
+// not built using any of the given constructors.
+func narrowVariableExcluding(p *Parser, subject *Identifier, excluded []string) {
+	constructors, ok := getOtherConstructors(subject.Type(), excluded)
+	if ok {
+		narrowVariable(p, subject, constructors)
+	}
+}
+
+// Refine the tested variable of an if expression in subsequent code, if
+// one of its branches always exits.
+func narrowAfterIf(p *Parser, i *IfExpression, subject *Identifier, constructor string) {
+	bodyExits := i.Body != nil && Exits(i.Body)
+	alternateExits := i.Alternate != nil && Exits(i.Alternate)
+	switch {
+	case bodyExits && !alternateExits:
+		narrowVariableExcluding(p, subject, []string{constructor})
+	case alternateExits && !bodyExits:
+		narrowVariable(p, subject, []string{constructor})
+	}
+}
+
+// Refine the matched variable in subsequent code,
+// using the constructors of the cases that do not always exit.
+func narrowAfterMatch(p *Parser, m *MatchExpression, subject *Identifier) {
+	listed := []string{}
+	for _, c := range m.Cases {
+		if !c.IsCatchall() {
+			listed = append(listed, getPatternConstructor(c.Pattern))
+		}
+	}
+	remaining := []string{}
+	for _, c := range m.Cases {
+		if alwaysExits(c.Statements) {
+			continue
+		}
+		if !c.IsCatchall() {
+			remaining = append(remaining, getPatternConstructor(c.Pattern))
+			continue
+		}
+		others, ok := getOtherConstructors(subject.Type(), listed)
+		if !ok {
+			// the catch-all case of a trait can be anything
+			return
+		}
+		remaining = append(remaining, others...)
+	}
+	narrowVariable(p, subject, remaining)
+}
+
+// Get the constructors that a match case may match
+func getCaseConstructors(m *MatchExpression, index int) []string {
+	c := m.Cases[index]
+	if !c.IsCatchall() {
+		return []string{getPatternConstructor(c.Pattern)}
+	}
+	listed := []string{}
+	for _, c := range m.Cases[:index] {
+		listed = append(listed, getPatternConstructor(c.Pattern))
+	}
+	others, _ := getOtherConstructors(m.Value.Type(), listed)
+	return others
+}
+
+// Drop the refinements of the variables assigned in a loop, before checking
+// it. Refinements are computed in a single pass, while assignments are seen
+// by the next iterations, like in:
+//
+//	if None := option { return }
+//	for i in 0..3 {
+//	    total += option // 'option' might be None on the second iteration
+//	    option = other
+//	}
+func widenLoopAssignments(p *Parser, loop *ForExpression) {
+	Walk(loop, func(n Node, skip func()) {
+		if isFunctionExpression(n) {
+			skip()
+			return
+		}
+		a, ok := n.(*Assignment)
+		if !ok || a.Operator.Kind() == Declare || a.Operator.Kind() == Define {
+			return
+		}
+		for _, identifier := range getAssignedIdentifiers(a.Pattern) {
+			p.scope.widen(identifier.Text())
+		}
+	})
+}
+
+// Get the variables assigned by a pattern, like 'a' and 'b' in 'a, b = f()'
+func getAssignedIdentifiers(pattern Expression) []*Identifier {
+	switch pattern := pattern.(type) {
+	case *Identifier:
+		return []*Identifier{pattern}
+	case *TupleExpression:
+		identifiers := []*Identifier{}
+		for _, element := range pattern.Elements {
+			identifiers = append(identifiers, getAssignedIdentifiers(element)...)
+		}
+		return identifiers
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNarrowAfterEarlyReturn(t *testing.T) {
+	source := "f :: (option ?number) => number {\n"
+	source += "    if None := option {\n"
+	source += "        return 0\n"
+	source += "    }\n"
+	source += "    option + 1\n"
+	source += "}\n"
+	source += "io.log(f)"
+	_, errors := Parse(strings.NewReader(source))
+	if len(errors) > 0 {
+		t.Fatalf("Expected no errors, got %#v", errors)
+	}
+}
+
+func TestNoNarrowingWithoutExit(t *testing.T) {
+	source := "f :: (option ?number) => number {\n"
+	source += "    if None := option {\n"
+	source += "        io.log(0)\n"
+	source += "    }\n"
+	source += "    option + 1\n"
+	source += "}\n"
+	source += "io.log(f)"
+	_, errors := Parse(strings.NewReader(source))
+	if len(errors) == 0 {
+		t.Fatalf("Expected errors, got none")
+	}
+}
+
+func TestNarrowInAlternate(t *testing.T) {
+	source := "f :: (option ?number) => number {\n"
+	source += "    if None := option {\n"
+	source += "        0\n"
+	source += "    } else {\n"
+	source += "        option * 2\n"
+	source += "    }\n"
+	source += "}\n"
+	source += "io.log(f)"
+	_, errors := Parse(strings.NewReader(source))
+	if len(errors) > 0 {
+		t.Fatalf("Expected no errors, got %#v", errors)
+	}
+}
+
+func TestNarrowAfterMatch(t *testing.T) {
+	source := "f :: (option ?number) => number {\n"
+	source += "    match option {\n"
+	source += "    case None:\n"
+	source += "        return 0\n"
+	source += "    case _:\n"
+	source += "        io.log(1)\n"
+	source += "    }\n"
+	source += "    option * 2\n"
+	source += "}\n"
+	source += "io.log(f)"
+	_, errors := Parse(strings.NewReader(source))
+	if len(errors) > 0 {
+		t.Fatalf("Expected no errors, got %#v", errors)
+	}
+}
+
+func TestWideningOnWrite(t *testing.T) {
+	source := "f :: (option ?number, other ?number) => number {\n"
+	source += "    if None := option {\n"
+	source += "        return 0\n"
+	source += "    }\n"
+	source += "    option = other\n"
+	source += "    option + 1\n"
+	source += "}\n"
+	source += "io.log(f)"
+	_, errors := Parse(strings.NewReader(source))
+	if len(errors) != 1 {
+		t.Fatalf("Expected 1 error, got %#v", errors)
+	}
+}
+
+func TestSumTypeSpanningLines(t *testing.T) {
+	source := "Shape :: | Circle{number}\n"
+	source += "    | Square{number}\n"
+	source += "io.log(Shape)"
+	parser := MakeParser(strings.NewReader(source))
+	first := parser.parseStatement()
+	if _, ok := first.(*Assignment); !ok {
+		t.Fatalf("Expected assignment, got %#v", first)
+	}
+	if parser.Peek().Kind() != EOL {
+		t.Fatalf("Expected a line break after the sum type, got %#v", parser.Peek())
+	}
+}
+
+func TestWidenAssignedInLoop(t *testing.T) {
+	source := "f :: (option ?number, other ?number) => number {\n"
+	source += "    if None := option {\n"
+	source += "        return 0\n"
+	source += "    }\n"
+	source += "    total := 0\n"
+	source += "    for i in 0..3 {\n"
+	source += "        total += option + i\n"
+	source += "        option = other\n"
+	source += "    }\n"
+	source += "    total\n"
+	source += "}\n"
+	source += "io.log(f)"
+	_, errors := Parse(strings.NewReader(source))
+	if len(errors) == 0 {
+		t.Fatalf("Expected errors, got none")
+	}
+}
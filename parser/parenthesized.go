@@ -50,7 +50,7 @@ func (p *Parser) parseParenthesizedExpression() *ParenthesizedExpression {
 	outerMultiline := p.multiline
 	p.allowBraceParsing = true
 	p.multiline = true
-	expr := p.parseTupleExpression()
+	expr := p.parseRange()
 	p.allowBraceParsing = outerBrace
 	p.multiline = outerMultiline
 
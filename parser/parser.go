@@ -40,10 +40,17 @@ func (p *Parser) error(node Node, kind ErrorKind, comp ...interface{}) {
 }
 
 func MakeParser(reader io.Reader) *Parser {
+	return newParser(reader, preludeScope())
+}
+
+// Make a parser whose program scope is nested in the given one
+func newParser(reader io.Reader, outer *Scope) *Parser {
 	tokenizer := NewTokenizer(reader)
+	scope := NewScope(ProgramScope)
+	scope.outer = outer
 	return &Parser{
 		tokenizer:         *tokenizer,
-		scope:             &std,
+		scope:             scope,
 		allowBraceParsing: true,
 		allowCallExpr:     true,
 	}
@@ -55,9 +62,10 @@ func (p *Parser) pushScope(scope *Scope) {
 }
 
 func (p *Parser) dropScope() {
-	for _, info := range p.scope.variables {
-		if len(info.reads) == 0 {
-			p.error(&Block{loc: info.declaredAt}, UnusedVariable)
+	for name, info := range p.scope.variables {
+		// implicit declarations have no location
+		if !info.isUsed() && info.declaredAt != (Loc{}) {
+			p.error(&Block{loc: info.declaredAt}, UnusedVariable, name)
 		}
 	}
 	p.scope = p.scope.outer
@@ -67,6 +75,10 @@ func (p *Parser) parseStatement() Node {
 	switch p.Peek().Kind() {
 	case BreakKeyword, ContinueKeyword, ReturnKeyword, ThrowKeyword:
 		return p.parseExit()
+	case ExternKeyword:
+		return p.parseExternDeclaration()
+	case YieldKeyword:
+		return p.parseYield()
 	default:
 		return p.parseAssignment()
 	}
@@ -80,7 +92,9 @@ func (p *Parser) parseExpression() Expression {
 		return p.parseIfExpression()
 	case MatchKeyword:
 		return p.parseMatchExpression()
+	case SelectKeyword:
+		return p.parseSelectExpression()
 	default:
-		return p.parseTupleExpression()
+		return p.parseRange()
 	}
 }
@@ -1,44 +1,156 @@
 package parser
 
 func (p *Parser) typeCheckPattern(pattern Expression, matched ExpressionType) {
-	switch matched := matched.(type) {
+	if sum, ok := getSum(matched); ok {
+		validateSumPattern(p, pattern, sum)
+		return
+	}
+	if trait, ok := matched.(Trait); ok {
+		validateTraitPattern(p, pattern, trait)
+	}
+}
+
+// Get the sum type behind given type (which can be aliased), if any
+func getSum(t ExpressionType) (Sum, bool) {
+	switch t := t.(type) {
 	case Sum:
-	case Trait:
-		validateTraitPattern(p, pattern, matched)
+		return t, true
+	case TypeAlias:
+		sum, ok := t.Ref.(Sum)
+		return sum, ok
+	default:
+		return Sum{}, false
+	}
+}
+
+// Validate patterns like 'Constructor' or 'Constructor(a, b)',
+// and declare bound identifiers.
+func validateSumPattern(p *Parser, pattern Expression, sum Sum) {
+	switch pattern := pattern.(type) {
+	case *Identifier:
+		name := pattern.Text()
+		if name == "_" {
+			return
+		}
+		if _, ok := sum.Members[name]; !ok {
+			p.error(pattern, ConstructorDoesNotExist, name)
+		}
+	case *CallExpression:
+		callee, ok := pattern.Callee.(*Identifier)
+		if !ok {
+			p.error(pattern.Callee, TypeIdentifierExpected)
+			return
+		}
+		member, ok := sum.Members[callee.Text()]
+		if !ok {
+			p.error(callee, ConstructorDoesNotExist, callee.Text())
+			return
+		}
+		declarePatternElements(p, pattern.Args, member)
+	default:
+		p.error(pattern, InvalidPattern)
+	}
+}
+
+func declarePatternElements(p *Parser, args *ParenthesizedExpression, constructor Function) {
+	elements := makeTuple(args.Expr).Elements
+	if len(elements) != constructor.arity() {
+		if len(elements) > constructor.arity() {
+			p.error(args, TooManyElements, constructor.arity(), len(elements))
+		} else {
+			p.error(args, MissingElements, constructor.arity(), len(elements))
+		}
+		return
+	}
+	for i, element := range elements {
+		identifier, ok := element.(*Identifier)
+		if !ok {
+			p.error(element, IdentifierExpected)
+			continue
+		}
+		t, _ := constructor.Params.Elements[i].build(nil, nil)
+		declareIdentifier(p, identifier, t)
 	}
 }
 
+// Validate patterns like 'Type{value}' or 'Type(value)' where the matched
+// value implements a trait.
 func validateTraitPattern(p *Parser, pattern Expression, trait Trait) {
-	instance, ok := pattern.(*InstanceExpression)
-	if !ok {
+	var callee Expression
+	var elements []Expression
+	var args Node
+	switch pattern := pattern.(type) {
+	case *InstanceExpression:
+		callee = pattern.Typing
+		elements = pattern.Args.Expr.(*TupleExpression).Elements
+		args = pattern.Args
+	case *CallExpression:
+		callee = pattern.Callee
+		elements = makeTuple(pattern.Args.Expr).Elements
+		args = pattern.Args
+	default:
 		p.error(pattern, InvalidPattern)
 		return
 	}
-	callee, ok := instance.Typing.(*Identifier)
-	if !ok || !callee.IsType() {
-		p.error(instance.Typing, TypeIdentifierExpected)
+	identifier, ok := callee.(*Identifier)
+	if !ok || !identifier.IsType() {
+		p.error(callee, TypeIdentifierExpected)
 		return
 	}
-	v, ok := p.scope.Find(callee.Text())
+	alias, ok := getTraitPatternType(p, identifier)
 	if !ok {
-		p.error(callee, CannotFind, callee.Text())
 		return
 	}
-	alias, ok := v.Typing.(TypeAlias)
-	if !ok || alias.implements(trait) {
-		p.error(callee, TypeDoesNotImplement, callee.Type())
+	if !alias.implements(trait) {
+		p.error(identifier, TypeDoesNotImplement, alias)
 		return
 	}
 
-	elements := instance.Args.Expr.(*TupleExpression).Elements
 	if len(elements) != 1 {
-		p.error(instance.Args, TooManyElements, 1, len(elements))
+		p.error(args, TooManyElements, 1, len(elements))
 		return
 	}
-	identifier, ok := elements[0].(*Identifier)
+	bound, ok := elements[0].(*Identifier)
 	if !ok {
 		p.error(elements[0], InvalidPattern)
 		return
 	}
-	p.scope.Add(identifier.Text(), identifier.Loc(), alias)
+	declareIdentifier(p, bound, alias)
+}
+
+func getTraitPatternType(p *Parser, identifier *Identifier) (TypeAlias, bool) {
+	v, ok := p.scope.Find(identifier.Text())
+	if !ok {
+		p.error(identifier, CannotFind, identifier.Text())
+		return TypeAlias{}, false
+	}
+	v.readAt(identifier.Loc())
+	t, ok := v.Typing.(Type)
+	if !ok {
+		p.error(identifier, TypeExpected)
+		return TypeAlias{}, false
+	}
+	alias, ok := t.Value.(TypeAlias)
+	if !ok {
+		p.error(identifier, TypeDoesNotImplement, t.Value)
+	}
+	return alias, ok
+}
+
+// Get the name of the constructor used in a pattern.
+// Return "_" for catch-all patterns and "" for invalid ones.
+func getPatternConstructor(pattern Expression) string {
+	var identifier *Identifier
+	switch pattern := pattern.(type) {
+	case *Identifier:
+		identifier = pattern
+	case *CallExpression:
+		identifier, _ = pattern.Callee.(*Identifier)
+	case *InstanceExpression:
+		identifier, _ = pattern.Typing.(*Identifier)
+	}
+	if identifier == nil {
+		return ""
+	}
+	return identifier.Text()
 }
@@ -0,0 +1,76 @@
+package parser
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// The part of the standard library written in the language itself.
+// It is parsed and checked once, then a copy of its scope is used as the
+// outermost scope of every program.
+//
+//go:embed prelude.src
+var preludeSource string
+
+var prelude struct {
+	once       sync.Once
+	scope      *Scope
+	statements []Node
+}
+
+func loadPrelude() {
+	p := newParser(strings.NewReader(preludeSource), &builtins)
+	statements := p.parseProgram()
+	if len(p.errors) > 0 {
+		messages := []string{}
+		for _, err := range p.errors {
+			line := err.Node.Loc().Start.Line
+			messages = append(messages, fmt.Sprintf("line %v: %v", line, err.Text()))
+		}
+		panic("invalid prelude:\n" + strings.Join(messages, "\n"))
+	}
+	markFormatMethods(p.scope)
+	markHelperMethods(p.scope)
+	p.scope.prelude = true
+	prelude.scope = p.scope
+	prelude.statements = statements
+}
+
+// Formatted printing takes any number of values, which cannot be declared in
+// the prelude itself
+func markFormatMethods(scope *Scope) {
+	variable, _ := scope.Find("IO")
+	io := variable.Typing.(Type).Value.(TypeAlias)
+	for _, name := range []string{"print", "println"} {
+		method := io.Methods[name].(Function)
+		method.Intrinsic = FormatIntrinsic
+		io.Methods[name] = method
+	}
+}
+
+// Some promise combinators have no JS equivalent, they are implemented by
+// runtime helpers instead
+func markHelperMethods(scope *Scope) {
+	variable, _ := scope.Find("Promise")
+	promise := variable.Typing.(Type).Value.(TypeAlias)
+	for _, name := range []string{"any", "sleep", "timeout"} {
+		method := promise.Methods[name].(Function)
+		method.Intrinsic = HelperIntrinsic
+		promise.Methods[name] = method
+	}
+}
+
+// Get a copy of the prelude's scope, since programs record reads and writes
+// of its variables, and may declare methods on its types
+func preludeScope() *Scope {
+	prelude.once.Do(loadPrelude)
+	return prelude.scope.clone()
+}
+
+// Get the checked statements of the prelude, to be emitted before programs
+func Prelude() []Node {
+	prelude.once.Do(loadPrelude)
+	return prelude.statements
+}
@@ -0,0 +1,220 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPreludeListMembers(t *testing.T) {
+	source := "list := []number{1, 2}\n"
+	source += "list.get(0)"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	alias, ok := statements[1].(*CallExpression).Type().(TypeAlias)
+	if !ok || alias.Name != "?" {
+		t.Fatalf("Expected option, got %v", statements[1].(*CallExpression).Type().Text())
+	}
+	if _, ok := alias.Params[0].Value.(Number); !ok {
+		t.Fatalf("Expected ?number, got %v", alias.Text())
+	}
+}
+
+func TestPreludeListMethod(t *testing.T) {
+	source := "list := []number{1, 2}\n"
+	source += "list.set(0, \"a\")"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, CannotAssignType)
+}
+
+func TestPreludeListGenericMethod(t *testing.T) {
+	source := "list := []number{1, 2}\n"
+	source += "list.map((x) => { x > 1 })"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	typing := statements[1].(*CallExpression).Type()
+	list, ok := typing.(List)
+	if !ok {
+		t.Fatalf("Expected list, got %v", typing.Text())
+	}
+	if _, ok := list.Element.(Boolean); !ok {
+		t.Fatalf("Expected []boolean, got %v", list.Text())
+	}
+}
+
+func TestPreludeListReduce(t *testing.T) {
+	source := "list := []number{1, 2}\n"
+	source += "list.reduce((acc, x) => { acc + x }, \"\")"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, NumberExpected, CannotAssignType)
+}
+
+func TestPreludeListPredicate(t *testing.T) {
+	source := "list := []number{1, 2}\n"
+	source += "list.filter((x) => { x + 1 })"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, CannotAssignType)
+}
+
+func TestPreludeExternType(t *testing.T) {
+	_, errors := Parse(strings.NewReader("io.log(42)\nio.log()"))
+	testErrorKinds(t, errors, MissingElements)
+}
+
+func TestLineComment(t *testing.T) {
+	source := "// a comment\n"
+	source += "a := 1 // another one\n"
+	source += "a"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+	if len(statements) != 2 {
+		t.Fatalf("Expected 2 statements, got %#v", statements)
+	}
+}
+
+func TestPreludeMapMembers(t *testing.T) {
+	source := "m := Map{\"a\": 1}\n"
+	source += "m.size\n"
+	source += "m.keys()\n"
+	source += "m.getOr(\"b\", 2)\n"
+	source += "m.values()\n"
+	source += "m.entries()"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	if _, ok := statements[1].(*PropertyAccessExpression).Type().(Number); !ok {
+		t.Fatalf("Expected number, got %v", statements[1].(Expression).Type().Text())
+	}
+	keys := statements[2].(*CallExpression).Type()
+	if keys.Text() != "[]string" {
+		t.Fatalf("Expected []string, got %v", keys.Text())
+	}
+	if _, ok := statements[3].(*CallExpression).Type().(Number); !ok {
+		t.Fatalf("Expected number, got %v", statements[3].(Expression).Type().Text())
+	}
+	values := statements[4].(*CallExpression).Type()
+	if values.Text() != "[]number" {
+		t.Fatalf("Expected []number, got %v", values.Text())
+	}
+	entries := statements[5].(*CallExpression).Type()
+	if entries.Text() != "[](string, number)" {
+		t.Fatalf("Expected [](string, number), got %v", entries.Text())
+	}
+}
+
+func TestPreludeMapBadKey(t *testing.T) {
+	source := "m := Map{\"a\": 1}\n"
+	source += "m.delete(1)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, CannotAssignType)
+}
+
+func TestPreludeMapUpdate(t *testing.T) {
+	source := "m := Map{\"a\": 1}\n"
+	source += "m.update(\"a\", (x) => { (x ?? 0) + 1 })\n"
+	source += "m.update(\"a\", (_) => { \"b\" })"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, CannotAssignType)
+}
+
+func TestPreludeStringMembers(t *testing.T) {
+	source := "s := \"a b\"\n"
+	source += "s.len()\n"
+	source += "s.split(\" \")\n"
+	source += "s.contains(\"a\")"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	expected := []string{"number", "[]string", "boolean"}
+	for i, text := range expected {
+		typing := statements[i+1].(*CallExpression).Type()
+		if typing.Text() != text {
+			t.Fatalf("Expected %v, got %v", text, typing.Text())
+		}
+	}
+}
+
+func TestPreludeStringParseNumber(t *testing.T) {
+	source := "f :: () => string!number { try \"42\".parseNumber() }\n"
+	source += "g :: () => number!number { try \"42\".parseNumber() }\n"
+	source += "f, g"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, CannotAssignType)
+}
+
+func TestPreludeSetMembers(t *testing.T) {
+	source := "a := Set{1, 2}\n"
+	source += "b := Set{2, 3}\n"
+	source += "a.union(b)\n"
+	source += "a.has(1)"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	union := statements[2].(*CallExpression).Type()
+	if union.Text() != "Set[number]" {
+		t.Fatalf("Expected Set[number], got %v", union.Text())
+	}
+	if _, ok := statements[3].(*CallExpression).Type().(Boolean); !ok {
+		t.Fatalf("Expected boolean, got %v", statements[3].(Expression).Type().Text())
+	}
+}
+
+func TestPreludeSetBadAlgebra(t *testing.T) {
+	source := "a := Set{1, 2}\n"
+	source += "b := Set{\"a\"}\n"
+	source += "a.difference(b)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, CannotAssignType)
+}
+
+func TestPreludeMath(t *testing.T) {
+	source := "math.floor(math.pi)\n"
+	source += "math.max(1, math.inf)\n"
+	source += "math.sqrt(\"4\")"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, CannotAssignType)
+}
+
+func TestPreludeSeededRandom(t *testing.T) {
+	source := "random := seededRandom(42)\n"
+	source += "random()"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	if _, ok := statements[1].(*CallExpression).Type().(Number); !ok {
+		t.Fatalf("Expected number, got %v", statements[1].(Expression).Type().Text())
+	}
+}
+
+func TestPreludeDisplay(t *testing.T) {
+	source := "Point :: { x number }\n"
+	source += "(p Point).show :: () => string { string(p.x) }\n"
+	source += "describe :: (d Display) => string { d.show() }\n"
+	source += "describe(Point{x: 1})\n"
+	source += "string(2, 3)\n"
+	source += "string(number)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, TooManyElements, ValueExpected)
+}
+
+func TestPreludeFormattedPrinting(t *testing.T) {
+	source := "io.println(\"{} + {} = {{}}\", 1, 2)\n"
+	source += "io.print(\"{}\")\n"
+	source += "io.print(\"{}\", 1, 2)\n"
+	source += "format := \"{}\"\n"
+	source += "io.println(format, 1)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, MissingElements, TooManyElements, StringExpected)
+}
+
+func TestPreludeNotModifiedByPrograms(t *testing.T) {
+	source := "(l List).total :: () => number {\n"
+	source += "    l.length\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	_, errors = Parse(strings.NewReader("l := []number{1}\nl.total"))
+	testErrorKinds(t, errors, PropertyDoesNotExist)
+}
@@ -4,11 +4,13 @@ import (
 	"strconv"
 )
 
-// Expr.Property
+// Expr.Property, or Expr?.Property if Optional
 type PropertyAccessExpression struct {
 	Expr     Expression
 	Property Expression
+	Optional bool // optional chaining: the property is accessed only if Expr is not None
 	typing   ExpressionType
+	extern   *ExternDeclaration
 }
 
 func (p *PropertyAccessExpression) getChildren() []Node {
@@ -27,15 +29,32 @@ func (p *PropertyAccessExpression) Loc() Loc {
 }
 func (p *PropertyAccessExpression) Type() ExpressionType { return p.typing }
 
+// If the property is a member bound to a JS member, return its declaration.
+// Else return nil.
+func (p *PropertyAccessExpression) Extern() *ExternDeclaration { return p.extern }
+
 func (expr *PropertyAccessExpression) typeCheck(p *Parser) {
 	expr.Expr.typeCheck(p)
-	switch deref(expr.Expr.Type()).(type) {
+	expr.typing = nil
+	object := expr.Expr.Type()
+	if expr.Optional {
+		object = getOptionPayload(object)
+		if object == nil {
+			p.error(expr.Expr, OptionExpected, expr.Expr.Type())
+			expr.typing = Unknown{}
+			return
+		}
+	}
+	switch deref(object).(type) {
 	case Tuple:
-		typeCheckTupleIndexAccess(p, expr)
+		typeCheckTupleIndexAccess(p, expr, object)
 	case Type:
 		typeCheckSumConstructorAccess(p, expr)
 	default:
-		typeCheckPropertyAccess(p, expr)
+		typeCheckPropertyAccess(p, expr, object)
+	}
+	if expr.Optional {
+		expr.typing = makeOptional(expr.typing)
 	}
 }
 
@@ -46,17 +65,31 @@ func parsePropertyAccess(p *Parser, left Expression) Expression {
 			return parseTraitExpression(p, left)
 		}
 	}
+	return &PropertyAccessExpression{Expr: left, Property: parseProperty(p)}
+}
+
+// Parse an optional property access: Expr?.Property
+func parseOptionalPropertyAccess(p *Parser, left Expression) Expression {
+	p.Consume() // ?.
+	return &PropertyAccessExpression{
+		Expr:     left,
+		Property: parseProperty(p),
+		Optional: true,
+	}
+}
+
+func parseProperty(p *Parser) Expression {
 	prop := fallback(p)
 	switch prop.(type) {
 	case *Identifier, *Literal:
 	default:
 		p.error(prop, IdentifierExpected)
 	}
-	return &PropertyAccessExpression{Expr: left, Property: prop}
+	return prop
 }
 
 // check accessing a tuple's index: tuple.0
-func typeCheckTupleIndexAccess(p *Parser, expr *PropertyAccessExpression) {
+func typeCheckTupleIndexAccess(p *Parser, expr *PropertyAccessExpression, object ExpressionType) {
 	property, ok := expr.Property.(*Literal)
 	if !ok {
 		expr.typing = Unknown{}
@@ -72,7 +105,7 @@ func typeCheckTupleIndexAccess(p *Parser, expr *PropertyAccessExpression) {
 		expr.typing = Unknown{}
 		return
 	}
-	elements := deref(expr.Expr.Type()).(Tuple).Elements
+	elements := deref(object).(Tuple).Elements
 	if number > len(elements)-1 || number < 0 {
 		p.error(property, OutOfRange, len(elements), number)
 		expr.typing = Unknown{}
@@ -116,7 +149,7 @@ func getSumTypeConstructor(t Type, name string) ExpressionType {
 }
 
 // check accessing an object's property or method: object.property
-func typeCheckPropertyAccess(p *Parser, expr *PropertyAccessExpression) {
+func typeCheckPropertyAccess(p *Parser, expr *PropertyAccessExpression, object ExpressionType) {
 	property, ok := expr.Property.(*Identifier)
 	if expr.Property != nil && !ok {
 		expr.typing = Unknown{}
@@ -127,11 +160,29 @@ func typeCheckPropertyAccess(p *Parser, expr *PropertyAccessExpression) {
 		name = property.Token.Text()
 	}
 
-	switch t := deref(expr.Expr.Type()).(type) {
+	expr.extern = nil
+	switch t := deref(object).(type) {
 	case TypeAlias:
-		expr.typing = getAliasProperty(t, name)
+		args := make([]ExpressionType, len(t.Params))
+		for i, param := range t.Params {
+			args[i] = param.Value
+		}
+		expr.typing, expr.extern = getDeclaredMethod(p, t.Name, name, args)
+		if expr.typing == nil {
+			expr.typing = getAliasProperty(t, name)
+		}
 	case List:
-		expr.typing = getListMethod(t, name)
+		args := []ExpressionType{t.Element}
+		expr.typing, expr.extern = getDeclaredMethod(p, "List", name, args)
+	case Range:
+		args := []ExpressionType{t.operands}
+		expr.typing, expr.extern = getDeclaredMethod(p, "Range", name, args)
+	case String:
+		expr.typing, expr.extern = getDeclaredMethod(p, "String", name, nil)
+	case Trait:
+		if member, ok := t.Members[name]; ok {
+			expr.typing = member
+		}
 	}
 	if expr.typing == nil {
 		p.error(expr.Property, PropertyDoesNotExist, name)
@@ -143,6 +194,13 @@ func typeCheckPropertyAccess(p *Parser, expr *PropertyAccessExpression) {
 // Also checks in alias's methods.
 func getAliasProperty(t TypeAlias, name string) ExpressionType {
 	if method, ok := t.Methods[name]; ok {
+		scope := NewScope(ProgramScope)
+		for _, param := range t.Params {
+			if param.Value != nil {
+				scope.Add(param.Name, Loc{}, param.Value)
+			}
+		}
+		method, _ = method.build(scope, nil)
 		return method
 	}
 	object, ok := t.Ref.(Object)
@@ -153,32 +211,35 @@ func getAliasProperty(t TypeAlias, name string) ExpressionType {
 	return res
 }
 
-func getListMethod(l List, name string) ExpressionType {
-	switch name {
-	case "has":
-		return Function{
-			Params:   &Tuple{[]ExpressionType{Number{}}},
-			Returned: Boolean{},
-		}
-	case "get":
-		return Function{
-			Params: &Tuple{[]ExpressionType{Number{}}},
-			// FIXME: proper error type
-			Returned: makeResultType(l.Element, nil),
-		}
-	case "set":
-		return Function{
-			Params: &Tuple{[]ExpressionType{Number{}, l.Element}},
-			// FIXME: proper error type
-			Returned: makeResultType(Nil{}, nil),
+// Get a method declared on the type of given name, with the type's params
+// replaced by the given arguments.
+// If the method is bound to a JS member, also return its declaration.
+func getDeclaredMethod(p *Parser, typeName string, name string, args []ExpressionType) (ExpressionType, *ExternDeclaration) {
+	variable, ok := p.scope.Find(typeName)
+	if !ok {
+		return nil, nil
+	}
+	t, _ := variable.Typing.(Type)
+	alias, ok := t.Value.(TypeAlias)
+	if !ok {
+		return nil, nil
+	}
+	method, ok := alias.Methods[name]
+	if !ok {
+		return nil, nil
+	}
+	scope := NewScope(ProgramScope)
+	for i, param := range alias.Params {
+		if i < len(args) && args[i] != nil {
+			scope.Add(param.Name, Loc{}, args[i])
 		}
-	default:
-		return nil
 	}
+	method, _ = method.build(scope, nil)
+	return method, variable.externMembers[name]
 }
 
 type TraitExpression struct {
-	Receiver *ParenthesizedExpression // Receiver.Expr is an Identifier
+	Receiver *ParenthesizedExpression // Receiver.Expr is a Param, like '(self Type)'
 	Def      *BracedExpression        // contains *TupleExpression
 }
 
@@ -190,45 +251,65 @@ func (t *TraitExpression) Loc() Loc {
 	return Loc{t.Receiver.loc.Start, t.Def.loc.End}
 }
 func (t *TraitExpression) Type() ExpressionType {
-	members := t.Def.Type().(Type).Value.(Object).Members
 	trait := map[string]ExpressionType{}
-	for _, member := range members {
-		trait[member.Name] = member.Type
+	for _, element := range t.Def.Expr.(*TupleExpression).Elements {
+		param, ok := element.(*Param)
+		if !ok || param.Identifier == nil || param.Complement == nil {
+			continue
+		}
+		if typing, ok := param.Complement.Type().(Type); ok {
+			trait[param.Identifier.Text()] = typing.Value
+		}
 	}
-	return Trait{
-		Self:    Generic{Name: t.Receiver.Expr.(*Identifier).Text()},
-		Members: trait,
+	self := Generic{Name: "_"}
+	if identifier := t.self(); identifier != nil {
+		self.Name = identifier.Text()
 	}
+	return Type{Trait{Self: self, Members: trait}}
 }
 func (t *TraitExpression) typeCheck(p *Parser) {
 	p.pushScope(NewScope(ProgramScope))
 	defer p.dropScope()
 
-	receiver := t.Receiver.Expr.(*Identifier)
-	if receiver != nil {
-		p.scope.Add(
-			receiver.Text(),
-			receiver.Loc(),
-			Generic{Name: receiver.Text()},
-		)
+	self := t.self()
+	if self == nil {
+		p.error(t.Receiver, ReceiverExpected)
+	} else {
+		// implicit declaration, so that it's not reported as unused
+		p.scope.Add(self.Text(), Loc{}, Type{Generic{Name: self.Text()}})
 	}
 
 	for _, element := range t.Def.Expr.(*TupleExpression).Elements {
 		param, ok := element.(*Param)
-		if !ok {
+		if !ok || param.Complement == nil {
 			continue
 		}
+		param.Complement.typeCheck(p)
 		typing, ok := param.Complement.Type().(Type)
 		if !ok {
-			p.error(param.Complement, FunctionTypeExpected)
+			p.error(param.Complement, TypeExpected)
 			continue
 		}
 		if _, ok := typing.Value.(Function); !ok {
-			p.error(param.Complement, FunctionTypeExpected)
+			p.error(param.Complement, FunctionTypeExpected, typing.Value)
 		}
 	}
 }
 
+// Get the identifier standing for the implementing type, like 'Type' in
+// '(self Type)'
+func (t *TraitExpression) self() *Identifier {
+	var expr Expression = t.Receiver.Expr
+	if param, ok := expr.(*Param); ok {
+		expr = param.Complement
+	}
+	identifier, ok := expr.(*Identifier)
+	if !ok || !identifier.IsType() {
+		return nil
+	}
+	return identifier
+}
+
 func parseTraitExpression(p *Parser, left Expression) Expression {
 	outer := p.allowCallExpr
 	p.allowCallExpr = false
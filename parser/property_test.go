@@ -56,7 +56,7 @@ func TestCheckPropertyAccessThroughRef(t *testing.T) {
 		Ref:  Object{Members: []ObjectMember{{"value", Number{}}}},
 	}
 	parser.scope.Add("BoxedNumber", Loc{}, Type{alias})
-	parser.scope.Add("ref", Loc{}, Ref{alias})
+	parser.scope.Add("ref", Loc{}, Ref{To: alias})
 	expr := PropertyAccessExpression{
 		Expr:     &Identifier{Token: literal{kind: Name, value: "ref"}},
 		Property: &Identifier{Token: literal{kind: Name, value: "value"}},
@@ -126,3 +126,56 @@ func TestListMethodAccess(t *testing.T) {
 	expr.typeCheck(parser)
 	testParserErrors(t, parser, 0)
 }
+
+func TestCheckOptionalPropertyAccess(t *testing.T) {
+	parser := MakeParser(nil)
+	alias := TypeAlias{
+		Name: "BoxedNumber",
+		Ref:  Object{Members: []ObjectMember{{"value", Number{}}}},
+	}
+	parser.scope.Add("BoxedNumber", Loc{}, Type{alias})
+	parser.scope.Add("option", Loc{}, makeOptionType(alias))
+	expr := PropertyAccessExpression{
+		Expr:     &Identifier{Token: literal{kind: Name, value: "option"}},
+		Property: &Identifier{Token: literal{kind: Name, value: "value"}},
+		Optional: true,
+	}
+	expr.typeCheck(parser)
+
+	if len(parser.errors) != 0 {
+		t.Fatalf("Expected no errors, got %#v", parser.errors)
+	}
+	if expr.Type().Text() != "?[number]" {
+		t.Fatalf("Expected ?[number], got %v", expr.Type().Text())
+	}
+}
+
+func TestOptionalChainOnNonOption(t *testing.T) {
+	source := "Boxed :: { value number }\n"
+	source += "box := Boxed{ value: 42 }\n"
+	source += "io.log(box?.value)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, OptionExpected)
+}
+
+func TestCheckTraitImplementation(t *testing.T) {
+	source := "Named :: (self Type).{\n"
+	source += "    name() -> string\n"
+	source += "}\n"
+	source += "Dog :: { n string }\n"
+	source += "(d Dog).name :: () => string { d.n }\n"
+	source += "Rock :: { weight number }\n"
+	source += "greet :: (named Named) => string { named.name() }\n"
+	source += "greet(Dog{n: \"Rex\"})\n"
+	source += "greet(Rock{weight: 2})"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, CannotAssignType)
+}
+
+func TestCheckTraitMembers(t *testing.T) {
+	source := "Named :: (self Type).{\n"
+	source += "    name number\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, FunctionTypeExpected)
+}
@@ -1,19 +1,37 @@
 package parser
 
+import "strconv"
+
+// Ranges of numbers, like '0..10' or '0..=10', are values that can be
+// stored, passed around and iterated on.
+// They can be stepped, with negative steps for descending ranges:
+//
+//	for i in 0..10 by 2 {}  // 0, 2, 4, 6, 8
+//	for i in 10..0 by -3 {} // 10, 7, 4, 1
+//
+// Steps cannot be 0. Ranges whose step is 0 at runtime are empty.
+//
+// Without left operand, like in 'list[..2]', ranges start at 0.
+// Exclusive ranges without right operand, like in 'list[1..]', are unbound.
+// Given a type, like in '(r ..number) => ...', ranges are type expressions.
 type RangeExpression struct {
 	Left     Expression
 	Right    Expression
 	Operator Token
+	Step     Expression // nil if the range is not stepped
 }
 
 func (r *RangeExpression) getChildren() []Node {
-	children := make([]Node, 0, 2)
+	children := make([]Node, 0, 3)
 	if r.Left != nil {
 		children = append(children, r.Left)
 	}
 	if r.Right != nil {
 		children = append(children, r.Right)
 	}
+	if r.Step != nil {
+		children = append(children, r.Step)
+	}
 	return children
 }
 
@@ -24,9 +42,12 @@ func (r *RangeExpression) Loc() Loc {
 	} else {
 		loc.Start = r.Operator.Loc().Start
 	}
-	if r.Right != nil {
+	switch {
+	case r.Step != nil:
+		loc.End = r.Step.Loc().End
+	case r.Right != nil:
 		loc.End = r.Right.Loc().End
-	} else {
+	default:
 		loc.End = r.Operator.Loc().End
 	}
 	return loc
@@ -39,6 +60,9 @@ func (r *RangeExpression) Type() ExpressionType {
 	} else if r.Right != nil {
 		typing = r.Right.Type()
 	}
+	if t, ok := typing.(Type); ok {
+		return Type{Range{t.Value}}
+	}
 	return Range{typing}
 }
 
@@ -68,13 +92,82 @@ func (p *Parser) parseRange() Expression {
 	} else {
 		right = p.parseBinaryExpression()
 	}
-	return &RangeExpression{left, right, operator}
+	return &RangeExpression{
+		Left:     left,
+		Right:    right,
+		Operator: operator,
+		Step:     parseRangeStep(p),
+	}
+}
+
+// Parse the 'by step' part of a range, if any
+func parseRangeStep(p *Parser) Expression {
+	if p.Peek().Kind() != ByKeyword {
+		return nil
+	}
+	p.Consume()
+	return p.parseBinaryExpression()
 }
 
 func (r *RangeExpression) typeCheck(p *Parser) {
-	r.Left.typeCheck(p)
-	r.Right.typeCheck(p)
+	if r.Left != nil {
+		r.Left.typeCheck(p)
+	}
+	if r.Right != nil {
+		r.Right.typeCheck(p)
+	}
 	if r.Left != nil && r.Right != nil && !Match(r.Left.Type(), r.Right.Type()) {
 		p.error(r, MismatchedTypes, r.Left.Type(), r.Right.Type())
 	}
+	if r.Step == nil {
+		return
+	}
+	r.Step.typeCheck(p)
+	if _, ok := r.Step.Type().(Number); !ok {
+		p.error(r.Step, NumberExpected, r.Step.Type())
+	} else if isZeroLiteral(r.Step) {
+		p.error(r.Step, ZeroStep)
+	}
+	for _, bound := range []Expression{r.Left, r.Right} {
+		if bound == nil {
+			continue
+		}
+		if _, ok := bound.Type().(Number); !ok {
+			p.error(bound, NumberExpected, bound.Type())
+		}
+	}
+}
+
+// Check if a range is known to be descending, that is if its step is a
+// negated number literal, like in '10..0 by -1'.
+// The direction of other steps is only known at runtime.
+func (r *RangeExpression) IsDescending() bool {
+	u, ok := r.Step.(*UnaryExpression)
+	if !ok || u.Operator.Kind() != Sub {
+		return false
+	}
+	_, ok = u.Operand.(*Literal)
+	return ok
+}
+
+// Check if an expression is a literal zero, like '0' or '-0'
+func isZeroLiteral(expr Expression) bool {
+	if u, ok := expr.(*UnaryExpression); ok && u.Operator.Kind() == Sub {
+		expr = u.Operand
+	}
+	literal, ok := expr.(*Literal)
+	if !ok || literal.Kind() != NumberLiteral {
+		return false
+	}
+	n, err := strconv.ParseFloat(literal.Text(), 64)
+	return err == nil && n == 0
+}
+
+// Check if the direction of a range is known before runtime
+func (r *RangeExpression) HasStaticDirection() bool {
+	if r.Step == nil || r.IsDescending() {
+		return true
+	}
+	_, ok := r.Step.(*Literal)
+	return ok
 }
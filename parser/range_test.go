@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSteppedRange(t *testing.T) {
+	parser := MakeParser(strings.NewReader("10..0 by -2"))
+	expr := parser.parseRange()
+
+	if len(parser.errors) != 0 {
+		t.Fatalf("Expected no errors, got %#v", parser.errors)
+	}
+	r, ok := expr.(*RangeExpression)
+	if !ok {
+		t.Fatalf("Expected range, got %#v", expr)
+	}
+	if r.Step == nil {
+		t.Fatal("Expected a step")
+	}
+	if !r.IsDescending() {
+		t.Fatal("Expected range to be descending")
+	}
+}
+
+func TestCheckRangeValue(t *testing.T) {
+	source := "r := 0..10 by 2\n"
+	source += "n := r.len()\n"
+	source += "b := r.contains(4)\n"
+	source += "reversed := r.reversed()"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	if text := statements[0].(*Assignment).Value.Type().Text(); text != "..number" {
+		t.Fatalf("Expected ..number, got %v", text)
+	}
+	if text := statements[3].(*Assignment).Value.Type().Text(); text != "..number" {
+		t.Fatalf("Expected ..number, got %v", text)
+	}
+}
+
+func TestCheckRangeTypeExpression(t *testing.T) {
+	source := "sum :: (r ..number) => number {\n"
+	source += "    total := 0\n"
+	source += "    for n in r {\n"
+	source += "        total += n\n"
+	source += "    }\n"
+	source += "    total\n"
+	source += "}\n"
+	source += "sum(1..=4)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+}
+
+func TestCheckRangeStep(t *testing.T) {
+	_, errors := Parse(strings.NewReader("for i in 0..10 by \"2\" {}"))
+	testErrorKinds(t, errors, NumberExpected, UnusedVariable)
+}
+
+func TestCheckZeroStep(t *testing.T) {
+	_, errors := Parse(strings.NewReader("for i in 10..0 by 0 { i }"))
+	testErrorKinds(t, errors, ZeroStep)
+
+	_, errors = Parse(strings.NewReader("for i in 10..0 by -0 { i }"))
+	testErrorKinds(t, errors, ZeroStep)
+}
+
+func TestCheckSteppedSlice(t *testing.T) {
+	source := "list := []number{1, 2, 3}\n"
+	source += "list[0..2 by 2]"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, UnexpectedExpression)
+}
@@ -1,16 +1,98 @@
 package parser
 
+import (
+	"maps"
+	"slices"
+)
+
 type Variable struct {
 	declaredAt Loc
 	Typing     ExpressionType
 	writes     []Node
 	reads      []Loc
+
+	// If not nil, this variable is a narrowed view of the given one,
+	// and reads and writes are recorded on the original variable.
+	narrows *Variable
+	// True if the narrowed type is the payload of the original sum type
+	unwrapped bool
+	// If not nil, this variable is bound to a JS value
+	extern *ExternDeclaration
+	// For types, the members bound to JS members
+	externMembers map[string]*ExternDeclaration
+	// True for definitions, like 'f :: () => {}', and receivers of methods
+	// not declared with 'mut', which cannot be modified
+	readonly bool
+	// True for receivers of methods. Writing a receiver counts as using it:
+	// writes either modify the caller's value, with 'mut', or are reported.
+	receiver bool
+	// The scope declaring this variable
+	scope *Scope
+	// For variables holding references, the variable they point to, if the
+	// references are bound to a scope, see escape.go
+	target *Identifier
 }
 
-func (v *Variable) readAt(l Loc)   { v.reads = append(v.reads, l) }
-func (v *Variable) writeAt(n Node) { v.writes = append(v.writes, n) }
+func (v *Variable) readAt(l Loc) {
+	if v.narrows != nil {
+		v.narrows.readAt(l)
+		return
+	}
+	v.reads = append(v.reads, l)
+}
+func (v *Variable) writeAt(n Node) {
+	if v.narrows != nil {
+		v.narrows.writeAt(n)
+		return
+	}
+	v.writes = append(v.writes, n)
+}
 
-func (v *Variable) Writes() []Node { return v.writes }
+// Check if the variable is read, or written if its writes reach another
+// value, like for receivers and references
+func (v *Variable) isUsed() bool {
+	if len(v.reads) > 0 {
+		return true
+	}
+	_, isRef := v.Typing.(Ref)
+	return (v.receiver || isRef) && len(v.writes) > 0
+}
+
+// Get the declared variable behind a narrowed view
+func (v *Variable) original() *Variable {
+	if v.narrows != nil {
+		return v.narrows
+	}
+	return v
+}
+
+func (v *Variable) Writes() []Node {
+	if v.narrows != nil {
+		return v.narrows.Writes()
+	}
+	return v.writes
+}
+
+// Check if the variable is declared by the prelude
+func (v *Variable) IsPrelude() bool {
+	scope := v.original().scope
+	return scope != nil && scope.prelude
+}
+
+// Check if a reference to the variable itself is taken somewhere, like in
+// '&x'. References to its fields, like '&x.key', are not counted.
+func (v *Variable) IsReferenced() bool {
+	for _, write := range v.Writes() {
+		unary, ok := write.(*UnaryExpression)
+		if !ok || unary.Operator.Kind() != BinaryAnd {
+			continue
+		}
+		if _, ok := unary.Operand.(*Identifier); ok {
+			return true
+		}
+	}
+	return false
+}
 
 type ScopeKind uint8
 
@@ -19,12 +101,22 @@ const (
 	BlockScope
 	FunctionScope
 	LoopScope
+	GroupScope
 )
 
 type Scope struct {
 	variables map[string]*Variable
+	narrowed  map[string]*Variable // flow-sensitive refinements of outer variables
 	kind      ScopeKind
 	outer     *Scope
+
+	// For labeled loops, the loop's label, and whether a 'break' or
+	// 'continue' refers to it
+	label     string
+	labelUsed bool
+
+	// True for the scope of the prelude and its copies
+	prelude bool
 }
 
 func NewScope(kind ScopeKind) *Scope {
@@ -35,6 +127,9 @@ func NewScope(kind ScopeKind) *Scope {
 }
 
 func (s Scope) Find(name string) (*Variable, bool) {
+	if variable, ok := s.narrowed[name]; ok {
+		return variable, true
+	}
 	variable, ok := s.variables[name]
 	if ok {
 		return variable, true
@@ -56,6 +151,18 @@ func (s Scope) Has(name string) bool {
 	return false
 }
 
+// Names returns the names of all the variables visible from this scope
+func (s Scope) Names() []string {
+	names := []string{}
+	for name := range s.variables {
+		names = append(names, name)
+	}
+	if s.outer != nil {
+		names = append(names, s.outer.Names()...)
+	}
+	return names
+}
+
 func (s *Scope) Add(name string, declaredAt Loc, typing ExpressionType) {
 	if name == "" || name == "_" {
 		return
@@ -63,10 +170,132 @@ func (s *Scope) Add(name string, declaredAt Loc, typing ExpressionType) {
 	s.variables[name] = &Variable{
 		declaredAt: declaredAt,
 		Typing:     typing,
+		scope:      s,
+	}
+}
+
+// Declare a variable for the given identifier, and link the identifier to it
+func (s *Scope) bind(identifier *Identifier, declaredAt Loc, typing ExpressionType) {
+	s.Add(identifier.Text(), declaredAt, typing)
+	identifier.variable = s.variables[identifier.Text()]
+}
+
+// Refine the type of a variable for the rest of this scope.
+// The variable keeps its identity: reads and writes are recorded on the
+// original declaration.
+func (s *Scope) narrow(name string, typing ExpressionType, unwrapped bool) {
+	variable, ok := s.Find(name)
+	if !ok {
+		return
+	}
+	if variable.narrows != nil {
+		variable = variable.narrows
+	}
+	if s.narrowed == nil {
+		s.narrowed = map[string]*Variable{}
+	}
+	s.narrowed[name] = &Variable{
+		declaredAt: variable.declaredAt,
+		Typing:     typing,
+		narrows:    variable,
+		unwrapped:  unwrapped,
+	}
+}
+
+// Drop all refinements of a variable, for example because it has been
+// re-assigned. Return the original variable.
+func (s *Scope) widen(name string) (*Variable, bool) {
+	for scope := s; scope != nil; scope = scope.outer {
+		delete(scope.narrowed, name)
+		if variable, ok := scope.variables[name]; ok {
+			return variable, true
+		}
+	}
+	return nil, false
+}
+
+// Find the innermost scope of the given kind
+func (s *Scope) enclosing(kind ScopeKind) *Scope {
+	for scope := s; scope != nil; scope = scope.outer {
+		if scope.kind == kind {
+			return scope
+		}
 	}
+	return nil
 }
 
-func (s *Scope) AddMethod(name string, self TypeAlias, signature Function) {
+// Check if the scope is the given one, or is nested in it
+func (s *Scope) within(outer *Scope) bool {
+	for scope := s; scope != nil; scope = scope.outer {
+		if scope == outer {
+			return true
+		}
+	}
+	return false
+}
+
+// Find the scope of the loop with the given label, in the current function
+func (s *Scope) findLabel(name string) (*Scope, bool) {
+	for scope := s; scope != nil && scope.kind != FunctionScope; scope = scope.outer {
+		if scope.kind == LoopScope && scope.label == name {
+			return scope, true
+		}
+	}
+	return nil, false
+}
+
+// Check if the scope is in a task group of the current function
+func (s *Scope) inGroup() bool {
+	for scope := s; scope != nil && scope.kind != FunctionScope; scope = scope.outer {
+		if scope.kind == GroupScope {
+			return true
+		}
+	}
+	return false
+}
+
+// Check if the given variable is declared outside of the innermost task group
+// of the current function
+func (s *Scope) isOutsideGroup(name string) bool {
+	for scope := s; scope != nil && scope.kind != FunctionScope; scope = scope.outer {
+		if _, ok := scope.variables[name]; ok {
+			return false
+		}
+		if scope.kind == GroupScope {
+			return true
+		}
+	}
+	return false
+}
+
+// Copy the scope and its outer scopes, so that the copy can be used without
+// modifying the original, like by recording reads or declaring methods
+func (s *Scope) clone() *Scope {
+	c := NewScope(s.kind)
+	c.label = s.label
+	c.prelude = s.prelude
+	if s.outer != nil {
+		c.outer = s.outer.clone()
+	}
+	for name, variable := range s.variables {
+		copied := *variable
+		// appending to clipped slices never writes to the original arrays
+		copied.reads = slices.Clip(variable.reads)
+		copied.writes = slices.Clip(variable.writes)
+		copied.scope = c
+		copied.externMembers = maps.Clone(variable.externMembers)
+		if t, ok := variable.Typing.(Type); ok {
+			if alias, ok := t.Value.(TypeAlias); ok {
+				alias.Methods = maps.Clone(alias.Methods)
+				copied.Typing = Type{alias}
+			}
+		}
+		c.variables[name] = &copied
+	}
+	return c
+}
+
+func (s *Scope) AddMethod(name string, self TypeAlias, signature ExpressionType) {
 	t, ok := s.Find(self.Name)
 	if !ok {
 		return
@@ -144,21 +373,6 @@ func makeResultType(ok ExpressionType, err ExpressionType) TypeAlias {
 	return alias
 }
 
-var mapMethods = map[string]ExpressionType{
-	"has": Function{
-		Params:   &Tuple{[]ExpressionType{Generic{Name: "Key"}}},
-		Returned: Boolean{},
-	},
-	"get": Function{
-		Params:   &Tuple{[]ExpressionType{Generic{Name: "Key"}}},
-		Returned: makeOptionType(Generic{Name: "Value"}),
-	},
-	"set": Function{
-		Params:   &Tuple{[]ExpressionType{Generic{Name: "Key"}, Generic{Name: "Value"}}},
-		Returned: Nil{},
-	},
-}
-
 // utility to create map types
 func makeMapType(key ExpressionType, value ExpressionType) TypeAlias {
 	alias := TypeAlias{
@@ -171,11 +385,28 @@ func makeMapType(key ExpressionType, value ExpressionType) TypeAlias {
 			Generic{Name: "Key", Value: key},
 			Generic{Name: "Value", Value: value},
 		},
-		Methods: mapMethods,
 	}
 	return alias
 }
 
+// utility to create set types
+func makeSetType(element ExpressionType) TypeAlias {
+	return TypeAlias{
+		Name:   "Set",
+		Params: []Generic{{Name: "Type", Value: element}},
+		Ref:    Set{Generic{Name: "Type", Value: element}},
+	}
+}
+
+// utility to create channel types
+func makeChanType(element ExpressionType) TypeAlias {
+	return TypeAlias{
+		Name:   "Chan",
+		Params: []Generic{{Name: "Type", Value: element}},
+		Ref:    newObject(),
+	}
+}
+
 // utility to create map types
 func makePromise(t ExpressionType) TypeAlias {
 	return TypeAlias{
@@ -190,8 +421,9 @@ func makePromise(t ExpressionType) TypeAlias {
 	}
 }
 
-// The scope containing the standard library
-var std = Scope{
+// The scope containing the type constructors that cannot be written in the
+// language itself. The rest of the standard library is in the prelude.
+var builtins = Scope{
 	variables: map[string]*Variable{
 		"List": {
 			Typing: Type{TypeAlias{
@@ -203,33 +435,50 @@ var std = Scope{
 		"Map": {
 			Typing: Type{makeMapType(nil, nil)},
 		},
+		"Set": {
+			Typing: Type{makeSetType(nil)},
+		},
+		// ranges' methods are declared on this type, see range.go
+		"Range": {
+			Typing: Type{TypeAlias{
+				Name:   "Range",
+				Params: []Generic{{Name: "Type"}},
+				Ref:    Range{Generic{Name: "Type"}},
+			}},
+		},
+		// channels' methods are declared in the prelude, see select.go
+		"Chan": {
+			Typing: Type{makeChanType(nil)},
+		},
+		// strings' methods are declared on this type
+		"String": {
+			Typing: Type{TypeAlias{Name: "String", Ref: String{}}},
+		},
 		"...": {
 			Typing: Type{makePromise(nil)},
 		},
 		"?": {
 			Typing: Type{optionType},
 		},
+		// types of generator functions, see generator.go
+		"Generator": {
+			Typing: Type{makeGeneratorType(nil, false)},
+		},
+		"AsyncGenerator": {
+			Typing: Type{makeGeneratorType(nil, true)},
+		},
 		"!": {
 			Typing: Type{makeResultType(nil, nil)},
 		},
-
-		"io": {
-			Typing: TypeAlias{
-				Name: "IO",
-				Ref: Object{
-					Members: []ObjectMember{
-						{
-							Name: "log",
-							Type: Function{
-								Params: &Tuple{[]ExpressionType{
-									Unknown{},
-								}},
-								Returned: Nil{},
-							},
-						},
-					},
-				},
-			},
+		// JSON conversions are derived from types, see json.go
+		"toJson": {
+			Typing: Function{Returned: String{}, Intrinsic: EncodeIntrinsic},
+		},
+		"fromJson": {
+			Typing: Function{Intrinsic: DecodeIntrinsic},
+		},
+		"unknown": {
+			Typing: Type{Unknown{}},
 		},
 	},
 }
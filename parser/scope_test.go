@@ -21,3 +21,22 @@ func TestStdIO(t *testing.T) {
 	parser.parseExpression().typeCheck(parser)
 	testParserErrors(t, parser, 0)
 }
+
+func TestReferencedVariable(t *testing.T) {
+	source := "Type :: { value number }\n"
+	source += "x := Type{ value: 42 }\n"
+	source += "y := Type{ value: 42 }\n"
+	source += "a := &x\n"
+	source += "b := &y.value"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	x := statements[1].(*Assignment).Pattern.(*Identifier).Variable()
+	if x == nil || !x.IsReferenced() {
+		t.Fatal("Expected 'x' to be referenced")
+	}
+	y := statements[2].(*Assignment).Pattern.(*Identifier).Variable()
+	if y == nil || y.IsReferenced() {
+		t.Fatal("Expected 'y' not to be referenced, only its field")
+	}
+}
@@ -0,0 +1,193 @@
+package parser
+
+import "slices"
+
+// Select expressions wait for the first of several channel operations:
+//
+//	select {
+//	case message := inbox.receive():
+//	    handle(message)
+//	case outbox.send(value):
+//	    sent++
+//	case _:
+//	    // no operation was ready
+//	}
+//
+// Operations that are ready are taken in order. Without default case, the
+// first operation to become ready is taken, and the other ones are dropped.
+// Received values are options, which are None once the channel is closed.
+type SelectExpression struct {
+	Keyword Token
+	Cases   []SelectCase
+	end     Position
+}
+
+type SelectCase struct {
+	Operation  Node // like 'c.send(x)', 'c.receive()' or 'x := c.receive()'
+	Statements []Node
+}
+
+func (c SelectCase) Type() ExpressionType { return getCaseType(c.Statements) }
+
+// Check if the case is taken when no operation is ready, like 'case _:'
+func (c SelectCase) IsDefault() bool {
+	identifier, ok := c.Operation.(*Identifier)
+	return ok && identifier.Text() == "_"
+}
+
+// Get the channel operation of the case, if any
+func (c SelectCase) Call() *CallExpression {
+	operation := c.Operation
+	if a, ok := operation.(*Assignment); ok {
+		operation = a.Value
+	}
+	call, _ := operation.(*CallExpression)
+	return call
+}
+
+// Get the variable declared with the received value, if any
+func (c SelectCase) Binding() Expression {
+	if a, ok := c.Operation.(*Assignment); ok {
+		return a.Pattern
+	}
+	return nil
+}
+
+func (s *SelectExpression) getChildren() []Node {
+	children := []Node{}
+	for i := range s.Cases {
+		if s.Cases[i].Operation != nil {
+			children = append(children, s.Cases[i].Operation)
+		}
+		children = append(children, s.Cases[i].Statements...)
+	}
+	return children
+}
+
+func (s *SelectExpression) Loc() Loc {
+	loc := s.Keyword.Loc()
+	if s.end != (Position{}) {
+		loc.End = s.end
+	}
+	return loc
+}
+
+func (s *SelectExpression) Type() ExpressionType {
+	if len(s.Cases) == 0 {
+		return Nil{}
+	}
+	return s.Cases[0].Type()
+}
+
+func (s *SelectExpression) typeCheck(p *Parser) {
+	for i := range s.Cases {
+		p.pushScope(NewScope(BlockScope))
+		typeCheckSelectOperation(p, s.Cases[i])
+		for _, statement := range s.Cases[i].Statements {
+			typeCheckStatement(p, statement)
+		}
+		p.dropScope()
+	}
+}
+
+func typeCheckSelectOperation(p *Parser, c SelectCase) {
+	if c.Operation == nil || c.IsDefault() {
+		return
+	}
+	c.Operation.typeCheck(p)
+	call := c.Call()
+	operation := GetChannelOperation(call)
+	if a, ok := c.Operation.(*Assignment); ok {
+		if a.Operator.Kind() != Declare || operation != "receive" {
+			p.error(c.Operation, ChannelOperationExpected)
+		}
+		return
+	}
+	if operation == "" {
+		p.error(c.Operation, ChannelOperationExpected)
+	}
+}
+
+// Get the name of the channel method called, "send" or "receive", or an
+// empty string if the call is not a channel operation
+func GetChannelOperation(call *CallExpression) string {
+	if call == nil {
+		return ""
+	}
+	access, ok := call.Callee.(*PropertyAccessExpression)
+	if !ok || access.Optional {
+		return ""
+	}
+	alias, ok := access.Expr.Type().(TypeAlias)
+	if !ok || alias.Name != "Chan" {
+		return ""
+	}
+	identifier, ok := access.Property.(*Identifier)
+	if !ok {
+		return ""
+	}
+	switch name := identifier.Text(); name {
+	case "send", "receive":
+		return name
+	default:
+		return ""
+	}
+}
+
+func (p *Parser) parseSelectExpression() *SelectExpression {
+	keyword := p.Consume()
+	if p.Peek().Kind() != LeftBrace && !recover(p, LeftBrace) {
+		return &SelectExpression{Keyword: keyword}
+	}
+	p.Consume()
+	p.DiscardLineBreaks()
+
+	cases := []SelectCase{}
+	stopAt := []TokenKind{RightBrace, EOF}
+	for !slices.Contains(stopAt, p.Peek().Kind()) {
+		cases = append(cases, parseSelectCase(p))
+	}
+
+	next := p.Peek()
+	end := next.Loc().End
+	if next.Kind() == RightBrace {
+		p.Consume()
+	} else {
+		p.error(&Literal{next}, RightBraceExpected)
+	}
+	expr := &SelectExpression{keyword, cases, end}
+	validateSelectCases(p, expr)
+	return expr
+}
+
+func parseSelectCase(p *Parser) SelectCase {
+	if p.Peek().Kind() != CaseKeyword {
+		p.error(&Literal{p.Peek()}, TokenExpected, token{kind: CaseKeyword})
+		return SelectCase{Statements: parseCaseBody(p)}
+	}
+	p.Consume()
+	outer := p.preventColon
+	p.preventColon = true
+	operation := p.parseAssignment()
+	p.preventColon = outer
+	parseCaseColon(p)
+	return SelectCase{
+		Operation:  operation,
+		Statements: parseCaseBody(p),
+	}
+}
+
+// Report default cases that are not last, and selects without operations
+func validateSelectCases(p *Parser, s *SelectExpression) {
+	operations := 0
+	for i, c := range s.Cases {
+		if !c.IsDefault() {
+			operations++
+		} else if i != len(s.Cases)-1 {
+			p.error(c.Operation, CatchallNotLast)
+		}
+	}
+	if operations == 0 {
+		p.error(s, MissingElements, "at least 1", 0)
+	}
+}
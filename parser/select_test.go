@@ -0,0 +1,104 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSelect(t *testing.T) {
+	source := "a := Chan[number]{}\n"
+	source += "b := Chan[string]{1}\n"
+	source += "select {\n"
+	source += "case x := a.receive():\n"
+	source += "    x ?? 0\n"
+	source += "case b.send(\"hi\"):\n"
+	source += "    1\n"
+	source += "case _:\n"
+	source += "    2\n"
+	source += "}"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	s, ok := statements[2].(*SelectExpression)
+	if !ok {
+		t.Fatalf("Expected select expression, got %T", statements[2])
+	}
+	if len(s.Cases) != 3 {
+		t.Fatalf("Expected 3 cases, got %v", len(s.Cases))
+	}
+	if s.Cases[0].Binding() == nil {
+		t.Fatal("Expected received value to be bound")
+	}
+	if op := GetChannelOperation(s.Cases[1].Call()); op != "send" {
+		t.Fatalf("Expected send operation, got '%v'", op)
+	}
+	if !s.Cases[2].IsDefault() {
+		t.Fatal("Expected default case")
+	}
+	if text := s.Type().Text(); text != "number" {
+		t.Fatalf("Expected number, got %v", text)
+	}
+}
+
+func TestCheckSelectBinding(t *testing.T) {
+	source := "a := Chan[number]{}\n"
+	source += "select {\n"
+	source += "case x := a.receive():\n"
+	source += "    x\n"
+	source += "}"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	s := statements[1].(*SelectExpression)
+	if text := s.Type().Text(); text != "?[number]" {
+		t.Fatalf("Expected ?[number], got %v", text)
+	}
+}
+
+func TestCheckSelectNotChannel(t *testing.T) {
+	source := "extern fetch :: async () -> string\n"
+	source += "a := Chan[number]{}\n"
+	source += "select {\n"
+	source += "case fetch():\n"
+	source += "    1\n"
+	source += "case a.receive():\n"
+	source += "    2\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, ChannelOperationExpected)
+}
+
+func TestCheckSelectDefaultNotLast(t *testing.T) {
+	source := "a := Chan[number]{}\n"
+	source += "select {\n"
+	source += "case _:\n"
+	source += "    1\n"
+	source += "case a.receive():\n"
+	source += "    2\n"
+	source += "}"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, CatchallNotLast)
+}
+
+func TestCheckChanInstance(t *testing.T) {
+	_, errors := Parse(strings.NewReader("c := Chan[number]{\"a\"}"))
+	testErrorKinds(t, errors, NumberExpected)
+
+	_, errors = Parse(strings.NewReader("c := Chan{}"))
+	testErrorKinds(t, errors, MissingTypeArgs)
+}
+
+func TestCheckChanMethods(t *testing.T) {
+	source := "f :: (c Chan[number]) => ?number {\n"
+	source += "    c.send(1)\n"
+	source += "    c.close()\n"
+	source += "    c.receive()\n"
+	source += "}"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	f := statements[0].(*Assignment).Value.Type().(Function)
+	if !f.Async {
+		t.Fatal("Expected function using a channel to be async")
+	}
+}
@@ -94,7 +94,7 @@ func (p *Parser) parseSumType() Expression {
 		if !slices.Contains(expected, p.Peek().Kind()) {
 			recover(p, BinaryOr)
 		}
-		p.DiscardLineBreaks()
+		p.DiscardLineBreaksBefore(BinaryOr)
 	}
 	if len(constructors) < 2 {
 		p.error(&Block{loc: constructors[0].Loc()}, MissingElements, "at least 2", len(constructors))
@@ -37,6 +37,11 @@ func (l *Literal) Type() ExpressionType {
 type Identifier struct {
 	Token
 	typing ExpressionType
+	// If the identifier refers to a variable narrowed to the payload of its
+	// sum type, this is the original (wide) type.
+	unwrapped ExpressionType
+	// The declared variable this identifier refers to, if any
+	variable *Variable
 }
 
 func (i *Identifier) getChildren() []Node {
@@ -49,20 +54,44 @@ func (i *Identifier) IsType() bool {
 
 func (i *Identifier) typeCheck(p *Parser) {
 	name := i.Text()
-	if variable, ok := p.scope.Find(name); ok {
-		if p.writing != nil {
-			variable.writeAt(p.writing)
-		} else {
-			variable.readAt(i.Loc())
-		}
-		i.typing = variable.Typing
-	} else {
+	variable, ok := p.scope.Find(name)
+	if !ok {
 		i.typing = Unknown{}
+		i.variable = nil
+		return
+	}
+	if p.writing != nil {
+		// writing a variable invalidates any narrowing
+		variable, _ = p.scope.widen(name)
+		variable.writeAt(p.writing)
+	} else {
+		variable.readAt(i.Loc())
+	}
+	i.typing = variable.Typing
+	i.variable = variable.original()
+	i.unwrapped = nil
+	if variable.unwrapped {
+		i.unwrapped = variable.narrows.Typing
 	}
 }
 
 func (i *Identifier) Type() ExpressionType { return i.typing }
 
+// Get the declared variable this identifier refers to, if any
+func (i *Identifier) Variable() *Variable { return i.variable }
+
+// If the identifier refers to a variable narrowed to the payload of its sum
+// type, return the original type of the variable. Else return nil.
+func (i *Identifier) UnwrappedFrom() ExpressionType { return i.unwrapped }
+
+// If the identifier refers to an extern declaration, return it. Else return nil.
+func (i *Identifier) Extern() *ExternDeclaration {
+	if i.variable == nil {
+		return nil
+	}
+	return i.variable.extern
+}
+
 func (p *Parser) parseToken() Expression {
 	token := p.Peek()
 	switch token.Kind() {
@@ -57,3 +57,41 @@ func TestParseCatchall(t *testing.T) {
 		t.Fatalf("Expected Identifier, got %#v", expr)
 	}
 }
+
+func TestParseUnderscoredIdentifier(t *testing.T) {
+	parser := MakeParser(strings.NewReader("__my_variable"))
+	expr := parser.parseToken()
+	if len(parser.errors) > 0 {
+		t.Fatalf("Expected no errors, got %#v", parser.errors)
+	}
+	identifier, ok := expr.(*Identifier)
+	if !ok {
+		t.Fatalf("Expected Identifier, got %#v", expr)
+	}
+	if identifier.Text() != "__my_variable" {
+		t.Fatalf("Expected '__my_variable', got '%v'", identifier.Text())
+	}
+}
+
+func TestParseEmptyString(t *testing.T) {
+	parser := MakeParser(strings.NewReader(`""`))
+	expr := parser.parseToken()
+	if len(parser.errors) > 0 {
+		t.Fatalf("Expected no errors, got %#v", parser.errors)
+	}
+	literal, ok := expr.(*Literal)
+	if !ok || literal.Token.Text() != `""` {
+		t.Fatalf("Expected empty string, got %#v", expr)
+	}
+}
+
+func TestParseTokenAcrossReads(t *testing.T) {
+	// longer than the scanner's first read
+	name := strings.Repeat("a", 5000)
+	parser := MakeParser(strings.NewReader(name))
+	expr := parser.parseToken()
+	identifier, ok := expr.(*Identifier)
+	if !ok || identifier.Text() != name {
+		t.Fatalf("Expected a single identifier, got %#v", expr)
+	}
+}
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"bufio"
+	"bytes"
 	"io"
 	"regexp"
 )
@@ -36,8 +37,10 @@ const (
 	ElseKeyword     //else
 	MatchKeyword    // match
 	CaseKeyword     // case
+	SelectKeyword   // select
 	ForKeyword      // for
 	InKeyword       // in
+	ByKeyword       // by
 	BreakKeyword    // break
 	ContinueKeyword // continue
 	ReturnKeyword   // return
@@ -46,6 +49,10 @@ const (
 	CatchKeyword    // catch
 	AsyncKeyword    // async
 	AwaitKeyword    // await
+	YieldKeyword    // yield
+	ExternKeyword   // extern
+	FromKeyword     // from
+	MutKeyword      // mut
 
 	Add        // +
 	Concat     // ++
@@ -61,6 +68,8 @@ const (
 	BinaryOr   // |
 
 	QuestionMark // ?
+	QuestionDot  // ?.
+	Coalesce     // ??
 
 	Less         // <
 	Greater      // >
@@ -74,6 +83,7 @@ const (
 	Assign         // =
 	ExclusiveRange // ..
 	InclusiveRange // ..=
+	Ellipsis       // ...
 	SlimArrow      // ->
 	FatArrow       // =>
 
@@ -147,6 +157,8 @@ func (t token) Text() string {
 		return "==="
 	case NotEqual:
 		return "!=="
+	case Coalesce:
+		return "??"
 	default:
 		return ""
 	}
@@ -176,10 +188,11 @@ func (l literal) Loc() Loc {
 
 var blank = regexp.MustCompile(`^[\t\f\r ]+`)
 var newLine = regexp.MustCompile(`^\s+`)
+var comment = regexp.MustCompile(`^//[^\n]*`)
 var number = regexp.MustCompile(`^\d+`)
-var str = regexp.MustCompile(`^"(.*?)[^\\]"`)
-var word = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9]*`)
-var operator = regexp.MustCompile(`^(&&=|\|\|=|\+=|-=|\*=|/=|%=|\+\+?|->?|\*\*?|/|%|::|:=|\.\.=?|=>|<=?|>=?|={1,2}|!=?|\|{1,2}|\?|&&?)`)
+var str = regexp.MustCompile(`^"([^"\\\n]|\\.)*"`)
+var word = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*`)
+var operator = regexp.MustCompile(`^(&&=|\|\|=|\+=|-=|\*=|/=|%=|\+\+?|->?|\*\*?|/|%|::|:=|\.\.\.|\.\.=?|=>|<=?|>=?|={1,2}|!=?|\|{1,2}|\?[?.]?|&&?)`)
 var punctuation = regexp.MustCompile(`^(\[|\]|,|:|\(|\)|\{|\}|_|\.)`)
 
 func split(data []byte, atEOF bool) (advance int, token []byte, err error) {
@@ -194,12 +207,19 @@ func split(data []byte, atEOF bool) (advance int, token []byte, err error) {
 		token = str.Find(data)
 	case word.Match(data):
 		token = word.Find(data)
+	case comment.Match(data):
+		token = comment.Find(data)
 	case operator.Match(data):
 		token = operator.Find(data)
 	case punctuation.Match(data):
 		token = punctuation.Find(data)
 	}
 
+	// tokens, like unterminated strings, might go on in data not read yet
+	isCut := len(token) == len(data) || len(token) == 0 && !bytes.ContainsRune(data, '\n')
+	if !atEOF && isCut {
+		return 0, nil, nil
+	}
 	if len(token) != 0 {
 		return len(token), token, nil
 	}
@@ -211,18 +231,21 @@ type tokenizer struct {
 	cursor  Position
 	token   Token
 	ready   bool
+	// a line break consumed ahead of time, to be read again
+	restored Token
 }
 
 type Tokenizer interface {
 	Peek() Token
 	Consume() Token
 	DiscardLineBreaks()
+	DiscardLineBreaksBefore(kind TokenKind) bool
 }
 
 func NewTokenizer(reader io.Reader) *tokenizer {
 	scanner := bufio.NewScanner(reader)
 	scanner.Split(split)
-	return &tokenizer{scanner, Position{1, 1}, nil, false}
+	return &tokenizer{scanner: scanner, cursor: Position{1, 1}}
 }
 
 func (t *tokenizer) updateCursor(token string) {
@@ -254,10 +277,14 @@ func makeToken(text string, loc Loc) Token {
 		return token{MatchKeyword, loc}
 	case "case":
 		return token{CaseKeyword, loc}
+	case "select":
+		return token{SelectKeyword, loc}
 	case "for":
 		return token{ForKeyword, loc}
 	case "in":
 		return token{InKeyword, loc}
+	case "by":
+		return token{ByKeyword, loc}
 	case "break":
 		return token{BreakKeyword, loc}
 	case "continue":
@@ -274,6 +301,14 @@ func makeToken(text string, loc Loc) Token {
 		return token{AsyncKeyword, loc}
 	case "await":
 		return token{AwaitKeyword, loc}
+	case "yield":
+		return token{YieldKeyword, loc}
+	case "extern":
+		return token{ExternKeyword, loc}
+	case "from":
+		return token{FromKeyword, loc}
+	case "mut":
+		return token{MutKeyword, loc}
 	case "+":
 		return token{Add, loc}
 	case "++":
@@ -312,6 +347,10 @@ func makeToken(text string, loc Loc) Token {
 		return token{NotEqual, loc}
 	case "?":
 		return token{QuestionMark, loc}
+	case "?.":
+		return token{QuestionDot, loc}
+	case "??":
+		return token{Coalesce, loc}
 	case "[":
 		return token{LeftBracket, loc}
 	case "]":
@@ -340,6 +379,8 @@ func makeToken(text string, loc Loc) Token {
 		return token{ExclusiveRange, loc}
 	case "..=":
 		return token{InclusiveRange, loc}
+	case "...":
+		return token{Ellipsis, loc}
 	case "->":
 		return token{SlimArrow, loc}
 	case "=>":
@@ -382,7 +423,7 @@ func (t *tokenizer) next() bool {
 		return false
 	}
 	value := t.scanner.Text()
-	if blank.MatchString(value) {
+	if blank.MatchString(value) || comment.MatchString(value) {
 		t.updateCursor(value)
 		return t.next()
 	}
@@ -394,6 +435,9 @@ func (t *tokenizer) next() bool {
 }
 
 func (t *tokenizer) Peek() Token {
+	if t.restored != nil {
+		return t.restored
+	}
 	if t.next() {
 		return t.token
 	}
@@ -401,6 +445,11 @@ func (t *tokenizer) Peek() Token {
 }
 
 func (t *tokenizer) Consume() Token {
+	if t.restored != nil {
+		token := t.restored
+		t.restored = nil
+		return token
+	}
 	if !t.next() {
 		return token{EOF, Loc{t.cursor, t.cursor}}
 	}
@@ -416,3 +465,17 @@ func (t *tokenizer) DiscardLineBreaks() {
 		token = t.Peek()
 	}
 }
+
+// Discard line breaks only if they are followed by a token of the given kind.
+// Report whether such a token was found.
+func (t *tokenizer) DiscardLineBreaksBefore(kind TokenKind) bool {
+	var lineBreak Token
+	for t.Peek().Kind() == EOL {
+		lineBreak = t.Consume()
+	}
+	if t.Peek().Kind() == kind {
+		return true
+	}
+	t.restored = lineBreak
+	return false
+}
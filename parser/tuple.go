@@ -29,9 +29,27 @@ func (t *TupleExpression) typeCheck(p *Parser) {
 	for i := range t.Elements {
 		types[i] = t.Elements[i].Type()
 	}
+	if values, ok := getTypeValues(types); ok {
+		// a tuple of types, like '(string, number)', is a tuple type
+		t.typing = Type{Tuple{values}}
+		return
+	}
 	t.typing = Tuple{types}
 }
 
+// Get the values of given types, if they are all types
+func getTypeValues(types []ExpressionType) ([]ExpressionType, bool) {
+	values := make([]ExpressionType, len(types))
+	for i := range types {
+		t, ok := types[i].(Type)
+		if !ok {
+			return nil, false
+		}
+		values[i] = t.Value
+	}
+	return values, true
+}
+
 func (t *TupleExpression) Loc() Loc {
 	if len(t.Elements) == 0 {
 		return Loc{}
@@ -95,3 +95,14 @@ func TestTupleType(t *testing.T) {
 		t.Fatalf("Expected string, got %#v", tuple.Elements[1])
 	}
 }
+
+func TestTupleOfTypes(t *testing.T) {
+	source := "entries := [](string, number){}"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	typing := statements[0].(*Assignment).Value.Type()
+	if typing.Text() != "[](string, number)" {
+		t.Fatalf("Expected [](string, number), got %v", typing.Text())
+	}
+}
@@ -48,7 +48,7 @@ func (u Unknown) build(scope *Scope, c ExpressionType) (ExpressionType, bool) {
 type Nil struct{}
 
 func (n Nil) Extends(t ExpressionType) bool {
-	_, ok := t.(Number)
+	_, ok := t.(Nil)
 	return ok
 }
 func (n Nil) Text() string { return "nil" }
@@ -98,6 +98,11 @@ type TypeAlias struct {
 
 func (ta TypeAlias) Extends(t ExpressionType) bool {
 	alias, ok := t.(TypeAlias)
+	// values of a generic trait type, like 'Iterator[number]', are values
+	// of any type implementing it
+	if trait, isTrait := getTrait(ta); isTrait && (!ok || alias.Name != ta.Name) {
+		return trait.Extends(t)
+	}
 	if !ok {
 		return false
 	}
@@ -105,7 +110,11 @@ func (ta TypeAlias) Extends(t ExpressionType) bool {
 		return false
 	}
 	for i, param := range ta.Params {
-		if param.Value != nil && !param.Value.Extends(alias.Params[i]) {
+		if i >= len(alias.Params) {
+			break
+		}
+		other := alias.Params[i].Value
+		if param.Value != nil && other != nil && !param.Value.Extends(other) {
 			return false
 		}
 	}
@@ -122,13 +131,11 @@ func (ta TypeAlias) Text() string {
 	if len(params) == 0 {
 		return ta.Name
 	}
-	var s string
+	// promises are written like '...number'
 	if ta.Name == "..." {
-		s = "async"
-	} else {
-		s = ta.Name
+		return "..." + params[0].Value.Text()
 	}
-	s += "["
+	s := ta.Name + "["
 	max := len(params) - 1
 	for _, param := range params[:max] {
 		s += param.Value.Text()
@@ -138,11 +145,38 @@ func (ta TypeAlias) Text() string {
 	return s + "]"
 }
 func (ta TypeAlias) build(scope *Scope, compared ExpressionType) (ExpressionType, bool) {
+	// Type params are aliases of a generic.
+	// They are either bound while inferring type args, or replaced by args.
+	if g, ok := ta.Ref.(Generic); ok && g.Name == ta.Name && scope != nil {
+		if v, ok := scope.Find(ta.Name); ok {
+			_, isType := v.Typing.(Type)
+			if isGenericType(v.Typing) {
+				return g.build(scope, compared)
+			} else if !isType {
+				return v.Typing, true
+			}
+		}
+	}
 	s := NewScope(ProgramScope)
 	s.outer = scope
-	for _, param := range ta.Params {
+	// type args are inferred from the compared alias' args, like 'number'
+	// for 'T' in '...?T' against '...?number'
+	c, _ := compared.(TypeAlias)
+	params := make([]Generic, len(ta.Params))
+	for i, param := range ta.Params {
+		if param.Value != nil {
+			var arg ExpressionType
+			if c.Name == ta.Name && i < len(c.Params) {
+				arg = c.Params[i].Value
+			}
+			if built, _ := param.Value.build(scope, arg); built != nil {
+				param.Value = built
+			}
+		}
+		params[i] = param
 		s.Add(param.Name, Loc{}, param)
 	}
+	ta.Params = params
 	var ref ExpressionType
 	if c, ok := compared.(TypeAlias); ok {
 		ref = c.Ref
@@ -160,7 +194,7 @@ func (ta *TypeAlias) registerMethod(name string, signature ExpressionType) {
 }
 func (ta TypeAlias) implements(trait Trait) bool {
 	for name, signature := range trait.Members {
-		method, ok := ta.Methods[name]
+		method, ok := ta.method(name)
 		if !ok || !signature.Extends(method) {
 			return false
 		}
@@ -168,18 +202,70 @@ func (ta TypeAlias) implements(trait Trait) bool {
 	return true
 }
 
+// Get a method declared on the type, with the type's args substituted, like
+// '() -> ?string' for 'next' on 'Cursor[string]'
+func (ta TypeAlias) method(name string) (ExpressionType, bool) {
+	method, ok := ta.Methods[name]
+	if !ok {
+		return nil, false
+	}
+	return substituteTypeArgs(method, ta.Params), true
+}
+
+// Replace the type params bound to a value in a type
+func substituteTypeArgs(t ExpressionType, params []Generic) ExpressionType {
+	scope := NewScope(ProgramScope)
+	for _, param := range params {
+		if param.Value != nil {
+			scope.Add(param.Name, Loc{}, param.Value)
+		}
+	}
+	built, _ := t.build(scope, nil)
+	return built
+}
+
+// Get the trait described by a type, if any.
+// Generic traits, like 'Iterator[number]', are aliases of a trait: their
+// type args are substituted in the trait's members.
+func getTrait(t ExpressionType) (Trait, bool) {
+	switch t := t.(type) {
+	case Trait:
+		return t, true
+	case TypeAlias:
+		trait, ok := t.Ref.(Trait)
+		if !ok {
+			return Trait{}, false
+		}
+		members := make(map[string]ExpressionType, len(trait.Members))
+		for name, member := range trait.Members {
+			members[name] = substituteTypeArgs(member, t.Params)
+		}
+		trait.Members = members
+		return trait, true
+	}
+	return Trait{}, false
+}
+
+// References are read-only, like '&T', unless taken with '&mut'.
+// Mutable references can be used as read-only ones, not the other way around.
 type Ref struct {
-	To ExpressionType
+	To      ExpressionType
+	Mutable bool
 }
 
 func (r Ref) Extends(t ExpressionType) bool {
 	ref, ok := t.(Ref)
-	if !ok {
+	if !ok || r.Mutable && !ref.Mutable {
 		return false
 	}
 	return r.To.Extends(ref.To)
 }
-func (r Ref) Text() string { return "&" + r.To.Text() }
+func (r Ref) Text() string {
+	if r.Mutable {
+		return "&mut " + r.To.Text()
+	}
+	return "&" + r.To.Text()
+}
 func (r Ref) build(scope *Scope, compared ExpressionType) (ExpressionType, bool) {
 	ref, ok := compared.(Ref)
 	if !ok {
@@ -241,6 +327,25 @@ func (m Map) build(scope *Scope, compared ExpressionType) (ExpressionType, bool)
 	return Map{key, value}, kk && vk
 }
 
+type Set struct {
+	Element ExpressionType
+}
+
+func (s Set) Extends(received ExpressionType) bool {
+	t, ok := received.(Set)
+	return ok && s.Element.Extends(t.Element)
+}
+func (s Set) Text() string { return "Set[" + s.Element.Text() + "]" }
+func (s Set) build(scope *Scope, compared ExpressionType) (ExpressionType, bool) {
+	var element ExpressionType
+	if c, ok := compared.(Set); ok {
+		element = c.Element
+	}
+	var ok bool
+	s.Element, ok = s.Element.build(scope, element)
+	return s, ok
+}
+
 type Tuple struct {
 	Elements []ExpressionType
 }
@@ -265,6 +370,9 @@ func (tuple Tuple) Extends(t ExpressionType) bool {
 	}
 }
 func (t Tuple) Text() string {
+	if len(t.Elements) == 0 {
+		return "()"
+	}
 	s := "("
 	max := len(t.Elements) - 1
 	for _, el := range t.Elements[:max] {
@@ -316,8 +424,22 @@ type Function struct {
 	Params     *Tuple
 	Returned   ExpressionType
 	Async      bool // true if the function can be called with 'async'
+	Host       bool // true if implemented in JS, exceptions then become errors
+	Intrinsic  Intrinsic
+	Mutates    bool // true for methods declared on 'mut' receivers
 }
 
+// Functions whose calls are checked and emitted by the compiler itself
+type Intrinsic uint8
+
+const (
+	NoIntrinsic     Intrinsic = iota
+	FormatIntrinsic           // takes a format string literal and its values
+	EncodeIntrinsic           // encodes a value as JSON
+	DecodeIntrinsic           // decodes JSON as a value of the given type
+	HelperIntrinsic           // implemented by a runtime helper of the same name
+)
+
 func (f Function) arity() int {
 	if f.Params == nil {
 		return 0
@@ -333,11 +455,8 @@ func (f Function) Extends(t ExpressionType) bool {
 	if f.arity() != function.arity() {
 		return false
 	}
-	if f.arity() == 0 {
-		return true
-	}
-	for i, param := range f.Params.Elements {
-		if !param.Extends(function.Params.Elements[i]) {
+	for i := 0; i < f.arity(); i++ {
+		if !f.Params.Elements[i].Extends(function.Params.Elements[i]) {
 			return false
 		}
 	}
@@ -355,15 +474,21 @@ func (f Function) build(scope *Scope, compared ExpressionType) (ExpressionType,
 		s.Add(param.Name, Loc{}, param)
 	}
 	c, k := compared.(Function)
-	f.Params = &Tuple{make([]ExpressionType, len(f.Params.Elements))}
-	for i, param := range f.Params.Elements {
-		var el ExpressionType
-		if len(c.Params.Elements) > i {
-			el = c.Params.Elements[i]
+	if f.Params != nil {
+		params := make([]ExpressionType, len(f.Params.Elements))
+		for i, param := range f.Params.Elements {
+			var el ExpressionType
+			if k && c.Params != nil && len(c.Params.Elements) > i {
+				el = c.Params.Elements[i]
+			}
+			p, pk := param.build(s, el)
+			ok = ok && pk
+			params[i] = p
 		}
-		p, k := param.build(s, el)
-		ok = ok && k
-		f.Params.Elements[i] = p
+		f.Params = &Tuple{params}
+	}
+	if f.Returned == nil {
+		return f, ok
 	}
 	var r ExpressionType
 	if k {
@@ -453,24 +578,33 @@ func (s Sum) Extends(t ExpressionType) bool {
 func (s Sum) Text() string {
 	str := "("
 	for name, member := range s.Members {
-		str += "| " + name + member.Params.Text() + " "
+		str += "| " + name
+		if member.Params != nil {
+			str += member.Params.Text()
+		}
+		str += " "
 	}
 	return str + ")"
 }
 func (s Sum) build(scope *Scope, compared ExpressionType) (ExpressionType, bool) {
 	ok := true
+	members := make(map[string]Function, len(s.Members))
 	for name, member := range s.Members {
-		var k bool
+		// Constructors return the sum itself, which would recurse endlessly
+		returned := member.Returned
+		member.Returned = nil
 		// FIXME: is compared a sum type? should it work like this?
 		m, k := member.build(scope, compared)
-		s.Members[name] = m.(Function)
+		built := m.(Function)
+		built.Returned = returned
+		members[name] = built
 		ok = ok && k
 	}
-	return s, ok
+	return Sum{members}, ok
 }
 func (s Sum) getMember(name string) ExpressionType {
 	member, ok := s.Members[name]
-	if !ok {
+	if !ok || member.Params == nil {
 		return Unknown{}
 	}
 	if len(member.Params.Elements) == 1 {
@@ -490,22 +624,17 @@ type Trait struct {
 }
 
 func (t Trait) Extends(et ExpressionType) bool {
-	var receivedMethods map[string]ExpressionType
-	switch et := et.(type) {
-	case Trait:
-		receivedMethods = et.Members
-	case TypeAlias:
-		receivedMethods = et.Methods
-	default:
-		return false
-	}
-	for name, signature := range t.Members {
-		method, ok := receivedMethods[name]
-		if !ok || !signature.Extends(method) {
-			return false
+	if trait, ok := getTrait(et); ok {
+		for name, signature := range t.Members {
+			method, ok := trait.Members[name]
+			if !ok || !signature.Extends(method) {
+				return false
+			}
 		}
+		return true
 	}
-	return true
+	alias, ok := et.(TypeAlias)
+	return ok && alias.implements(t)
 }
 func (t Trait) Text() string {
 	s := "("
@@ -534,6 +663,10 @@ func (g Generic) Extends(t ExpressionType) bool {
 func (g Generic) Text() string { return g.Name }
 func (g Generic) build(scope *Scope, compared ExpressionType) (ExpressionType, bool) {
 	if g.Value != nil {
+		// bound values might refer to outer type params
+		if scope != nil {
+			return g.Value.build(scope, compared)
+		}
 		return g.Value, true
 	}
 	if scope == nil {
@@ -5,6 +5,7 @@ import "fmt"
 type UnaryExpression struct {
 	Operator Token
 	Operand  Expression
+	Mutable  bool // for references taken with '&mut'
 }
 
 func (u *UnaryExpression) getChildren() []Node {
@@ -23,10 +24,7 @@ func (u *UnaryExpression) typeCheck(p *Parser) {
 	case AsyncKeyword:
 		typeCheckAsyncExpression(p, u)
 	case AwaitKeyword:
-		alias, ok := u.Operand.Type().(TypeAlias)
-		if !ok || alias.Name != "..." {
-			p.error(u.Operand, PromiseExpected, u.Operand.Type())
-		}
+		typeCheckAwait(p, u)
 	case Bang:
 		switch u.Operand.Type().(type) {
 		case Type, Boolean:
@@ -34,28 +32,60 @@ func (u *UnaryExpression) typeCheck(p *Parser) {
 			p.error(u.Operand, TypeOrBoolExpected, u.Operand.Type())
 		}
 	case BinaryAnd:
+		if instance, ok := u.Operand.(*InstanceExpression); ok {
+			// new values are not held by any variable
+			instance.typeCheck(p)
+			return
+		}
 		identifier := getReferencedIdentifier(u.Operand)
 		if identifier == nil {
 			return
 		}
-		v, ok := p.scope.Find(identifier.Text())
+		// references are taken on the whole variable, not on its refinement
+		v, ok := p.scope.widen(identifier.Text())
 		if ok {
-			v.writeAt(u)
+			// reference types, like '&Point', do not reference any variable
+			if _, isType := v.Typing.(Type); !isType {
+				v.writeAt(u)
+			}
+			u.Operand.typeCheck(p)
+		}
+		_, isType := u.Operand.Type().(Type)
+		if c, ok := u.Operand.(*ComputedAccessExpression); ok && !isType && !isListElement(c) {
+			p.error(u.Operand, NotReferenceable)
+			return
+		}
+		if !isType && u.Mutable {
+			reportReadOnlyWrite(p, u, u.Operand)
 		}
 	case Mul:
 		if _, ok := u.Operand.Type().(Ref); !ok {
 			p.error(u.Operand, RefExpected, u.Operand.Type())
 			return
 		}
+	case Ellipsis:
+		// promises resolving without value are written '...()'
+		if _, ok := u.Operand.Type().(Type); !ok && !isNilReturn(u.Operand) {
+			p.error(u.Operand, TypeExpected)
+		}
 	case QuestionMark:
 		if _, ok := u.Operand.Type().(Type); !ok {
 			p.error(u.Operand, TypeExpected)
 		}
+	case Sub:
+		if _, ok := u.Operand.Type().(Number); !ok {
+			p.error(u.Operand, NumberExpected, u.Operand.Type())
+		}
 	case TryKeyword:
-		alias, ok := u.Operand.Type().(TypeAlias)
-		if !ok || alias.Name != "!" {
-			p.error(u.Operand, ResultExpected, u.Operand.Type())
+		t := u.Operand.Type()
+		if !isResult(t) && !isOption(t) {
+			p.error(u.Operand, FailableExpected, t)
 		}
+	case MutKeyword:
+		// method receivers like '(p mut Point)' are checked on their own
+		p.error(u, IllegalMut)
+	case YieldKeyword:
+		// checked against the generator's type, see generator.go
 	default:
 		panic(fmt.Sprintf("Operator '%v' not implemented!", u.Operator.Kind()))
 	}
@@ -91,12 +121,7 @@ func (u *UnaryExpression) Type() ExpressionType {
 	case AsyncKeyword:
 		return makePromise(u.Operand.Type())
 	case AwaitKeyword:
-		alias, ok := u.Operand.Type().(TypeAlias)
-		if !ok || alias.Name != "..." {
-			return Unknown{}
-		}
-		t, _ := alias.Params[0].Value.build(nil, nil)
-		return t
+		return getAwaitType(u)
 	case Bang:
 		t := u.Operand.Type()
 		if ty, ok := t.(Type); ok {
@@ -108,9 +133,9 @@ func (u *UnaryExpression) Type() ExpressionType {
 	case BinaryAnd:
 		switch t := u.Operand.Type().(type) {
 		case Type:
-			return Type{Ref{t.Value}}
+			return Type{Ref{t.Value, u.Mutable}}
 		default:
-			return Ref{t}
+			return Ref{t, u.Mutable}
 		}
 	case Mul:
 		ref, ok := u.Operand.Type().(Ref)
@@ -124,12 +149,30 @@ func (u *UnaryExpression) Type() ExpressionType {
 			t = ty.Value
 		}
 		return Type{makeOptionType(t)}
+	case Ellipsis:
+		if isNilReturn(u.Operand) {
+			return Type{makePromise(Nil{})}
+		}
+		t, ok := u.Operand.Type().(Type)
+		if !ok {
+			return Unknown{}
+		}
+		return Type{makePromise(t.Value)}
+	case MutKeyword:
+		return u.Operand.Type()
+	case Sub:
+		return Number{}
 	case TryKeyword:
-		alias, ok := u.Operand.Type().(TypeAlias)
-		if !ok || alias.Name != "!" {
+		t := u.Operand.Type()
+		if payload := getOptionPayload(t); payload != nil {
+			return payload
+		}
+		if !isResult(t) {
 			return Unknown{}
 		}
-		return alias.Ref.(Sum).getMember("Ok")
+		return getHappyType(t)
+	case YieldKeyword:
+		return Nil{}
 	default:
 		return Unknown{}
 	}
@@ -175,8 +218,15 @@ func (l *ListTypeExpression) Type() ExpressionType {
 
 func (p *Parser) parseUnaryExpression() Expression {
 	switch p.Peek().Kind() {
-	case AsyncKeyword, AwaitKeyword, Bang, BinaryAnd, Mul, QuestionMark, TryKeyword:
+	case AsyncKeyword, AwaitKeyword, Bang, BinaryAnd, Ellipsis, Mul, MutKeyword, QuestionMark, Sub, TryKeyword:
 		token := p.Consume()
+		if token.Kind() == AsyncKeyword && p.Peek().Kind() == LeftBrace {
+			return &TaskGroup{Keyword: token, Body: p.parseBlock()}
+		}
+		mutable := token.Kind() == BinaryAnd && p.Peek().Kind() == MutKeyword
+		if mutable {
+			p.Consume()
+		}
 		expr := parseInnerUnary(p)
 		if token.Kind() == AsyncKeyword {
 			validateAsyncOperand(p, expr)
@@ -184,9 +234,13 @@ func (p *Parser) parseUnaryExpression() Expression {
 		if token.Kind() == BinaryAnd && !isReferencable(expr) {
 			p.error(expr, NotReferenceable)
 		}
-		return &UnaryExpression{token, expr}
+		return &UnaryExpression{token, expr, mutable}
 	case LeftBracket:
 		return parseListTypeExpression(p)
+	case ExclusiveRange:
+		// like '..number' in '(r ..number) => ...'
+		operator := p.Consume()
+		return &RangeExpression{Right: parseInnerUnary(p), Operator: operator}
 	default:
 		return p.parseAccessExpression()
 	}
@@ -211,8 +265,13 @@ func parseInnerUnary(p *Parser) Expression {
 
 func parseListTypeExpression(p *Parser) Expression {
 	brackets := p.parseBracketedExpression()
+	// functions need type params, so '[](A, B)' is a list of tuples
+	isFunction := brackets != nil && brackets.Expr != nil
 	switch p.Peek().Kind() {
 	case LeftParenthesis:
+		if !isFunction {
+			return &ListTypeExpression{brackets, parseInnerUnary(p)}
+		}
 		return p.parseFunctionExpression(brackets)
 	case LeftBrace:
 		return parseAnonymousList(p, brackets)
@@ -233,3 +292,13 @@ func parseAnonymousList(p *Parser, brackets *BracketedExpression) *InstanceExpre
 		Args:   args,
 	}
 }
+
+// Check if an expression is an element of a list, like 'list[0]'.
+// Elements can be referenced, unlike slices, map entries or characters.
+func isListElement(c *ComputedAccessExpression) bool {
+	if _, ok := c.Property.Expr.(*RangeExpression); ok {
+		return false
+	}
+	_, ok := deref(c.Expr.Type()).(List)
+	return ok
+}
@@ -267,6 +267,28 @@ func TestCheckReference(t *testing.T) {
 	}
 }
 
+func TestCheckElementReference(t *testing.T) {
+	source := "list := []number{1, 2}\n"
+	source += "r := &mut list[1]\n"
+	source += "*r = 3"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+	r := statements[1].(*Assignment).Value
+	if text := r.Type().Text(); text != "&mut number" {
+		t.Fatalf("Expected '&mut number', got %v", text)
+	}
+
+	source = "list := []number{1, 2}\n"
+	source += "&list[0..1]"
+	_, errors = Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, NotReferenceable)
+
+	source = "s := \"abc\"\n"
+	source += "&s[0]"
+	_, errors = Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, NotReferenceable)
+}
+
 func TestParseDereference(t *testing.T) {
 	parser := MakeParser(strings.NewReader("*ref"))
 	node := parser.parseExpression()
@@ -283,7 +305,7 @@ func TestParseDereference(t *testing.T) {
 func TestCheckDereference(t *testing.T) {
 	parser := MakeParser(nil)
 	// *ref
-	parser.scope.Add("ref", Loc{}, Ref{Number{}})
+	parser.scope.Add("ref", Loc{}, Ref{To: Number{}})
 	expr := &UnaryExpression{
 		Operator: token{kind: Mul},
 		Operand:  &Identifier{Token: literal{kind: Name, value: "ref"}},
@@ -454,3 +476,39 @@ func TestCheckTryExpressionBadType(t *testing.T) {
 		t.Fatalf("Expected 1 error, got %#v", parser.errors)
 	}
 }
+
+func TestTryOption(t *testing.T) {
+	source := "f :: (option ?number) => ?number {\n"
+	source += "    n := try option\n"
+	source += "    n + 1\n"
+	source += "}\n"
+	source += "io.log(f)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+}
+
+func TestTryOptionInNonOptionFunction(t *testing.T) {
+	source := "f :: (option ?number) => number {\n"
+	source += "    n := try option\n"
+	source += "    n + 1\n"
+	source += "}\n"
+	source += "io.log(f)"
+	_, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors, IllegalPropagation)
+}
+
+func TestCheckNegation(t *testing.T) {
+	source := "a := -2\n"
+	source += "-a"
+	statements, errors := Parse(strings.NewReader(source))
+	testErrorKinds(t, errors)
+
+	if _, ok := statements[1].(*UnaryExpression).Type().(Number); !ok {
+		t.Fatal("Expected number")
+	}
+}
+
+func TestCheckNegationNotNumber(t *testing.T) {
+	_, errors := Parse(strings.NewReader("-true"))
+	testErrorKinds(t, errors, NumberExpected)
+}
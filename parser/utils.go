@@ -38,12 +38,45 @@ func addTypeParamToScope(scope *Scope, param *Param) {
 	scope.Add(name, param.Loc(), t)
 }
 
+// Check if the given type is an option
+func isOption(t ExpressionType) bool {
+	alias, ok := t.(TypeAlias)
+	return ok && alias.Name == "?"
+}
+
+// Check if the given type is a result
+func isResult(t ExpressionType) bool {
+	alias, ok := t.(TypeAlias)
+	return ok && alias.Name == "!"
+}
+
+// Wrap the given type in an option, unless it already is one
+func makeOptional(t ExpressionType) ExpressionType {
+	if isOption(t) {
+		return t
+	}
+	return makeOptionType(t)
+}
+
+// If the given is an option, return its "Some" type.
+// Else return nil.
+func getOptionPayload(t ExpressionType) ExpressionType {
+	if !isOption(t) {
+		return nil
+	}
+	return t.(TypeAlias).Ref.(Sum).getMember("Some")
+}
+
 // If the given is a result, return its "Ok" type.
+// If the given is an option, return its "Some" type.
 // Else return the given type.
 func getHappyType(t ExpressionType) ExpressionType {
 	if alias, ok := t.(TypeAlias); ok && alias.Name == "!" {
 		return alias.Ref.(Sum).getMember("Ok")
 	}
+	if payload := getOptionPayload(t); payload != nil {
+		return payload
+	}
 	return t
 }
 
@@ -57,7 +90,8 @@ func getErrorType(t ExpressionType) ExpressionType {
 }
 
 // Check if an expression can be taken as an argument for the ref operator.
-// Such an expression can only be identifiers or nested accesses.
+// Such an expression can only be identifiers or nested accesses, like
+// 'x.list[0]'.
 func isReferencable(expr Expression) bool {
 	for {
 		switch e := expr.(type) {
@@ -66,10 +100,14 @@ func isReferencable(expr Expression) bool {
 		case *Literal:
 			_, ok := e.Type().(Type)
 			return ok
+		case *ListTypeExpression:
+			return true
 		case *InstanceExpression:
 			expr = e.Typing
 		case *PropertyAccessExpression:
 			expr = e.Expr
+		case *ComputedAccessExpression:
+			expr = e.Expr
 		default:
 			return false
 		}
@@ -85,6 +123,8 @@ func getReferencedIdentifier(expr Expression) *Identifier {
 			expr = e.Typing
 		case *PropertyAccessExpression:
 			expr = e.Expr
+		case *ComputedAccessExpression:
+			expr = e.Expr
 		default:
 			return nil
 		}
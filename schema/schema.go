@@ -0,0 +1,190 @@
+// Package schema exports the types defined in a program as JSON Schema.
+// Schemas describe values as encoded by 'toJson'.
+package schema
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/bmelicque/test-parser/parser"
+)
+
+const draft = "https://json-schema.org/draft/2020-12/schema"
+
+type schema = map[string]any
+
+type exporter struct {
+	defined map[string]bool // types with a schema in $defs
+}
+
+// Export the top-level type definitions of a checked program.
+// Generic types are only exported where used with concrete type args, and
+// types whose values cannot be encoded as JSON are not exported.
+func Export(nodes []parser.Node) string {
+	definitions := map[string]parser.TypeAlias{}
+	for _, node := range nodes {
+		if alias, ok := getDefinedType(node); ok && parser.IsJsonType(alias) {
+			definitions[alias.Name] = alias
+		}
+	}
+
+	e := exporter{defined: map[string]bool{}}
+	for name := range definitions {
+		e.defined[name] = true
+	}
+	defs := schema{}
+	for name, alias := range definitions {
+		defs[name] = e.exportRef(alias)
+	}
+	document := schema{"$schema": draft, "$defs": defs}
+	text, _ := json.MarshalIndent(document, "", "  ")
+	return string(text) + "\n"
+}
+
+// Get the type defined by a statement like 'Type :: ...'
+func getDefinedType(node parser.Node) (parser.TypeAlias, bool) {
+	a, ok := node.(*parser.Assignment)
+	if !ok || a.Operator.Kind() != parser.Define {
+		return parser.TypeAlias{}, false
+	}
+	identifier, ok := a.Pattern.(*parser.Identifier)
+	if !ok || !identifier.IsType() {
+		return parser.TypeAlias{}, false
+	}
+	t, _ := identifier.Type().(parser.Type)
+	alias, ok := t.Value.(parser.TypeAlias)
+	return alias, ok
+}
+
+func (e exporter) export(t parser.ExpressionType) schema {
+	switch t := t.(type) {
+	case parser.Boolean:
+		return schema{"type": "boolean"}
+	case parser.Number:
+		return schema{"type": "number"}
+	case parser.String:
+		return schema{"type": "string"}
+	case parser.Generic:
+		return e.export(t.Value)
+	case parser.Tuple:
+		return e.exportTuple(t)
+	case parser.List:
+		return schema{"type": "array", "items": e.export(t.Element)}
+	case parser.Set:
+		return schema{"type": "array", "items": e.export(t.Element), "uniqueItems": true}
+	case parser.Map:
+		return e.exportMap(t)
+	case parser.TypeAlias:
+		return e.exportAlias(t)
+	default:
+		return schema{}
+	}
+}
+
+func (e exporter) exportTuple(t parser.Tuple) schema {
+	items := make([]schema, len(t.Elements))
+	for i, element := range t.Elements {
+		items[i] = e.export(element)
+	}
+	return schema{
+		"type":        "array",
+		"prefixItems": items,
+		"items":       false,
+		"minItems":    len(items),
+	}
+}
+
+// Maps with string keys are encoded as objects, other maps as lists of
+// entries
+func (e exporter) exportMap(t parser.Map) schema {
+	if _, ok := t.Key.(parser.String); ok {
+		return schema{"type": "object", "additionalProperties": e.export(t.Value)}
+	}
+	return schema{
+		"type":  "array",
+		"items": e.exportTuple(parser.Tuple{Elements: []parser.ExpressionType{t.Key, t.Value}}),
+	}
+}
+
+// Non-generic defined types are referred to, others are written in place
+func (e exporter) exportAlias(t parser.TypeAlias) schema {
+	if isOption(t) {
+		return schema{"anyOf": []schema{e.export(t.Params[0].Value), {"type": "null"}}}
+	}
+	if e.defined[t.Name] && len(t.Params) == 0 {
+		return schema{"$ref": "#/$defs/" + t.Name}
+	}
+	return e.exportRef(t)
+}
+
+// Get the schema of the aliased type
+func (e exporter) exportRef(t parser.TypeAlias) schema {
+	switch ref := t.Ref.(type) {
+	case parser.Object:
+		return e.exportObject(ref)
+	case parser.Sum:
+		return e.exportSum(ref)
+	default:
+		return e.export(ref)
+	}
+}
+
+// Options and fields with a default value may be missing
+func (e exporter) exportObject(o parser.Object) schema {
+	properties := schema{}
+	required := []string{}
+	for _, member := range o.Members {
+		properties[member.Name] = e.export(member.Type)
+		if alias, ok := member.Type.(parser.TypeAlias); !ok || !isOption(alias) {
+			required = append(required, member.Name)
+		}
+	}
+	for _, member := range o.Defaults {
+		properties[member.Name] = e.export(member.Type)
+	}
+	s := schema{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// Constructors are tagged objects, holding a tuple if they have several
+// params
+func (e exporter) exportSum(sum parser.Sum) schema {
+	names := make([]string, 0, len(sum.Members))
+	for name := range sum.Members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	constructors := make([]schema, len(names))
+	for i, name := range names {
+		properties := schema{"tag": schema{"const": name}}
+		required := []string{"tag"}
+		params := sum.Members[name].Params
+		switch {
+		case params == nil || len(params.Elements) == 0:
+		case len(params.Elements) == 1:
+			properties["value"] = e.export(params.Elements[0])
+			required = append(required, "value")
+		default:
+			properties["value"] = e.exportTuple(*params)
+			required = append(required, "value")
+		}
+		constructors[i] = schema{
+			"type":                 "object",
+			"properties":           properties,
+			"required":             required,
+			"additionalProperties": false,
+		}
+	}
+	return schema{"oneOf": constructors}
+}
+
+func isOption(t parser.TypeAlias) bool {
+	return t.Name == "?"
+}
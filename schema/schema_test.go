@@ -0,0 +1,122 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bmelicque/test-parser/parser"
+)
+
+func testSchema(t *testing.T, source string) map[string]any {
+	ast, errors := parser.Parse(strings.NewReader(source))
+	if len(errors) > 0 {
+		t.Fatalf("Expected no errors, got %#v", errors)
+	}
+	var document map[string]any
+	if err := json.Unmarshal([]byte(Export(ast)), &document); err != nil {
+		t.Fatal(err)
+	}
+	return document["$defs"].(map[string]any)
+}
+
+func testDefinition(t *testing.T, defs map[string]any, name string, expected string) {
+	var s any
+	if err := json.Unmarshal([]byte(expected), &s); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(defs[name], s) {
+		received, _ := json.Marshal(defs[name])
+		t.Fatalf("expected %v to be:\n%v\n\ngot:\n%v", name, expected, string(received))
+	}
+}
+
+func TestObjectSchema(t *testing.T) {
+	defs := testSchema(t, `User :: {
+    name string
+    age ?number
+    tags []string
+    admin: false
+}`)
+	testDefinition(t, defs, "User", `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"age": {"anyOf": [{"type": "number"}, {"type": "null"}]},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"admin": {"type": "boolean"}
+		},
+		"required": ["name", "tags"],
+		"additionalProperties": false
+	}`)
+}
+
+func TestSumSchema(t *testing.T) {
+	defs := testSchema(t, `Shape :: | Circle{number} | Rect{number, number} | Empty`)
+	testDefinition(t, defs, "Shape", `{"oneOf": [
+		{
+			"type": "object",
+			"properties": {"tag": {"const": "Circle"}, "value": {"type": "number"}},
+			"required": ["tag", "value"],
+			"additionalProperties": false
+		},
+		{
+			"type": "object",
+			"properties": {"tag": {"const": "Empty"}},
+			"required": ["tag"],
+			"additionalProperties": false
+		},
+		{
+			"type": "object",
+			"properties": {"tag": {"const": "Rect"}, "value": {
+				"type": "array",
+				"prefixItems": [{"type": "number"}, {"type": "number"}],
+				"items": false,
+				"minItems": 2
+			}},
+			"required": ["tag", "value"],
+			"additionalProperties": false
+		}
+	]}`)
+}
+
+func TestReferencesAndGenerics(t *testing.T) {
+	defs := testSchema(t, `Id :: number
+Box[Type] :: {
+    value Type
+}
+Entry :: {
+    id Id
+    box Box[string]
+    scores Map[string, number]
+}`)
+	if _, ok := defs["Box"]; ok {
+		t.Fatal("expected generic type not to be exported")
+	}
+	testDefinition(t, defs, "Id", `{"type": "number"}`)
+	testDefinition(t, defs, "Entry", `{
+		"type": "object",
+		"properties": {
+			"id": {"$ref": "#/$defs/Id"},
+			"box": {
+				"type": "object",
+				"properties": {"value": {"type": "string"}},
+				"required": ["value"],
+				"additionalProperties": false
+			},
+			"scores": {"type": "object", "additionalProperties": {"type": "number"}}
+		},
+		"required": ["id", "box", "scores"],
+		"additionalProperties": false
+	}`)
+}
+
+func TestNonJsonTypes(t *testing.T) {
+	defs := testSchema(t, `Callback :: {
+    call (number) -> number
+}`)
+	if _, ok := defs["Callback"]; ok {
+		t.Fatal("expected type with a function not to be exported")
+	}
+}